@@ -0,0 +1,449 @@
+package main
+
+// The compress-report command packs a column of a CSV or TSV file with every
+// registered codec and PackOp, and prints the packed size and the throughput
+// of every combination, to help choosing the codecs of a series before
+// ingesting the data.
+//
+// The Adaptive codec picks a codec and PackOp for every frame, so it is
+// reported once, labelled with the codecs and PackOps it picked. Codecs that
+// do not support the type of the values, and the lossy Quantized codec unless
+// an error bound is given, are listed as skipped after the table.
+//
+// Usage:
+//
+//	ats compress-report [flags] <file>
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Default number of values per frame of the report
+const defaultReportFrameSize = 1024
+
+// Configuration of the compress-report command
+type reportConfig struct {
+	path      string
+	columns   []string
+	delimiter rune
+	header    bool
+	frameSize int
+	elemType  string
+	maxError  float64
+	scale     uint8
+}
+
+// Measures of a codec and PackOp over all frames of a column
+type reportRow struct {
+	bitsPerValue float64
+	ratio        float64
+	encodeMBps   float64
+	decodeMBps   float64
+	exact        bool
+	err          error
+
+	// Number of frames packed with every codec and PackOp, as recorded in
+	// their headers
+	choices map[string]int
+}
+
+// Runs the compress-report command with the specified arguments and returns
+// the exit code of the process.
+func runCompressReport(args []string, stdout io.Writer, stderr io.Writer) int {
+	cfg, err := parseReportArgs(args, stderr)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(stderr, "compress-report:", err)
+		}
+		return 2
+	}
+
+	file, err := os.Open(cfg.path)
+	if err != nil {
+		fmt.Fprintln(stderr, "compress-report:", err)
+		return 1
+	}
+	defer file.Close()
+
+	if err := compressReport(file, stdout, cfg); err != nil {
+		fmt.Fprintln(stderr, "compress-report:", err)
+		return 1
+	}
+	return 0
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func parseReportArgs(args []string, stderr io.Writer) (reportConfig, error) {
+	flags := flag.NewFlagSet("compress-report", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: ats compress-report [flags] <file>")
+		flags.PrintDefaults()
+	}
+
+	columns := flags.String("columns", "0",
+		"comma separated columns to analyze, by name (with -header) or zero based index")
+	delimiter := flags.String("delimiter", "",
+		"field delimiter, \"tab\" for tabs (default: tab for .tsv files, comma otherwise)")
+	header := flags.Bool("header", false, "first line of the file holds the column names")
+	frameSize := flags.Int("frame-size", defaultReportFrameSize, "number of values per frame")
+	elemType := flags.String("type", "float64",
+		"type of the values: float64, int64, uint64, float32, int32, uint32 or decimal")
+	scale := flags.Int("scale", 2, "number of digits after the decimal point of decimal values")
+	maxError := flags.Float64("max-error", 0,
+		"absolute error bound of the lossy Quantized codec (float64 values), which is only reported if set")
+
+	if err := flags.Parse(args); err != nil {
+		return reportConfig{}, err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return reportConfig{}, errors.New("expected a single input file")
+	}
+	if *frameSize <= 0 {
+		return reportConfig{}, errors.New("frame size must be positive")
+	}
+	if *scale < 0 || *scale > packer.MaxDecimalScale {
+		return reportConfig{}, fmt.Errorf("scale must be between 0 and %d", packer.MaxDecimalScale)
+	}
+
+	cfg := reportConfig{
+		path:      flags.Arg(0),
+		columns:   strings.Split(*columns, ","),
+		delimiter: ',',
+		header:    *header,
+		frameSize: *frameSize,
+		elemType:  *elemType,
+		maxError:  *maxError,
+		scale:     uint8(*scale),
+	}
+
+	switch {
+	case *delimiter == "tab" || *delimiter == `\t`:
+		cfg.delimiter = '\t'
+	case *delimiter != "":
+		runes := []rune(*delimiter)
+		if len(runes) != 1 {
+			return reportConfig{}, errors.New("delimiter must be a single character")
+		}
+		cfg.delimiter = runes[0]
+	case strings.EqualFold(filepath.Ext(cfg.path), ".tsv"):
+		cfg.delimiter = '\t'
+	}
+
+	return cfg, nil
+}
+
+// Reads the columns of the input and writes the report of every column
+func compressReport(in io.Reader, out io.Writer, cfg reportConfig) error {
+	names, columns, err := readReportColumns(in, cfg)
+	if err != nil {
+		return err
+	}
+
+	for ndx := range columns {
+		if ndx > 0 {
+			fmt.Fprintln(out)
+		}
+
+		var err error
+		switch cfg.elemType {
+		case "float64":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+		case "int64":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) })
+		case "uint64":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (uint64, error) { return strconv.ParseUint(s, 10, 64) })
+		case "float32":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (float32, error) {
+					v, err := strconv.ParseFloat(s, 32)
+					return float32(v), err
+				})
+		case "int32":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (int32, error) {
+					v, err := strconv.ParseInt(s, 10, 32)
+					return int32(v), err
+				})
+		case "uint32":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (uint32, error) {
+					v, err := strconv.ParseUint(s, 10, 32)
+					return uint32(v), err
+				})
+		case "decimal":
+			err = reportColumn(out, names[ndx], columns[ndx], cfg,
+				func(s string) (packer.Decimal, error) { return packer.ParseDecimal(s, cfg.scale) })
+		default:
+			err = fmt.Errorf("unsupported value type %q", cfg.elemType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reads the fields of the requested columns. Returns the names of the columns
+// and their fields.
+func readReportColumns(in io.Reader, cfg reportConfig) ([]string, [][]string, error) {
+	reader := csv.NewReader(in)
+	reader.Comma = cfg.delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	var header []string
+	if cfg.header {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading header: %w", err)
+		}
+		header = append(header, record...)
+	}
+
+	names := make([]string, len(cfg.columns))
+	indices := make([]int, len(cfg.columns))
+	for ndx, spec := range cfg.columns {
+		spec = strings.TrimSpace(spec)
+		names[ndx] = spec
+		indices[ndx] = -1
+		for col, name := range header {
+			if strings.TrimSpace(name) == spec {
+				indices[ndx] = col
+			}
+		}
+		if indices[ndx] < 0 {
+			col, err := strconv.Atoi(spec)
+			if err != nil || col < 0 {
+				return nil, nil, fmt.Errorf("unknown column %q", spec)
+			}
+			indices[ndx] = col
+			if col < len(header) {
+				names[ndx] = strings.TrimSpace(header[col])
+			}
+		}
+	}
+
+	columns := make([][]string, len(cfg.columns))
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for ndx, col := range indices {
+			if col >= len(record) {
+				return nil, nil, fmt.Errorf("line %d has no column %d", line, col)
+			}
+			columns[ndx] = append(columns[ndx], strings.TrimSpace(record[col]))
+		}
+	}
+
+	return names, columns, nil
+}
+
+// Parses the fields of the column and writes the report of every codec and
+// PackOp supporting the type of the values, followed by the skipped codecs.
+func reportColumn[T packer.Number](
+	out io.Writer, name string, fields []string, cfg reportConfig,
+	parse func(string) (T, error)) error {
+
+	frameSize := cfg.frameSize
+	values := make([]T, len(fields))
+	for ndx, field := range fields {
+		v, err := parse(field)
+		if err != nil {
+			return fmt.Errorf("column %s, value %d: %w", name, ndx+1, err)
+		}
+		values[ndx] = v
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("column %s holds no values", name)
+	}
+
+	frames := make([][]T, 0, (len(values)+frameSize-1)/frameSize)
+	for start := 0; start < len(values); start += frameSize {
+		end := start + frameSize
+		if end > len(values) {
+			end = len(values)
+		}
+		frames = append(frames, values[start:end])
+	}
+
+	fmt.Fprintf(out, "Column %s: %d values, %d frames of %d values\n",
+		name, len(values), len(frames), frameSize)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "codec\top\tbits/value\tratio\tencode MB/s\tdecode MB/s\texact\t")
+	skipped := make([]string, 0)
+	for _, id := range packer.Codecs() {
+		if !packer.Supports[T](id) {
+			skipped = append(skipped,
+				fmt.Sprintf("%s (no %s support)", id, packer.ElemTypeOf[T]()))
+			continue
+		}
+		p, err := reportPacker[T](id, cfg)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s (%v)", id, err))
+			continue
+		}
+
+		// The op is picked by the codec along with the codec of every frame
+		if id == packer.CodecAdaptive {
+			row := measureCodec(p, packer.NOP, frames)
+			writeReportRow(tw, adaptiveLabel(row.choices), "auto", row)
+			continue
+		}
+
+		for _, op := range []packer.PackOp{packer.NOP, packer.Offset, packer.Delta} {
+			writeReportRow(tw, id.String(), op.String(), measureCodec(p, op, frames))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(out, "Skipped: %s\n", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// Create the packer of the codec for the report. Codecs needing parameters
+// are created with the parameters of the configuration. Returns the packer
+// along with nil error, otherwise returns (nil, error) if the codec cannot be
+// reported.
+func reportPacker[T packer.Number](id packer.CodecID, cfg reportConfig) (packer.Packer[T], error) {
+	switch id {
+	case packer.CodecQuantized:
+		if cfg.maxError == 0 {
+			return nil, errors.New("lossy, requires -max-error")
+		}
+		return packer.NewQuantized[T](packer.AbsoluteError, cfg.maxError), nil
+	case packer.CodecDecimal:
+		return packer.NewDecimalPacker[T](cfg.scale), nil
+	}
+	return packer.New[T](id)
+}
+
+// Writes the row of the codec and op to the table of the report
+func writeReportRow(tw io.Writer, codec string, op string, row reportRow) {
+	if row.err != nil {
+		fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t-\t-\t%v\n", codec, op, row.err)
+		return
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%.1f\t%.1f\t%v\t\n",
+		codec, op, row.bitsPerValue, row.ratio, row.encodeMBps, row.decodeMBps, row.exact)
+}
+
+// Return the label of the Adaptive codec listing the codecs and PackOps it
+// picked, the most frequent first, along with their number of frames when it
+// picked several.
+func adaptiveLabel(choices map[string]int) string {
+	picked := make([]string, 0, len(choices))
+	for choice := range choices {
+		picked = append(picked, choice)
+	}
+	sort.Slice(picked, func(i, j int) bool {
+		if choices[picked[i]] != choices[picked[j]] {
+			return choices[picked[i]] > choices[picked[j]]
+		}
+		return picked[i] < picked[j]
+	})
+	if len(picked) > 1 {
+		for ndx, choice := range picked {
+			picked[ndx] = fmt.Sprintf("%s x%d", choice, choices[choice])
+		}
+	}
+
+	return fmt.Sprintf("%s: %s", packer.CodecAdaptive, strings.Join(picked, ", "))
+}
+
+// Packs and unpacks every frame with the packer and PackOp
+func measureCodec[T packer.Number](p packer.Packer[T], op packer.PackOp, frames [][]T) reportRow {
+	row := reportRow{exact: true, choices: make(map[string]int)}
+
+	var numValues, packedBytes int
+	var encodeTime, decodeTime time.Duration
+	buffer := &bytes.Buffer{}
+	for _, values := range frames {
+		buffer.Reset()
+		begin := time.Now()
+		if err := p.Pack(values, buffer, op, reportOpParam(values, op)); err != nil {
+			row.err = err
+			return row
+		}
+		encodeTime += time.Since(begin)
+		if hdr, err := packer.ReadHeader(buffer); err == nil {
+			row.choices[fmt.Sprintf("%s/%s", hdr.Codec, hdr.Op)]++
+		}
+
+		dst := make([]T, len(values))
+		begin = time.Now()
+		if _, err := p.Unpack(buffer, dst, packer.NOP, 0); err != nil {
+			row.err = err
+			return row
+		}
+		decodeTime += time.Since(begin)
+
+		// NaNs unpacked as NaNs are exact
+		for ndx := range values {
+			if dst[ndx] != values[ndx] && (dst[ndx] == dst[ndx] || values[ndx] == values[ndx]) {
+				row.exact = false
+			}
+		}
+		numValues += len(values)
+		packedBytes += buffer.Len()
+	}
+
+	rawBytes := float64(packer.ElemTypeOf[T]().Size()) * float64(numValues)
+	row.bitsPerValue = float64(8*packedBytes) / float64(numValues)
+	row.ratio = rawBytes / float64(packedBytes)
+	row.encodeMBps = rawBytes / 1e6 / encodeTime.Seconds()
+	row.decodeMBps = rawBytes / 1e6 / decodeTime.Seconds()
+
+	return row
+}
+
+// Return the op parameter of the frame. Offset shifts the values by their
+// minimum and Delta starts from the first value.
+func reportOpParam[T packer.Number](values []T, op packer.PackOp) T {
+	switch op {
+	case packer.Offset:
+		minVal := values[0]
+		for _, v := range values {
+			if v < minVal {
+				minVal = v
+			}
+		}
+		return -minVal
+	case packer.Delta:
+		return values[0]
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressReport_Columns(t *testing.T) {
+
+	input := "time\tprice\tvolume\n"
+	for i := 0; i < 50; i++ {
+		input += strings.Join([]string{"1700000000", "10.25", "100"}, "\t") + "\n"
+	}
+
+	cfg, err := parseReportArgs(
+		[]string{"-header", "-columns", "price,2", "-frame-size", "16", "data.tsv"}, io.Discard)
+	assert.Nil(t, err)
+	assert.Equal(t, '\t', cfg.delimiter)
+
+	out := &bytes.Buffer{}
+	err = compressReport(strings.NewReader(input), out, cfg)
+	assert.Nil(t, err)
+
+	report := out.String()
+	assert.Contains(t, report, "Column price: 50 values, 4 frames of 16 values")
+	assert.Contains(t, report, "Column volume: 50 values")
+	assert.Contains(t, report, "Chimp")
+
+	// Adaptive is reported once per column, naming the codec it picked
+	assert.Equal(t, 2, strings.Count(report, "Adaptive"))
+	assert.Contains(t, report, "Adaptive: RLE/")
+
+	// Skipped codecs are listed
+	assert.Contains(t, report, "Quantized (lossy, requires -max-error)")
+	assert.Contains(t, report, "Simple8b (no Float64 support)")
+
+	cfg.maxError = 0.01
+	out.Reset()
+	assert.Nil(t, compressReport(strings.NewReader(input), out, cfg))
+	assert.Regexp(t, `Quantized +NOP`, out.String())
+}
+
+func TestCompressReport_Decimal(t *testing.T) {
+
+	input := ""
+	for i := 0; i < 40; i++ {
+		input += "12.5\n-0.25\n"
+	}
+
+	cfg, err := parseReportArgs([]string{"-type", "decimal", "-scale", "2", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+
+	out := &bytes.Buffer{}
+	err = compressReport(strings.NewReader(input), out, cfg)
+	assert.Nil(t, err)
+	assert.Regexp(t, `Decimal +NOP`, out.String())
+	assert.Contains(t, out.String(), "ALP (no Decimal support)")
+
+	// Values with more digits than the scale are rejected
+	cfg, err = parseReportArgs([]string{"-type", "decimal", "-scale", "1", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+	assert.NotNil(t, compressReport(strings.NewReader(input), io.Discard, cfg))
+
+	_, err = parseReportArgs([]string{"-type", "decimal", "-scale", "19", "data.csv"}, io.Discard)
+	assert.NotNil(t, err)
+}
+
+func TestCompressReport_Float32(t *testing.T) {
+
+	input := ""
+	for i := 0; i < 40; i++ {
+		input += "21.5\n"
+	}
+
+	cfg, err := parseReportArgs([]string{"-type", "float32", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+
+	out := &bytes.Buffer{}
+	err = compressReport(strings.NewReader(input), out, cfg)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "Gorilla")
+
+	cfg, err = parseReportArgs([]string{"-type", "uint32", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+	err = compressReport(strings.NewReader("4294967296\n"), io.Discard, cfg)
+	assert.NotNil(t, err)
+}
+
+func TestCompressReport_InvalidInput(t *testing.T) {
+
+	_, err := parseReportArgs([]string{}, io.Discard)
+	assert.NotNil(t, err)
+
+	cfg, err := parseReportArgs([]string{"-columns", "3", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+	err = compressReport(strings.NewReader("1,2\n3,4\n"), io.Discard, cfg)
+	assert.NotNil(t, err)
+
+	cfg, err = parseReportArgs([]string{"-type", "int64", "data.csv"}, io.Discard)
+	assert.Nil(t, err)
+	err = compressReport(strings.NewReader("1.5\n"), io.Discard, cfg)
+	assert.NotNil(t, err)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/bzick/tokenizer"
 )
@@ -84,6 +85,10 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "compress-report" {
+		os.Exit(runCompressReport(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	query := "filter(if time > \"2022-01-01\" and temperature > 25 then true else false) | groupby([\"region\", \"department\"]) | window(1h) | rate(20m, 1m) | sort([\"column1\", \"column2\"]) | limit(10) | sum(cpu_usage)"
 
 	// configure tokenizer
@@ -0,0 +1,322 @@
+package bitmap
+
+// Run-length compressed bitsets. A bitmap holds the runs of consecutive set
+// bits of a sequence of bits, so long stretches of identical values (a check
+// being up for hours, an alert that never fires) take a few bytes whatever
+// their length. Logical operations are evaluated on the runs without
+// expanding the bits.
+//
+// Layout of the binary form (see MarshalBinary), all fields being unsigned
+// varints:
+//
+//	length    number of bits of the bitmap
+//	numRuns   number of runs of set bits
+//	          for every run, the number of unset bits before the run followed
+//	          by the number of set bits of the run
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// Sequence of bits compressed as the runs of its set bits
+type Bitmap struct {
+
+	// Runs of set bits, in order and separated by at least one unset bit
+	runs []Run
+
+	// Number of bits
+	length uint64
+}
+
+// Run of consecutive set bits
+type Run struct {
+
+	// Index of the first set bit of the run
+	Start uint64
+
+	// Number of set bits of the run
+	Length uint64
+}
+
+// Create an empty bitmap
+func New() *Bitmap {
+	return &Bitmap{runs: nil, length: 0}
+}
+
+// Create a bitmap holding the specified bits
+func FromBools(values []bool) *Bitmap {
+	b := New()
+	for _, v := range values {
+		b.Append(v)
+	}
+	return b
+}
+
+// Append a bit after the last bit of the bitmap
+func (b *Bitmap) Append(v bool) {
+	b.AppendRun(v, 1)
+}
+
+// Append n bits of the same value after the last bit of the bitmap
+func (b *Bitmap) AppendRun(v bool, n uint64) {
+	if v {
+		b.appendRun(b.length, b.length+n)
+	}
+	b.length += n
+}
+
+// Set the bit at the specified index to v. Returns nil on success, otherwise
+// returns the error if the index is out of bound.
+func (b *Bitmap) Set(index uint64, v bool) error {
+	if index >= b.length {
+		return errors.New("index out of bound")
+	}
+
+	ndx := b.search(index)
+	isSet := ndx < len(b.runs) && b.runs[ndx].Start <= index
+	if isSet == v {
+		return nil
+	}
+
+	if !v {
+		// Split the run holding the bit
+		r := b.runs[ndx]
+		tail := Run{Start: index + 1, Length: r.Start + r.Length - index - 1}
+		b.runs[ndx].Length = index - r.Start
+		switch {
+		case b.runs[ndx].Length == 0 && tail.Length == 0:
+			b.runs = append(b.runs[:ndx], b.runs[ndx+1:]...)
+		case b.runs[ndx].Length == 0:
+			b.runs[ndx] = tail
+		case tail.Length > 0:
+			b.runs = append(b.runs[:ndx+1], append([]Run{tail}, b.runs[ndx+1:]...)...)
+		}
+		return nil
+	}
+
+	// Extend the adjacent runs, merging them if the bit joins them
+	joinsPrev := ndx > 0 && b.runs[ndx-1].Start+b.runs[ndx-1].Length == index
+	joinsNext := ndx < len(b.runs) && b.runs[ndx].Start == index+1
+	switch {
+	case joinsPrev && joinsNext:
+		b.runs[ndx-1].Length += 1 + b.runs[ndx].Length
+		b.runs = append(b.runs[:ndx], b.runs[ndx+1:]...)
+	case joinsPrev:
+		b.runs[ndx-1].Length++
+	case joinsNext:
+		b.runs[ndx].Start--
+		b.runs[ndx].Length++
+	default:
+		b.runs = append(b.runs[:ndx], append([]Run{{Start: index, Length: 1}}, b.runs[ndx:]...)...)
+	}
+	return nil
+}
+
+// Return the bit at the specified index along with nil error, otherwise
+// returns (false, error) if the index is out of bound.
+func (b *Bitmap) Get(index uint64) (bool, error) {
+	if index >= b.length {
+		return false, errors.New("index out of bound")
+	}
+
+	ndx := b.search(index)
+	return ndx < len(b.runs) && b.runs[ndx].Start <= index, nil
+}
+
+// Return the number of bits of the bitmap
+func (b *Bitmap) Len() uint64 {
+	return b.length
+}
+
+// Return the number of set bits of the bitmap
+func (b *Bitmap) Count() uint64 {
+	count := uint64(0)
+	for _, r := range b.runs {
+		count += r.Length
+	}
+	return count
+}
+
+// Return the runs of set bits of the bitmap in order. The returned slice must
+// not be modified.
+func (b *Bitmap) Runs() []Run {
+	return b.runs
+}
+
+// Call visit with the index of every set bit of the bitmap, in order
+func (b *Bitmap) ForEachSet(visit func(index uint64)) {
+	for _, r := range b.runs {
+		for i := r.Start; i < r.Start+r.Length; i++ {
+			visit(i)
+		}
+	}
+}
+
+// Return the bitwise and of the bitmaps along with nil error, otherwise
+// returns (nil, error) if the bitmaps differ in length.
+func (b *Bitmap) And(other *Bitmap) (*Bitmap, error) {
+	return b.combine(other, func(x, y bool) bool { return x && y })
+}
+
+// Return the bitwise or of the bitmaps along with nil error, otherwise
+// returns (nil, error) if the bitmaps differ in length.
+func (b *Bitmap) Or(other *Bitmap) (*Bitmap, error) {
+	return b.combine(other, func(x, y bool) bool { return x || y })
+}
+
+// Return the bitwise exclusive or of the bitmaps along with nil error,
+// otherwise returns (nil, error) if the bitmaps differ in length.
+func (b *Bitmap) Xor(other *Bitmap) (*Bitmap, error) {
+	return b.combine(other, func(x, y bool) bool { return x != y })
+}
+
+// Return the complement of the bitmap
+func (b *Bitmap) Not() *Bitmap {
+	res := New()
+	start := uint64(0)
+	for _, r := range b.runs {
+		res.appendRun(start, r.Start)
+		start = r.Start + r.Length
+	}
+	res.appendRun(start, b.length)
+	res.length = b.length
+	return res
+}
+
+// Return the binary form of the bitmap. Never returns an error.
+func (b *Bitmap) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2*binary.MaxVarintLen64*(len(b.runs)+1))
+	data = binary.AppendUvarint(data, b.length)
+	data = binary.AppendUvarint(data, uint64(len(b.runs)))
+
+	end := uint64(0)
+	for _, r := range b.runs {
+		data = binary.AppendUvarint(data, r.Start-end)
+		data = binary.AppendUvarint(data, r.Length)
+		end = r.Start + r.Length
+	}
+
+	return data, nil
+}
+
+// Replace the bits of the bitmap by the bits of the binary form produced by
+// MarshalBinary. Returns nil on success, otherwise returns the error and
+// leaves the bitmap unchanged.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	next := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errors.New("truncated bitmap")
+		}
+		data = data[n:]
+		return v, nil
+	}
+
+	length, err := next()
+	if err != nil {
+		return err
+	}
+	numRuns, err := next()
+	if err != nil {
+		return err
+	}
+	if numRuns > uint64(len(data))/2 {
+		return errors.New("truncated bitmap")
+	}
+
+	runs := make([]Run, 0, numRuns)
+	end := uint64(0)
+	for i := uint64(0); i < numRuns; i++ {
+		gap, err := next()
+		if err != nil {
+			return err
+		}
+		n, err := next()
+		if err != nil {
+			return err
+		}
+		start := end + gap
+		if n == 0 || (i > 0 && gap == 0) || start < end || start+n < start || start+n > length {
+			return errors.New("invalid bitmap run")
+		}
+		runs = append(runs, Run{Start: start, Length: n})
+		end = start + n
+	}
+
+	b.runs = runs
+	b.length = length
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the index of the first run ending after the specified bit
+func (b *Bitmap) search(index uint64) int {
+	return sort.Search(len(b.runs), func(i int) bool {
+		return b.runs[i].Start+b.runs[i].Length > index
+	})
+}
+
+// Set the bits in [start, end), which must be after the last run, merging the
+// bits with the last run if they are adjacent.
+func (b *Bitmap) appendRun(start, end uint64) {
+	if end <= start {
+		return
+	}
+	if last := len(b.runs) - 1; last >= 0 && b.runs[last].Start+b.runs[last].Length == start {
+		b.runs[last].Length += end - start
+		return
+	}
+	b.runs = append(b.runs, Run{Start: start, Length: end - start})
+}
+
+// Return the bitmap whose bits are f of the bits of both bitmaps. The runs of
+// both bitmaps are swept together, every boundary of a run flipping the bit of
+// its bitmap, so f is evaluated once per segment between boundaries.
+func (b *Bitmap) combine(other *Bitmap, f func(x, y bool) bool) (*Bitmap, error) {
+	if other == nil || b.length != other.length {
+		return nil, errors.New("bitmaps differ in length")
+	}
+
+	res := New()
+	ba, bb := boundaries(b.runs), boundaries(other.runs)
+	ia, ib := 0, 0
+	x, y := false, false
+	pos := uint64(0)
+	for pos < b.length {
+		for ia < len(ba) && ba[ia] == pos {
+			x, ia = !x, ia+1
+		}
+		for ib < len(bb) && bb[ib] == pos {
+			y, ib = !y, ib+1
+		}
+
+		next := b.length
+		if ia < len(ba) && ba[ia] < next {
+			next = ba[ia]
+		}
+		if ib < len(bb) && bb[ib] < next {
+			next = bb[ib]
+		}
+		if f(x, y) {
+			res.appendRun(pos, next)
+		}
+		pos = next
+	}
+	res.length = b.length
+
+	return res, nil
+}
+
+// Return the start and the end of every run, in order
+func boundaries(runs []Run) []uint64 {
+	res := make([]uint64, 0, 2*len(runs))
+	for _, r := range runs {
+		res = append(res, r.Start, r.Start+r.Length)
+	}
+	return res
+}
@@ -0,0 +1,125 @@
+package bitmap
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitmap_AppendGet(t *testing.T) {
+
+	values := []bool{false, true, true, false, false, true, true, true, false, true}
+	b := FromBools(values)
+	assert.Equal(t, uint64(len(values)), b.Len())
+	assert.Equal(t, uint64(6), b.Count())
+	assert.Equal(t, []Run{{1, 2}, {5, 3}, {9, 1}}, b.Runs())
+
+	for i, v := range values {
+		got, err := b.Get(uint64(i))
+		assert.Nil(t, err)
+		assert.Equal(t, v, got, i)
+	}
+	_, err := b.Get(uint64(len(values)))
+	assert.NotNil(t, err)
+
+	var set []uint64
+	b.ForEachSet(func(i uint64) { set = append(set, i) })
+	assert.Equal(t, []uint64{1, 2, 5, 6, 7, 9}, set)
+
+	// Long runs are held as a single run
+	b = New()
+	b.AppendRun(true, 1<<40)
+	b.AppendRun(true, 5)
+	b.AppendRun(false, 3)
+	assert.Equal(t, []Run{{0, 1<<40 + 5}}, b.Runs())
+	assert.Equal(t, uint64(1<<40+8), b.Len())
+}
+
+func TestBitmap_Logical(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(7))
+	a := make([]bool, 2000)
+	c := make([]bool, 2000)
+	for i := range a {
+		a[i] = (i/40)%3 == 0 || rnd.Intn(50) == 0
+		c[i] = (i/25)%2 == 0
+	}
+	ba, bc := FromBools(a), FromBools(c)
+
+	and, err := ba.And(bc)
+	assert.Nil(t, err)
+	or, err := ba.Or(bc)
+	assert.Nil(t, err)
+	xor, err := ba.Xor(bc)
+	assert.Nil(t, err)
+	not := ba.Not()
+
+	for i := range a {
+		assert.Equal(t, a[i] && c[i], get(t, and, i))
+		assert.Equal(t, a[i] || c[i], get(t, or, i))
+		assert.Equal(t, a[i] != c[i], get(t, xor, i))
+		assert.Equal(t, !a[i], get(t, not, i))
+	}
+	assert.Equal(t, ba.Len(), not.Len())
+	assert.Equal(t, ba.Runs(), not.Not().Runs())
+
+	_, err = ba.And(FromBools([]bool{true}))
+	assert.NotNil(t, err)
+	_, err = ba.Or(nil)
+	assert.NotNil(t, err)
+}
+
+func TestBitmap_Set(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(11))
+	values := make([]bool, 500)
+	for i := range values {
+		values[i] = (i/20)%2 == 0
+	}
+	b := FromBools(values)
+
+	for n := 0; n < 2000; n++ {
+		i := rnd.Intn(len(values))
+		v := rnd.Intn(2) == 0
+		values[i] = v
+		assert.Nil(t, b.Set(uint64(i), v))
+	}
+	for i, v := range values {
+		assert.Equal(t, v, get(t, b, i), i)
+	}
+
+	// Runs are kept separated by unset bits
+	assert.Equal(t, FromBools(values).Runs(), b.Runs())
+	assert.NotNil(t, b.Set(uint64(len(values)), true))
+}
+
+func TestBitmap_MarshalBinary(t *testing.T) {
+
+	b := New()
+	b.AppendRun(false, 3600)
+	b.AppendRun(true, 120)
+	b.AppendRun(false, 80000)
+	b.AppendRun(true, 7)
+
+	data, err := b.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Less(t, len(data), 16)
+
+	res := New()
+	assert.Nil(t, res.UnmarshalBinary(data))
+	assert.Equal(t, b.Len(), res.Len())
+	assert.Equal(t, b.Runs(), res.Runs())
+
+	// Truncated and inconsistent forms are rejected
+	assert.NotNil(t, res.UnmarshalBinary(data[:len(data)-1]))
+	assert.NotNil(t, res.UnmarshalBinary([]byte{2, 1, 0, 3}))
+	assert.NotNil(t, res.UnmarshalBinary(nil))
+	assert.Equal(t, b.Runs(), res.Runs())
+}
+
+func get(t *testing.T, b *Bitmap, i int) bool {
+	v, err := b.Get(uint64(i))
+	assert.Nil(t, err)
+	return v
+}
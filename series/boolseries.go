@@ -0,0 +1,219 @@
+package series
+
+import (
+	"errors"
+
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/rmravindran/ats/series/frame"
+)
+
+// Series of boolean values (up/down checks, alert states, predicate results)
+// over time. The values are held in a run-length compressed bitmap instead of
+// one integer per value, the times being stored in frames like the times of a
+// numeric series.
+type BoolSeries struct {
+
+	// Frames for time
+	timeFrames []*frame.Frame[uint64]
+
+	// Values of the series
+	bits *bitmap.Bitmap
+
+	// Frame size
+	frameSize int
+
+	// Last frame offset
+	lastFrameOffset int
+
+	// Construction options
+	opts options
+}
+
+// Creates a new boolean series where every time frame is of the specified
+// frameSize. By default time frames are packed with DeltaOfDelta; use
+// WithTimeCodec to select another registered codec.
+func NewBoolSeries(frameSize int, opts ...Option) *BoolSeries {
+	series := &BoolSeries{
+		timeFrames:      nil,
+		bits:            bitmap.New(),
+		frameSize:       frameSize,
+		lastFrameOffset: 0,
+		opts:            defaultOptions(),
+	}
+
+	for _, opt := range opts {
+		opt(&series.opts)
+	}
+
+	return series
+}
+
+// Creates a new boolean series holding the bits of the bitmap at the
+// specified times. Returns the series along with nil error, otherwise returns
+// (nil, error).
+func BoolFromBitmap(
+	times []uint64, bits *bitmap.Bitmap, frameSize int, opts ...Option) (*BoolSeries, error) {
+
+	if bits == nil || uint64(len(times)) != bits.Len() {
+		return nil, errors.New("times and bits differ in length")
+	}
+
+	series := NewBoolSeries(frameSize, opts...)
+	for ndx, t := range times {
+		if err := series.appendTime(ndx, t); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range bits.Runs() {
+		series.bits.AppendRun(false, r.Start-series.bits.Len())
+		series.bits.AppendRun(true, r.Length)
+	}
+	series.bits.AppendRun(false, bits.Len()-series.bits.Len())
+
+	return series, nil
+}
+
+// Appends a value to the series
+func (series *BoolSeries) AppendValue(time uint64, value bool) error {
+	if err := series.appendTime(series.Size(), time); err != nil {
+		return err
+	}
+	series.bits.Append(value)
+
+	return nil
+}
+
+// Return the time and the value at the specified index
+func (series *BoolSeries) Value(index int) (uint64, bool, error) {
+	t, err := series.Time(index)
+	if err != nil {
+		return 0, false, err
+	}
+	v, err := series.bits.Get(uint64(index))
+	if err != nil {
+		return 0, false, err
+	}
+
+	return t, v, nil
+}
+
+// Return the time at the specified index
+func (series *BoolSeries) Time(index int) (uint64, error) {
+	if index < 0 || index >= series.Size() {
+		return 0, errors.New("index out of bound")
+	}
+
+	frameIndex := index / series.frameSize
+	return series.timeFrames[frameIndex].Value(index - frameIndex*series.frameSize)
+}
+
+// Return the times of the series, decoded frame by frame
+func (series *BoolSeries) Times() ([]uint64, error) {
+	times := make([]uint64, 0, series.Size())
+	for i := range series.timeFrames {
+		f, n, err := series.TimeFrame(i)
+		if err != nil {
+			return nil, err
+		}
+		dec, err := f.Decoder()
+		if err != nil {
+			return nil, err
+		}
+		chunk := make([]uint64, n)
+		for filled := 0; filled < n; {
+			read, err := dec.DecodeNext(chunk[filled:])
+			if err != nil {
+				return nil, err
+			}
+			filled += read
+		}
+		times = append(times, chunk...)
+	}
+
+	return times, nil
+}
+
+// Return the bitmap of the values of the series. The bitmap must not be
+// modified.
+func (series *BoolSeries) Bits() *bitmap.Bitmap {
+	return series.bits
+}
+
+func (series *BoolSeries) Size() int {
+	return int(series.bits.Len())
+}
+
+func (series *BoolSeries) FrameSize() int {
+	return series.frameSize
+}
+
+// Return the number of time frames of the series
+func (series *BoolSeries) NumFrames() int {
+	return len(series.timeFrames)
+}
+
+// Pack every full time frame of the series, the frame being filled is left as
+// is. Packed frames release their unpacked times if reduce is true. The
+// values are always held compressed.
+func (series *BoolSeries) Finalize(reduce bool) error {
+	for i := range series.timeFrames {
+		if i == len(series.timeFrames)-1 && series.lastFrameOffset < series.frameSize {
+			break
+		}
+		if err := series.timeFrames[i].Finalize(reduce); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Return the time frame at the specified index along with the number of
+// values of the series it holds (see Series.TimeFrame).
+func (series *BoolSeries) TimeFrame(index int) (*frame.Frame[uint64], int, error) {
+	if index < 0 || index >= len(series.timeFrames) {
+		return nil, 0, errors.New("frame index out of bound")
+	}
+
+	n := series.frameSize
+	if index == len(series.timeFrames)-1 {
+		n = series.lastFrameOffset
+	}
+	return series.timeFrames[index], n, nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Store the time of the value at the specified index, the index being the
+// number of times already stored. The value is appended by the caller.
+func (series *BoolSeries) appendTime(index int, time uint64) error {
+	frameIndex := index / series.frameSize
+	if frameIndex >= len(series.timeFrames) {
+		if err := series.appendFrame(); err != nil {
+			return err
+		}
+	}
+
+	err := appendToFrame(series.timeFrames[frameIndex], series.lastFrameOffset, time)
+	if err != nil {
+		return err
+	}
+	series.lastFrameOffset++
+
+	return nil
+}
+
+func (series *BoolSeries) appendFrame() error {
+	pT, err := newTimePacker(&series.opts)
+	if err != nil {
+		return err
+	}
+
+	fT := newFrame[uint64](uint64(series.frameSize), pT, &series.opts)
+	series.timeFrames = append(series.timeFrames, fT)
+	series.lastFrameOffset = 0
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package series
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/rmravindran/ats/series/packer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolSeries_Basic(t *testing.T) {
+
+	s := NewBoolSeries(64, WithTimeCodec(packer.CodecSimple8b))
+
+	// Check failing for a while in the middle of the series
+	for i := 0; i < 1000; i++ {
+		err := s.AppendValue(uint64(i*30), i < 400 || i >= 430)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 1000, s.Size())
+	assert.Equal(t, 16, s.NumFrames())
+	assert.Nil(t, s.Finalize(true))
+
+	for _, i := range []int{0, 399, 400, 429, 430, 999} {
+		time, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i*30), time)
+		assert.Equal(t, i < 400 || i >= 430, v, i)
+	}
+	_, _, err := s.Value(1000)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 2, len(s.Bits().Runs()))
+	assert.Equal(t, uint64(970), s.Bits().Count())
+
+	times, err := s.Times()
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, len(times))
+	assert.Equal(t, uint64(999*30), times[999])
+}
+
+func TestBoolSeries_FromBitmap(t *testing.T) {
+
+	bits := bitmap.FromBools([]bool{true, false, false, true, true})
+	s, err := BoolFromBitmap([]uint64{10, 20, 30, 40, 50}, bits, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, s.Size())
+	assert.Equal(t, 3, s.NumFrames())
+	assert.Equal(t, bits.Runs(), s.Bits().Runs())
+
+	time, v, err := s.Value(3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(40), time)
+	assert.True(t, v)
+
+	_, err = BoolFromBitmap([]uint64{10}, bits, 2)
+	assert.NotNil(t, err)
+}
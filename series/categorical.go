@@ -0,0 +1,294 @@
+package series
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/rmravindran/ats/series/frame"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Series of categorical values (status labels, log levels, enum states) over
+// time. Every frame of the series has its own dictionary of the labels
+// appearing in it, and the labels are stored as uint32 codes into that
+// dictionary in frames packed with an integer codec. The code frames follow
+// the lifecycle of the frames of a numeric series (see Finalize); packed code
+// frames carry their dictionary, so they can be decoded without the series
+// (see DecodeCategoricalFrame).
+type CategoricalSeries struct {
+
+	// Codes of the labels, frame i holding codes into dictionaries[i]
+	codes *Series[uint32]
+
+	// Dictionaries of the frames
+	dictionaries []*Dictionary
+}
+
+// Labels of a frame of a categorical series, a label being identified by its
+// code, the position at which it was added to the dictionary.
+type Dictionary struct {
+	labels []string
+	codes  map[string]uint32
+}
+
+// Creates a new categorical series where every frame is of the specified
+// frameSize. By default time frames are packed with DeltaOfDelta and code
+// frames with RLE, which suits labels held over many consecutive values; use
+// WithTimeCodec and WithValueCodec to select other registered integer codecs.
+func NewCategoricalSeries(frameSize int, opts ...Option) *CategoricalSeries {
+	opts = append([]Option{WithValueCodec(packer.CodecRLE)}, opts...)
+	return &CategoricalSeries{
+		codes:        NewSeries[uint32](frameSize, opts...),
+		dictionaries: nil,
+	}
+}
+
+// Creates a new categorical series holding the labels at the specified times,
+// appended in order. Returns the series along with nil error, otherwise
+// returns (nil, error).
+func CategoricalFromSlices(
+	times []uint64, labels []string, frameSize int, opts ...Option) (*CategoricalSeries, error) {
+
+	if len(times) != len(labels) {
+		return nil, errors.New("times and labels differ in length")
+	}
+
+	series := NewCategoricalSeries(frameSize, opts...)
+	for ndx := range labels {
+		if err := series.AppendValue(times[ndx], labels[ndx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return series, nil
+}
+
+// Appends a label to the series
+func (series *CategoricalSeries) AppendValue(time uint64, label string) error {
+	frameIndex := series.codes.Size() / series.codes.FrameSize()
+	if frameIndex >= len(series.dictionaries) {
+		series.dictionaries = append(series.dictionaries, newDictionary())
+	}
+
+	dict := series.dictionaries[frameIndex]
+	code, added := dict.add(label)
+	if err := series.codes.AppendValue(time, code); err != nil {
+		if added {
+			dict.removeLast()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Set label at the specified index
+func (series *CategoricalSeries) SetValue(index int, time uint64, label string) error {
+	if index < 0 || index >= series.codes.Size() {
+		return errors.New("index out of bound")
+	}
+
+	dict := series.dictionaries[index/series.codes.FrameSize()]
+	code, added := dict.add(label)
+	if err := series.codes.SetValue(index, time, code); err != nil {
+		if added {
+			dict.removeLast()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Return the time and the label at the specified index
+func (series *CategoricalSeries) Value(index int) (uint64, string, error) {
+	t, code, err := series.Code(index)
+	if err != nil {
+		return 0, "", err
+	}
+
+	label, err := series.dictionaries[index/series.codes.FrameSize()].Label(code)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return t, label, nil
+}
+
+// Return the time and the code of the label at the specified index, the code
+// being relative to the dictionary of the frame holding the label.
+func (series *CategoricalSeries) Code(index int) (uint64, uint32, error) {
+	if index < 0 {
+		return 0, 0, errors.New("index out of bound")
+	}
+	return series.codes.Value(index)
+}
+
+func (series *CategoricalSeries) Size() int {
+	return series.codes.Size()
+}
+
+func (series *CategoricalSeries) FrameSize() int {
+	return series.codes.FrameSize()
+}
+
+// Return the number of frames of the series
+func (series *CategoricalSeries) NumFrames() int {
+	return series.codes.NumFrames()
+}
+
+// Pack every full frame of the series, the frame being filled is left as is.
+// Packed frames release their unpacked codes if reduce is true. The dictionary
+// of every packed frame is stored in its packed buffer (see
+// packer.AppendLabels).
+func (series *CategoricalSeries) Finalize(reduce bool) error {
+	if err := series.codes.Finalize(reduce); err != nil {
+		return err
+	}
+
+	for ndx, dict := range series.dictionaries {
+		f, _, err := series.codes.ValueFrame(ndx)
+		if err != nil {
+			return err
+		}
+
+		// Frames left unpacked or already holding their dictionary are skipped
+		buffer := f.Buffer()
+		if buffer == nil {
+			continue
+		}
+		hdr, err := packer.ReadHeader(buffer)
+		if err != nil {
+			return err
+		}
+		if hdr.HasFlag(packer.FlagLabels) {
+			continue
+		}
+		if err := packer.AppendLabels(buffer, dict.Labels()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Return the dictionary of the frame at the specified index
+func (series *CategoricalSeries) Dictionary(index int) (*Dictionary, error) {
+	if index < 0 || index >= len(series.dictionaries) {
+		return nil, errors.New("frame index out of bound")
+	}
+	return series.dictionaries[index], nil
+}
+
+// Return the time frame at the specified index along with the number of
+// values of the series it holds (see Series.TimeFrame).
+func (series *CategoricalSeries) TimeFrame(index int) (*frame.Frame[uint64], int, error) {
+	return series.codes.TimeFrame(index)
+}
+
+// Return the code frame at the specified index along with the number of
+// values of the series it holds (see Series.ValueFrame). The codes index the
+// dictionary of the frame.
+func (series *CategoricalSeries) CodeFrame(index int) (*frame.Frame[uint32], int, error) {
+	return series.codes.ValueFrame(index)
+}
+
+// Return the id of the codec used to pack the code frames
+func (series *CategoricalSeries) CodeCodec() packer.CodecID {
+	return series.codes.ValueCodec()
+}
+
+// Return the labels of the values of a packed code frame of a categorical
+// series (see CategoricalSeries.CodeFrame) decoded from its buffer alone, the
+// dictionary being read from the buffer. Returns the labels along with nil
+// error, otherwise returns (nil, error).
+func DecodeCategoricalFrame(buffer *bytes.Buffer) ([]string, error) {
+	hdr, err := packer.ReadHeader(buffer)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := packer.ReadLabels(buffer)
+	if err != nil {
+		return nil, err
+	}
+	if !hdr.HasFlag(packer.FlagLabels) {
+		return nil, errors.New("buffer holds no dictionary")
+	}
+
+	p, err := packer.New[uint32](hdr.Codec)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]uint32, hdr.NumElements)
+	if _, err := p.Unpack(buffer, codes, packer.NOP, 0); err != nil {
+		return nil, err
+	}
+
+	res := make([]string, len(codes))
+	for ndx, code := range codes {
+		if uint64(code) >= uint64(len(labels)) {
+			return nil, fmt.Errorf("%w: code not in dictionary", packer.ErrCorrupt)
+		}
+		res[ndx] = labels[code]
+	}
+
+	return res, nil
+}
+
+// Return the label of the specified code. Returns the label along with nil
+// error, otherwise returns ("", error) if the code is not in the dictionary.
+func (dict *Dictionary) Label(code uint32) (string, error) {
+	if uint64(code) >= uint64(len(dict.labels)) {
+		return "", errors.New("code not in dictionary")
+	}
+	return dict.labels[code], nil
+}
+
+// Return the code of the label and true if the label is in the dictionary,
+// otherwise returns (0, false).
+func (dict *Dictionary) Code(label string) (uint32, bool) {
+	code, ok := dict.codes[label]
+	return code, ok
+}
+
+// Return the number of labels in the dictionary
+func (dict *Dictionary) Len() int {
+	return len(dict.labels)
+}
+
+// Return the labels of the dictionary ordered by code. The returned slice
+// must not be modified.
+func (dict *Dictionary) Labels() []string {
+	return dict.labels
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func newDictionary() *Dictionary {
+	return &Dictionary{
+		labels: nil,
+		codes:  make(map[string]uint32),
+	}
+}
+
+// Return the code of the label and true if the label was added to the
+// dictionary, false if it was already in it.
+func (dict *Dictionary) add(label string) (uint32, bool) {
+	if code, ok := dict.codes[label]; ok {
+		return code, false
+	}
+	code := uint32(len(dict.labels))
+	dict.labels = append(dict.labels, label)
+	dict.codes[label] = code
+	return code, true
+}
+
+// Remove the last label added, to undo an add whose value could not be stored
+func (dict *Dictionary) removeLast() {
+	last := len(dict.labels) - 1
+	delete(dict.codes, dict.labels[last])
+	dict.labels = dict.labels[:last]
+}
@@ -0,0 +1,137 @@
+package series
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rmravindran/ats/series/packer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoricalSeries_Basic(t *testing.T) {
+
+	s := NewCategoricalSeries(4)
+	assert.Equal(t, packer.CodecRLE, s.CodeCodec())
+
+	labels := []string{"ok", "ok", "warn", "ok", "error", "error", "ok", "ok", "ok", "warn"}
+	for i, label := range labels {
+		err := s.AppendValue(uint64(i*10), label)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 10, s.Size())
+	assert.Equal(t, 3, s.NumFrames())
+	assert.Nil(t, s.Finalize(true))
+
+	for i, label := range labels {
+		time, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i*10), time)
+		assert.Equal(t, label, v)
+	}
+
+	// Every frame has its own dictionary
+	dict, err := s.Dictionary(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"error", "ok"}, dict.Labels())
+	code, ok := dict.Code("ok")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), code)
+	_, ok = dict.Code("warn")
+	assert.False(t, ok)
+	_, err = dict.Label(2)
+	assert.NotNil(t, err)
+
+	_, code, err = s.Code(5)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), code)
+
+	// Setting a value of a packed frame adds the label to its dictionary
+	assert.Nil(t, s.SetValue(5, 50, "critical"))
+	_, v, err := s.Value(5)
+	assert.Nil(t, err)
+	assert.Equal(t, "critical", v)
+	assert.Equal(t, 3, dict.Len())
+
+	_, _, err = s.Value(10)
+	assert.NotNil(t, err)
+	assert.NotNil(t, s.SetValue(-1, 0, "ok"))
+	_, err = s.Dictionary(3)
+	assert.NotNil(t, err)
+}
+
+// Packed code frames are decoded without the series
+func TestCategoricalSeries_PackedDictionary(t *testing.T) {
+
+	labels := make([]string, 100)
+	for i := range labels {
+		labels[i] = []string{"idle", "running", "failed"}[(i/7)%3]
+	}
+	s, err := CategoricalFromSlices(make([]uint64, 100), labels, 32,
+		WithValueCodec(packer.CodecSimple8b))
+	assert.Nil(t, err)
+	assert.Nil(t, s.Finalize(true))
+
+	decode := func(index int) []string {
+		f, _, err := s.CodeFrame(index)
+		assert.Nil(t, err)
+		assert.NotNil(t, f.Buffer())
+		copied := bytes.NewBuffer(append([]byte{}, f.Buffer().Bytes()...))
+		res, err := DecodeCategoricalFrame(copied)
+		assert.Nil(t, err)
+		return res
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, labels[i*32:(i+1)*32], decode(i))
+	}
+
+	// The last frame is not packed yet
+	f, _, err := s.CodeFrame(3)
+	assert.Nil(t, err)
+	assert.Nil(t, f.Buffer())
+
+	// Frames repacked after a change carry their new dictionary
+	assert.Nil(t, s.SetValue(40, 0, "paused"))
+	labels[40] = "paused"
+	assert.Nil(t, s.Finalize(true))
+	assert.Equal(t, labels[32:64], decode(1))
+	assert.Nil(t, s.Finalize(true))
+	assert.Equal(t, labels[32:64], decode(1))
+
+	// The series still reads its packed frames
+	for i, label := range labels {
+		_, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, label, v)
+	}
+
+	// Blocks without dictionary are rejected
+	numeric := NewSeries[uint32](4)
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, numeric.AppendValue(uint64(i), uint32(i)))
+	}
+	assert.Nil(t, numeric.Finalize(true))
+	fN, _, err := numeric.ValueFrame(0)
+	assert.Nil(t, err)
+	_, err = DecodeCategoricalFrame(fN.Buffer())
+	assert.NotNil(t, err)
+}
+
+func TestCategoricalSeries_FromSlices(t *testing.T) {
+
+	s, err := CategoricalFromSlices(
+		[]uint64{1, 2, 3}, []string{"a", "b", "a"}, 2, WithValueCodec(packer.CodecSimple8b))
+	assert.Nil(t, err)
+	assert.Equal(t, packer.CodecSimple8b, s.CodeCodec())
+	assert.Equal(t, 3, s.Size())
+
+	_, err = CategoricalFromSlices([]uint64{1}, []string{"a", "b"}, 2)
+	assert.NotNil(t, err)
+
+	// Codes cannot be packed with float codecs, the failed label is not kept
+	s = NewCategoricalSeries(2, WithValueCodec(packer.CodecQuantized))
+	assert.NotNil(t, s.AppendValue(0, "a"))
+	assert.Equal(t, 0, s.Size())
+	dict, err := s.Dictionary(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, dict.Len())
+}
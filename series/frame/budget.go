@@ -0,0 +1,195 @@
+package frame
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Memory budget bounding the memory held by the values that frames decode
+// from their packed buffers. Reading a compact frame unpacks its values and
+// keeps them resident so later reads are served natively; frames sharing a
+// budget account those values in it, and once the budget is exceeded the
+// values of the least recently used frames are dropped, returning the frames
+// to their compact state. Dropped values are decoded again when the frame is
+// next read.
+//
+// Only values that are a copy of a packed buffer are accounted. The values of
+// frames being filled or modified, which cannot be dropped until the frame is
+// finalized, are not. A budget may be shared by the frames of many series; the
+// bookkeeping of the budget is safe for concurrent use, but the frames sharing
+// it must not be used concurrently since reading one frame may evict another.
+type Budget struct {
+	mu sync.Mutex
+
+	// Maximum number of bytes of decoded values
+	limit uint64
+
+	// Number of bytes of decoded values currently accounted
+	used uint64
+
+	// Accounted frames, the most recently used first
+	lru *list.List
+
+	// Element of the lru list of every accounted frame
+	entries map[evictable]*list.Element
+
+	// Counters of the budget
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// Snapshot of the counters and usage of a budget
+type BudgetMetrics struct {
+
+	// Number of reads of accounted frames served by resident values
+	Hits uint64
+
+	// Number of reads that had to decode the values of a frame
+	Misses uint64
+
+	// Number of frames whose values were dropped to honour the limit
+	Evictions uint64
+
+	// Number of bytes of decoded values currently accounted
+	Used uint64
+
+	// Maximum number of bytes of decoded values
+	Limit uint64
+
+	// Number of frames whose values are currently accounted
+	Frames int
+}
+
+// Create a budget holding at most limit bytes of decoded values
+func NewBudget(limit uint64) *Budget {
+	return &Budget{
+		limit:   limit,
+		used:    0,
+		lru:     list.New(),
+		entries: make(map[evictable]*list.Element),
+	}
+}
+
+// Return the maximum number of bytes of decoded values
+func (b *Budget) Limit() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
+
+// Set the maximum number of bytes of decoded values, evicting the least
+// recently used frames until the accounted values fit the new limit.
+func (b *Budget) SetLimit(limit uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = limit
+	b.evictLocked(nil)
+}
+
+// Return the number of bytes of decoded values currently accounted
+func (b *Budget) Used() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// Return a snapshot of the counters and usage of the budget
+func (b *Budget) Metrics() BudgetMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BudgetMetrics{
+		Hits:      b.hits,
+		Misses:    b.misses,
+		Evictions: b.evictions,
+		Used:      b.used,
+		Limit:     b.limit,
+		Frames:    b.lru.Len(),
+	}
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Implemented by the frames whose decoded values can be dropped
+type evictable interface {
+
+	// Drop the decoded values. Called with the lock of the budget held, so it
+	// must not call back into the budget.
+	evict()
+}
+
+// Accounted frame and the number of bytes of its decoded values
+type budgetEntry struct {
+	frame evictable
+	size  uint64
+}
+
+// Record a read of the frame served by its resident values
+func (b *Budget) hit(e evictable) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if el, ok := b.entries[e]; ok {
+		b.lru.MoveToFront(el)
+		b.hits++
+	}
+}
+
+// Record a read of the frame that decoded size bytes of values and account
+// them (see admit).
+func (b *Budget) miss(e evictable, size uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.misses++
+	b.admitLocked(e, size)
+}
+
+// Account size bytes of decoded values of the frame as the most recently
+// used, evicting other frames if the limit is exceeded. The frame itself is
+// never evicted, so a frame larger than the limit stays resident until
+// another frame is read.
+func (b *Budget) admit(e evictable, size uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.admitLocked(e, size)
+}
+
+// Stop accounting the values of the frame
+func (b *Budget) release(e evictable) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if el, ok := b.entries[e]; ok {
+		b.removeLocked(el)
+	}
+}
+
+func (b *Budget) admitLocked(e evictable, size uint64) {
+	if el, ok := b.entries[e]; ok {
+		b.removeLocked(el)
+	}
+	b.entries[e] = b.lru.PushFront(&budgetEntry{frame: e, size: size})
+	b.used += size
+	b.evictLocked(e)
+}
+
+// Evict the least recently used frames, except keep, until the accounted
+// values fit the limit
+func (b *Budget) evictLocked(keep evictable) {
+	for el := b.lru.Back(); el != nil && b.used > b.limit; {
+		prev := el.Prev()
+		entry := el.Value.(*budgetEntry)
+		if entry.frame != keep {
+			b.removeLocked(el)
+			entry.frame.evict()
+			b.evictions++
+		}
+		el = prev
+	}
+}
+
+func (b *Budget) removeLocked(el *list.Element) {
+	entry := b.lru.Remove(el).(*budgetEntry)
+	delete(b.entries, entry.frame)
+	b.used -= entry.size
+}
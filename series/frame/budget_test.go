@@ -0,0 +1,78 @@
+package frame
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series/packer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget_Eviction(t *testing.T) {
+
+	// Room for the values of two frames
+	budget := NewBudget(2 * 100 * 8)
+
+	frames := make([]*Frame[float64], 4)
+	for i := range frames {
+		frames[i] = NewEmptyFrame[float64](100, packer.NewChimp[float64]())
+		for j := 0; j < 100; j++ {
+			assert.Nil(t, frames[i].SetValue(j, float64(i*100+j)))
+		}
+		assert.Nil(t, frames[i].Finalize(true))
+		frames[i].SetBudget(budget)
+	}
+	packed := frames[1].Size()
+
+	read := func(i int) {
+		values := frames[i].Values()
+		assert.Equal(t, 100, len(values))
+		assert.Equal(t, float64(i*100+99), values[99])
+	}
+
+	read(0)
+	read(1)
+	read(0)
+	m := budget.Metrics()
+	assert.Equal(t, uint64(1), m.Hits)
+	assert.Equal(t, uint64(2), m.Misses)
+	assert.Equal(t, uint64(0), m.Evictions)
+	assert.Equal(t, uint64(1600), m.Used)
+
+	// Frame 1 is the least recently used
+	read(2)
+	m = budget.Metrics()
+	assert.Equal(t, uint64(1), m.Evictions)
+	assert.Equal(t, 2, m.Frames)
+	assert.Equal(t, packed, frames[1].Size())
+	assert.Greater(t, frames[0].Size(), packed)
+
+	// Evicted frames are decoded again when read
+	read(1)
+	m = budget.Metrics()
+	assert.Equal(t, uint64(4), m.Misses)
+	assert.Equal(t, uint64(2), m.Evictions)
+	assert.Equal(t, uint64(1600), budget.Used())
+
+	// Modified values are no longer accounted, and cannot be evicted
+	assert.Nil(t, frames[1].SetValue(0, -1))
+	assert.Equal(t, uint64(800), budget.Used())
+	budget.SetLimit(0)
+	assert.Equal(t, uint64(0), budget.Used())
+	v, err := frames[1].Value(0)
+	assert.Nil(t, err)
+	assert.Equal(t, -1.0, v)
+
+	// Finalized values kept resident are accounted again, the frame being
+	// kept even though it exceeds the limit
+	assert.Nil(t, frames[1].Finalize(false))
+	assert.Equal(t, 1, budget.Metrics().Frames)
+	assert.Equal(t, uint64(800), budget.Used())
+	budget.SetLimit(1000)
+	assert.Nil(t, frames[1].Finalize(true))
+	read(3)
+	assert.Equal(t, uint64(800), budget.Used())
+	frames[3].SetBudget(nil)
+	assert.Equal(t, uint64(0), budget.Used())
+	assert.Equal(t, uint64(1000), budget.Limit())
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 
+	"github.com/rmravindran/ats/series/bitmap"
 	"github.com/rmravindran/ats/series/packer"
 )
 
@@ -29,6 +30,37 @@ type Frame[T packer.Number] struct {
 
 	// Indicates if the frame is dirty.
 	isDirty bool
+
+	// Encoder of the values of an appendable frame
+	appender packer.Appender[T]
+
+	// Maximum number of values of an appendable frame
+	capacity uint64
+
+	// Statistics of the values of an appendable frame
+	stats packer.Stats[T]
+
+	// Last segment of a compact frame decoded to read a single value
+	segment []T
+
+	// Index of the first value of the decoded segment
+	segmentStart uint64
+
+	// Indicates if the checksum of the packed buffer was verified
+	verified bool
+
+	// Bitmap of the present values, nil if no value is missing (see IsValid)
+	valid *bitmap.Bitmap
+
+	// Indicates if the validity of a packed frame was read from its buffer
+	validLoaded bool
+
+	// Budget accounting the values decoded from the packed buffer, if any
+	budget *Budget
+
+	// Indicates if the unpacked values are a copy of the packed buffer, which
+	// can be dropped (see Budget)
+	cached bool
 }
 
 //-----------------------------------------------------------------------------
@@ -46,12 +78,48 @@ func NewEmptyFrame[T packer.Number](size uint64, p packer.Packer[T]) *Frame[T] {
 		packOp:      packer.NOP,
 		packOpParam: 0.0,
 		isDirty:     true,
+		validLoaded: true,
 	}
 
 	return frame
 }
 
-// Create a new frame of the specified size for holding packed buffer
+// Create an empty frame that has the capacity to hold size elements and that
+// selects the codec and pack operation from the candidates of the config when
+// it is finalized. The selection is recorded in the header of the buffer.
+func NewAdaptiveFrame[T packer.Number](size uint64, config packer.AdaptiveConfig) *Frame[T] {
+	return NewEmptyFrame[T](size, packer.NewAdaptive[T](config))
+}
+
+// Create an empty frame that has the capacity to hold size elements, where
+// values are encoded incrementally as they are appended (see Append) instead
+// of being held unpacked. Returns the frame along with nil error, otherwise
+// returns (nil, error) if the packer does not support incremental encoding.
+func NewAppendableFrame[T packer.Number](size uint64, p packer.Packer[T]) (*Frame[T], error) {
+
+	ap, ok := p.(packer.AppendablePacker[T])
+	if !ok {
+		return nil, errors.New("packer does not support appending")
+	}
+
+	frame := &Frame[T]{
+		buffer:      nil,
+		values:      nil,
+		state:       Appending,
+		packer:      p,
+		packOp:      packer.NOP,
+		packOpParam: 0.0,
+		isDirty:     true,
+		capacity:    size,
+		validLoaded: true,
+	}
+	frame.appender = ap.NewAppender(frame.packOp, frame.packOpParam)
+
+	return frame, nil
+}
+
+// Create a new frame holding the specified packed buffer. The buffer is self
+// describing, so p does not need to be the packer instance that produced it.
 func NewPackedFrame[T packer.Number](buffer *bytes.Buffer, p packer.Packer[T]) *Frame[T] {
 
 	frame := &Frame[T]{
@@ -62,6 +130,7 @@ func NewPackedFrame[T packer.Number](buffer *bytes.Buffer, p packer.Packer[T]) *
 		packOp:      packer.NOP,
 		packOpParam: 0.0,
 		isDirty:     true,
+		validLoaded: false,
 	}
 
 	return frame
@@ -78,6 +147,7 @@ func NewUnpackedFrame[T packer.Number](values []T, p packer.Packer[T]) *Frame[T]
 		packOp:      packer.NOP,
 		packOpParam: 0.0,
 		isDirty:     true,
+		validLoaded: true,
 	}
 
 	return frame
@@ -96,15 +166,28 @@ func (frame *Frame[T]) SetValue(index int, value T) error {
 
 	// Unpack first
 
-	frame.unpackIfNeeded()
+	if err := frame.unpackIfNeeded(); err != nil {
+		return err
+	}
 
-	if index >= len(frame.values) {
+	if index < 0 || index >= len(frame.values) {
 		return errors.New("index out of bound")
 	}
 
 	// Set value at index
 
+	frame.uncache()
 	frame.values[index] = value
+	if frame.valid != nil {
+		frame.valid.Set(uint64(index), true)
+	}
+
+	// Values of an appendable frame are encoded again to keep the frame
+	// appendable
+
+	if frame.state == Appending {
+		frame.reencode()
+	}
 
 	// Mark the frame as dirty
 
@@ -115,31 +198,177 @@ func (frame *Frame[T]) SetValue(index int, value T) error {
 	return nil
 }
 
-// Finalize the frame by packing the data.
+// Append the value after the last value of an appendable frame. The value is
+// encoded right away, without unpacking the values already in the frame.
+func (frame *Frame[T]) Append(value T) error {
+
+	if frame.state != Appending {
+		return errors.New("frame is not appendable")
+	}
+	if frame.appender.NumElements() >= frame.capacity {
+		return errors.New("frame is full")
+	}
+
+	err := frame.appender.Append(value)
+	if err != nil {
+		return err
+	}
+	frame.stats.Add(value)
+	if frame.valid != nil {
+		frame.valid.Append(true)
+	}
+
+	// Unpacked values are no longer current
+	frame.values = nil
+
+	return nil
+}
+
+// Mark the element at the given index as missing (see IsValid). Missing
+// elements hold the zero value.
+func (frame *Frame[T]) SetNull(index int) error {
+
+	if frame.state == Unknown {
+		return errors.New("uninitialized frame")
+	}
+
+	// Unpack first
+
+	if err := frame.unpackIfNeeded(); err != nil {
+		return err
+	}
+
+	if index < 0 || index >= len(frame.values) {
+		return errors.New("index out of bound")
+	}
+
+	// Clear value at index and mark it missing
+
+	frame.uncache()
+	frame.values[index] = 0
+	frame.ensureValidity()
+	frame.valid.Set(uint64(index), false)
+
+	// Values of an appendable frame are encoded again to keep the frame
+	// appendable
+
+	if frame.state == Appending {
+		frame.reencode()
+	}
+
+	// Mark the frame as dirty
+
+	frame.isDirty = true
+
+	return nil
+}
+
+// Append a missing value after the last value of an appendable frame (see
+// IsValid). The missing value is encoded as the zero value.
+func (frame *Frame[T]) AppendNull() error {
+
+	if frame.state != Appending {
+		return errors.New("frame is not appendable")
+	}
+	if frame.appender.NumElements() >= frame.capacity {
+		return errors.New("frame is full")
+	}
+
+	frame.ensureValidity()
+	err := frame.appender.Append(0)
+	if err != nil {
+		return err
+	}
+	frame.valid.Append(false)
+
+	// Unpacked values are no longer current
+	frame.values = nil
+
+	return nil
+}
+
+// Account the values the frame decodes from its packed buffer in the
+// specified budget, so they are dropped when the budget is exceeded (see
+// Budget). A nil budget stops accounting the values, keeping them resident.
+func (frame *Frame[T]) SetBudget(b *Budget) {
+
+	if frame.budget == b {
+		return
+	}
+	if frame.budget != nil {
+		frame.budget.release(frame)
+	}
+	frame.budget = b
+	if frame.cached && b != nil {
+		b.admit(frame, frame.valuesSize())
+	}
+}
+
+// Finalize the frame by packing the data. The statistics of the values are
+// stored along with the packed data (see Stats). Finalizing an appendable
+// frame seals it, no more values can be appended afterwards.
 func (frame *Frame[T]) Finalize(reduce bool) error {
 
 	// If frame is not dirty, the nothing to do apart from releasing
-	// the memory if the options requires us to do so
+	// the memory if the options requires us to do so. The buffer is current,
+	// so the frame reads from it again once the values are released.
 	if !frame.isDirty {
 		if reduce {
 			frame.values = nil
+			frame.uncache()
+			frame.state = Compact
 		}
 		return nil
 	}
 
+	// Packed frames are repacked from their values
+	if frame.state == Compact {
+		if err := frame.unpackIfNeeded(); err != nil {
+			return err
+		}
+	}
+
 	if frame.buffer == nil {
 		frame.buffer = &bytes.Buffer{}
 	}
+	frame.buffer.Reset()
+	frame.segment = nil
+	frame.verified = false
+
+	var err error
+	if frame.state == Appending {
+		err = frame.appender.Snapshot(frame.buffer)
+		if err == nil {
+			err = packer.AppendStats(frame.buffer, frame.stats)
+		}
+	} else {
+		err = frame.packer.Pack(
+			frame.values, frame.buffer, frame.packOp, frame.packOpParam)
+		if err == nil {
+			err = packer.AppendStats(frame.buffer, frame.computeStats())
+		}
+	}
 
-	err := frame.packer.Pack(
-		frame.values, frame.buffer, frame.packOp, frame.packOpParam)
+	// The validity section is elided when no value is missing
+	if err == nil && frame.valid != nil {
+		if frame.valid.Count() == frame.valid.Len() {
+			frame.valid = nil
+		} else {
+			err = packer.AppendValidity(frame.buffer, frame.valid)
+		}
+	}
 
 	if err == nil {
 		frame.isDirty = false
 		frame.state = Compact
+		frame.appender = nil
 
-		if reduce {
+		// Values kept resident are now a copy of the packed buffer
+		if reduce || frame.values == nil {
 			frame.values = nil
+			frame.uncache()
+		} else {
+			frame.cache(false)
 		}
 	}
 
@@ -150,15 +379,27 @@ func (frame *Frame[T]) Finalize(reduce bool) error {
 //- ACCESSORS
 //-----------------------------------------------------------------------------
 
-// Return the value of the frame at the specified index.
+// Return the value of the frame at the specified index. On a compact frame
+// packed with restart points, only the segment holding the value is unpacked.
 func (frame *Frame[T]) Value(index int) (T, error) {
 
 	if frame.state == Unknown {
 		return 0, errors.New("uninitialized frame")
 	}
 
+	// Decode the segment holding the value if possible
+	if v, ok, err := frame.seek(index); ok || err != nil {
+		return v, err
+	}
+
 	// Unpack first
-	frame.unpackIfNeeded()
+	if err := frame.unpackIfNeeded(); err != nil {
+		return 0, err
+	}
+
+	if index < 0 || index >= len(frame.values) {
+		return 0, errors.New("index out of bound")
+	}
 
 	return frame.values[index], nil
 }
@@ -172,8 +413,8 @@ func (frame *Frame[T]) Buffer() *bytes.Buffer {
 	return frame.buffer
 }
 
-// If the frame is dirty returns nil, otherwise returns the packed values
-// of the frame.
+// Return the unpacked values of the frame, or nil if the frame is
+// uninitialized or its packed buffer cannot be unpacked.
 func (frame *Frame[T]) Values() []T {
 
 	if frame.state == Unknown {
@@ -182,36 +423,423 @@ func (frame *Frame[T]) Values() []T {
 
 	// Unpack first
 
-	frame.unpackIfNeeded()
+	if err := frame.unpackIfNeeded(); err != nil {
+		return nil
+	}
 
 	return frame.values
 }
 
+// Return a decoder streaming through the values of the frame in chunks (see
+// packer.Decoder). The values of a compact frame are decoded from its packed
+// buffer without being unpacked into the frame. The frame must not be
+// modified while the decoder is in use.
+func (frame *Frame[T]) Decoder() (packer.Decoder[T], error) {
+
+	if frame.state == Unknown {
+		return nil, errors.New("uninitialized frame")
+	}
+	if frame.values != nil {
+		frame.hit()
+		return packer.NewSliceDecoder(frame.values), nil
+	}
+
+	buffer := frame.buffer
+	if frame.state == Appending {
+		buffer = &bytes.Buffer{}
+		if err := frame.appender.Snapshot(buffer); err != nil {
+			return nil, err
+		}
+	}
+
+	return packer.NewDecoder(frame.packer, buffer)
+}
+
+// Evaluate the aggregate over the values of the frame without unpacking them
+// (see packer.AggregateBlock), arg being the argument of
+// packer.AggregateCountEqual. Compact frames are evaluated on their packed
+// buffer and appendable frames from their running statistics. Returns
+// (result, true, nil) if the aggregate was evaluated, (0, false, nil) if the
+// values have to be decoded, otherwise returns (0, false, error). Counting
+// aggregates are evaluated by CountPacked and returned as values (see
+// packer.CountValue).
+func (frame *Frame[T]) AggregatePacked(agg packer.Aggregate, arg T) (T, bool, error) {
+
+	if agg.IsCount() {
+		count, ok, err := frame.CountPacked(agg, arg)
+		if err != nil {
+			return 0, false, err
+		}
+		v, err := packer.CountValue[T](count)
+		if err != nil || !ok {
+			return 0, false, err
+		}
+		return v, true, nil
+	}
+
+	switch frame.state {
+	case Unknown:
+		return 0, false, errors.New("uninitialized frame")
+	case Compact:
+		return packer.AggregateBlock(frame.packer, frame.buffer, agg, arg)
+	case Appending:
+		switch agg {
+		case packer.AggregateSum:
+			return frame.stats.Sum, true, nil
+		case packer.AggregateMin:
+			return frame.stats.Min, frame.stats.Count > 0, nil
+		case packer.AggregateMax:
+			return frame.stats.Max, frame.stats.Count > 0, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// Evaluate the counting aggregate (see packer.Aggregate.IsCount) over the
+// values of the frame without unpacking them (see packer.CountBlock), arg
+// being the argument of packer.AggregateCountEqual. Returns (count, true,
+// nil) if the aggregate was evaluated, (0, false, nil) if the values have to
+// be decoded, otherwise returns (0, false, error).
+func (frame *Frame[T]) CountPacked(agg packer.Aggregate, arg T) (uint64, bool, error) {
+
+	switch frame.state {
+	case Unknown:
+		return 0, false, errors.New("uninitialized frame")
+	case Compact:
+		return packer.CountBlock(frame.packer, frame.buffer, agg, arg)
+	case Appending:
+		if agg == packer.AggregateCount {
+			return frame.stats.Count, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// Return true if the element at the given index is present, false if it is
+// missing (see SetNull), out of bound or its validity cannot be read from the
+// packed buffer.
+func (frame *Frame[T]) IsValid(index int) bool {
+
+	if frame.state == Unknown || index < 0 || uint64(index) >= frame.Length() {
+		return false
+	}
+	if err := frame.loadValidity(); err != nil {
+		return false
+	}
+	if frame.valid == nil {
+		return true
+	}
+
+	v, err := frame.valid.Get(uint64(index))
+	return err == nil && v
+}
+
+// Return true if elements of the frame are missing
+func (frame *Frame[T]) HasNulls() bool {
+	valid, err := frame.Validity()
+	return err != nil || valid != nil
+}
+
+// Return the bitmap of the present elements of the frame along with nil
+// error, or (nil, nil) if no element is missing. Otherwise returns (nil,
+// error). The bitmap must not be modified.
+func (frame *Frame[T]) Validity() (*bitmap.Bitmap, error) {
+
+	if err := frame.loadValidity(); err != nil {
+		return nil, err
+	}
+	if frame.valid == nil || frame.valid.Count() == frame.valid.Len() {
+		return nil, nil
+	}
+	return frame.valid, nil
+}
+
+// Return true if values can be appended to the frame (see Append).
+func (frame *Frame[T]) IsAppendable() bool {
+	return frame.state == Appending
+}
+
+// Return true if the frame is packed with a lossy codec, the unpacked values
+// being approximations within the error bound recorded in the header of the
+// packed buffer (see packer.FlagLossy).
+func (frame *Frame[T]) IsLossy() bool {
+	hdr, err := frame.Header()
+	return err == nil && hdr.HasFlag(packer.FlagLossy)
+}
+
+// Return the header of the packed buffer of the frame.
+func (frame *Frame[T]) Header() (packer.Header, error) {
+	return packer.ReadHeader(frame.buffer)
+}
+
+// Return the statistics of the values of the frame. The statistics of a
+// finalized frame are read from its packed buffer without unpacking the
+// values, unless the buffer was produced without statistics.
+func (frame *Frame[T]) Stats() (packer.Stats[T], error) {
+
+	if frame.state == Unknown {
+		return packer.Stats[T]{}, errors.New("uninitialized frame")
+	}
+
+	if frame.state == Appending {
+		return frame.stats, nil
+	}
+
+	if frame.state == Compact {
+		hdr, err := frame.Header()
+		if err != nil {
+			return packer.Stats[T]{}, err
+		}
+		if hdr.HasFlag(packer.FlagStats) {
+			return packer.ReadStats[T](frame.buffer)
+		}
+	}
+
+	if err := frame.unpackIfNeeded(); err != nil {
+		return packer.Stats[T]{}, err
+	}
+	return frame.computeStats(), nil
+}
+
 func (frame *Frame[T]) Length() uint64 {
 	if frame.state == Compact {
-		return frame.packer.NumElements()
+		hdr, err := frame.Header()
+		if err != nil {
+			return 0
+		}
+		return hdr.NumElements
+	}
+	if frame.state == Appending {
+		return frame.appender.NumElements()
 	}
 	return uint64(len(frame.values))
 }
 
 func (frame *Frame[T]) Size() uint64 {
-	if frame.values == nil {
-		return frame.packer.PackedSize()
+	var packedSize uint64 = 0
+	if frame.buffer != nil {
+		packedSize = uint64(frame.buffer.Len())
+	}
+	if frame.state == Appending {
+		packedSize = frame.appender.PackedSize()
 	}
 
-	return frame.packer.PackedSize() + uint64(8*len(frame.values))
+	return packedSize + packer.ElemTypeOf[T]().Size()*uint64(len(frame.values))
 }
 
 //-----------------------------------------------------------------------------
 //                              PRIVATE METHODS
 //-----------------------------------------------------------------------------
 
-func (frame *Frame[T]) unpackIfNeeded() {
+func (frame *Frame[T]) unpackIfNeeded() error {
+
+	if frame.state == Native {
+		frame.hit()
+	}
 
 	if frame.state == Compact {
-		frame.values = make([]T, frame.packer.NumElements())
-		frame.packer.Unpack(
-			frame.buffer, frame.values, frame.packOp, frame.packOpParam)
+		if err := frame.loadValidity(); err != nil {
+			return err
+		}
+		// The element count is checked against the payload by the header
+		hdr, err := frame.Header()
+		if err != nil {
+			return err
+		}
+		values := make([]T, hdr.NumElements)
+		_, err = frame.packer.Unpack(
+			frame.buffer, values, frame.packOp, frame.packOpParam)
+		if err != nil {
+			return err
+		}
+		frame.values = values
 		frame.state = Native
+		frame.cache(true)
+	}
+
+	// Unpack a snapshot of an appendable frame, keeping it appendable
+	if frame.state == Appending && frame.values == nil {
+		buffer := &bytes.Buffer{}
+		if err := frame.appender.Snapshot(buffer); err != nil {
+			return err
+		}
+		values := make([]T, frame.appender.NumElements())
+		_, err := frame.packer.Unpack(buffer, values, frame.packOp, frame.packOpParam)
+		if err != nil {
+			return err
+		}
+		frame.values = values
+	}
+
+	return nil
+}
+
+// Return the value at the specified index of a compact frame by decoding the
+// segment holding it, the last decoded segment being cached. Returns false if
+// the frame is not compact or was packed without restart points. The
+// checksum of the packed buffer is verified on the first seek.
+func (frame *Frame[T]) seek(index int) (T, bool, error) {
+
+	if frame.state != Compact || index < 0 {
+		return 0, false, nil
+	}
+
+	idx := uint64(index)
+	if idx >= frame.segmentStart && idx-frame.segmentStart < uint64(len(frame.segment)) {
+		return frame.segment[idx-frame.segmentStart], true, nil
 	}
+
+	seeker, ok := frame.packer.(packer.Seeker[T])
+	if !ok {
+		return 0, false, nil
+	}
+	hdr, err := frame.Header()
+	if err != nil || !hdr.HasFlag(packer.FlagIndex) {
+		return 0, false, nil
+	}
+	if idx >= hdr.NumElements {
+		return 0, false, errors.New("index out of bound")
+	}
+
+	if !frame.verified {
+		if err := packer.VerifyBlock(frame.buffer); err != nil {
+			return 0, false, err
+		}
+		frame.verified = true
+	}
+
+	length, err := packer.SegmentLength(frame.buffer)
+	if err != nil {
+		return 0, false, err
+	}
+	if uint64(cap(frame.segment)) < length {
+		frame.segment = make([]T, length)
+	}
+
+	start, n, err := seeker.UnpackSegment(frame.buffer, idx, frame.segment[:length])
+	if err != nil {
+		frame.segment = nil
+		return 0, false, err
+	}
+	frame.segment = frame.segment[:n]
+	frame.segmentStart = start
+
+	return frame.segment[idx-start], true, nil
+}
+
+// Encode the unpacked values of an appendable frame with a new appender
+func (frame *Frame[T]) reencode() {
+
+	ap := frame.packer.(packer.AppendablePacker[T])
+	frame.appender = ap.NewAppender(frame.packOp, frame.packOpParam)
+	frame.stats = packer.Stats[T]{}
+	for ndx, v := range frame.values {
+		frame.appender.Append(v)
+		if frame.isPresent(ndx) {
+			frame.stats.Add(v)
+		}
+	}
+}
+
+// Read the validity of a packed frame from its buffer, once
+func (frame *Frame[T]) loadValidity() error {
+
+	if frame.validLoaded {
+		return nil
+	}
+	valid, err := packer.ReadValidity(frame.buffer)
+	if err != nil {
+		return err
+	}
+	frame.valid = valid
+	frame.validLoaded = true
+
+	return nil
+}
+
+// Create the bitmap of the present elements if no element was missing so far
+func (frame *Frame[T]) ensureValidity() {
+
+	if frame.valid != nil {
+		return
+	}
+	frame.valid = bitmap.New()
+	frame.valid.AppendRun(true, frame.Length())
+}
+
+// Return true if the unpacked element at the given index is present
+func (frame *Frame[T]) isPresent(index int) bool {
+
+	if frame.valid == nil {
+		return true
+	}
+	v, _ := frame.valid.Get(uint64(index))
+	return v
+}
+
+// Compute the statistics of the present unpacked values
+func (frame *Frame[T]) computeStats() packer.Stats[T] {
+
+	if frame.valid == nil {
+		return packer.ComputeStats(frame.values)
+	}
+	stats := packer.Stats[T]{}
+	for ndx, v := range frame.values {
+		if frame.isPresent(ndx) {
+			stats.Add(v)
+		}
+	}
+	return stats
+}
+
+// Mark the unpacked values as a copy of the packed buffer and account them in
+// the budget, decoded being true if they were just decoded from the buffer.
+func (frame *Frame[T]) cache(decoded bool) {
+
+	frame.cached = true
+	if frame.budget == nil {
+		return
+	}
+	if decoded {
+		frame.budget.miss(frame, frame.valuesSize())
+	} else {
+		frame.budget.admit(frame, frame.valuesSize())
+	}
+}
+
+// Stop accounting the unpacked values, which are no longer a copy of the
+// packed buffer
+func (frame *Frame[T]) uncache() {
+
+	if !frame.cached {
+		return
+	}
+	frame.cached = false
+	if frame.budget != nil {
+		frame.budget.release(frame)
+	}
+}
+
+// Record a read served by the unpacked values
+func (frame *Frame[T]) hit() {
+
+	if frame.cached && frame.budget != nil {
+		frame.budget.hit(frame)
+	}
+}
+
+// Drop the unpacked values, returning the frame to its compact state. Called
+// by the budget, which already stopped accounting the values.
+func (frame *Frame[T]) evict() {
+
+	frame.values = nil
+	frame.cached = false
+	frame.state = Compact
+}
+
+// Return the number of bytes of the unpacked values
+func (frame *Frame[T]) valuesSize() uint64 {
+	return packer.ElemTypeOf[T]().Size() * uint64(len(frame.values))
 }
@@ -1,6 +1,9 @@
 package frame
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"testing"
 
 	"github.com/rmravindran/ats/series/packer"
@@ -24,15 +27,72 @@ func TestFrame_EmptyFrame(t *testing.T) {
 
 	// Finalize without releasing the unpacked array
 	fA.Finalize(false)
+	packed := packedSize(pA, fA.Values())
 
 	// Frame size should be packed size + unpacked array size
-	assert.Equal(t, uint64(10*8)+pA.PackedSize(), fA.Size())
+	assert.Equal(t, uint64(10*8)+packed+packer.StatsSize, fA.Size())
 
 	// Finalize the frame with release option.
 	fA.Finalize(true)
 
 	// Frame size should be just the packed size
-	assert.Equal(t, pA.PackedSize(), fA.Size())
+	assert.Equal(t, packed+packer.StatsSize, fA.Size())
+}
+
+// Native frames of 32-bit values hold 4 bytes per value
+func TestFrame_Float32Frame(t *testing.T) {
+
+	values := make([]float32, 10)
+	pA := packer.NewChimp[float32]()
+	fA := NewEmptyFrame[float32](10, pA)
+	for i := range values {
+		values[i] = float32(i) * 0.5
+		err := fA.SetValue(i, values[i])
+		assert.Nil(t, err)
+	}
+
+	fA.Finalize(false)
+	buffer := &bytes.Buffer{}
+	pA.Pack(values, buffer, packer.NOP, 0)
+	packed := uint64(buffer.Len())
+	assert.Equal(t, uint64(10*4)+packed+packer.StatsSize, fA.Size())
+
+	fA.Finalize(true)
+	v, err := fA.Value(7)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(3.5), v)
+}
+
+// Releasing the values of a clean frame that was read returns it to reading
+// from its packed buffer
+func TestFrame_FinalizeAfterRead(t *testing.T) {
+
+	fA := NewEmptyFrame[float64](10, packer.NewChimp[float64]())
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, fA.SetValue(i, float64(i)))
+	}
+	assert.Nil(t, fA.Finalize(true))
+
+	// Reading unpacks the frame, which is released again
+	v, err := fA.Value(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, v)
+	assert.Nil(t, fA.Finalize(true))
+
+	v, err = fA.Value(9)
+	assert.Nil(t, err)
+	assert.Equal(t, 9.0, v)
+	assert.Equal(t, uint64(10), fA.Length())
+	assert.Equal(t, 10, len(fA.Values()))
+
+	assert.Nil(t, fA.Finalize(true))
+	assert.Nil(t, fA.SetValue(9, -9))
+	v, err = fA.Value(9)
+	assert.Nil(t, err)
+	assert.Equal(t, -9.0, v)
+	v, err = fA.Value(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, v)
 }
 
 func TestFrame_UnPackedFrame(t *testing.T) {
@@ -51,15 +111,16 @@ func TestFrame_UnPackedFrame(t *testing.T) {
 
 	// Finalize without releasing the unpacked array
 	fA.Finalize(false)
+	packed := packedSize(pA, fA.Values())
 
 	// Frame size should be packed size + unpacked array size
-	assert.Equal(t, uint64(10*8)+pA.PackedSize(), fA.Size())
+	assert.Equal(t, uint64(10*8)+packed+packer.StatsSize, fA.Size())
 
 	// Finalize the frame with release option.
 	fA.Finalize(true)
 
 	// Frame size should be just the packed size
-	assert.Equal(t, pA.PackedSize(), fA.Size())
+	assert.Equal(t, packed+packer.StatsSize, fA.Size())
 }
 
 func TestFrame_PackedFrame(t *testing.T) {
@@ -93,13 +154,415 @@ func TestFrame_PackedFrame(t *testing.T) {
 
 	// Finalize without releasing the unpacked array
 	fA.Finalize(false)
+	packed := packedSize(pA, fA.Values())
 
 	// Frame size should be packed size + unpacked array size
-	assert.Equal(t, uint64(10*8)+pA.PackedSize(), fA.Size())
+	assert.Equal(t, uint64(10*8)+packed+packer.StatsSize, fA.Size())
 
 	// Finalize the frame with release option.
 	fA.Finalize(true)
 
 	// Frame size should be just the packed size
-	assert.Equal(t, pA.PackedSize(), fA.Size())
+	assert.Equal(t, packed+packer.StatsSize, fA.Size())
+}
+
+func TestFrame_PackedFrameWithFreshPacker(t *testing.T) {
+
+	values := make([]float64, 10)
+	for i := 0; i < 10; i++ {
+		values[i] = float64(i)
+	}
+	fA := NewUnpackedFrame[float64](values, packer.NewChimp[float64]())
+	fA.Finalize(true)
+
+	fB := NewPackedFrame[float64](fA.Buffer(), packer.NewChimp[float64]())
+
+	assert.Equal(t, uint64(10), fB.Length())
+	for i := 0; i < 10; i++ {
+		v, err := fB.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, float64(i), v)
+	}
+
+	hdr, err := fB.Header()
+	assert.Nil(t, err)
+	assert.Equal(t, packer.CodecChimp, hdr.Codec)
+}
+
+func TestFrame_AdaptiveFrame(t *testing.T) {
+
+	fA := NewAdaptiveFrame[int64](100, packer.AdaptiveConfig{})
+	for i := 0; i < 100; i++ {
+		fA.SetValue(i, 42)
+	}
+
+	err := fA.Finalize(true)
+	assert.Nil(t, err)
+
+	hdr, err := fA.Header()
+	assert.Nil(t, err)
+	assert.NotEqual(t, packer.CodecAdaptive, hdr.Codec)
+	assert.True(t, packer.Supports[int64](hdr.Codec))
+	assert.Equal(t, uint64(100), fA.Length())
+
+	for i := 0; i < 100; i++ {
+		v, err := fA.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(42), v)
+	}
+}
+
+func TestFrame_Stats(t *testing.T) {
+
+	pA := packer.NewGorilla[float64]()
+	fA := NewEmptyFrame[float64](10, pA)
+	for i := 0; i < 10; i++ {
+		fA.SetValue(i, float64(i)-2.5)
+	}
+
+	// Statistics of a native frame are computed from the values
+	stats, err := fA.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), stats.Count)
+	assert.Equal(t, -2.5, stats.Min)
+	assert.Equal(t, 6.5, stats.Max)
+	assert.Equal(t, 20.0, stats.Sum)
+	assert.Equal(t, -2.5, stats.First)
+	assert.Equal(t, 6.5, stats.Last)
+
+	// Statistics of a finalized frame are read from the packed buffer and
+	// do not require unpacking
+	err = fA.Finalize(true)
+	assert.Nil(t, err)
+
+	packed, err := fA.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats, packed)
+	assert.Nil(t, fA.values)
+
+	// Statistics survive a round trip of the buffer
+	fB := NewPackedFrame[float64](fA.Buffer(), packer.NewGorilla[float64]())
+	packed, err = fB.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats, packed)
+}
+
+func TestFrame_AggregatePacked(t *testing.T) {
+
+	fA := NewEmptyFrame[int64](10, packer.NewRLE[int64]())
+	for i := 0; i < 10; i++ {
+		fA.SetValue(i, int64(i/4))
+	}
+
+	// Values of a native frame have to be decoded
+	_, ok, err := fA.AggregatePacked(packer.AggregateCountEqual, 1)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// Runs of a compact frame are evaluated without unpacking
+	err = fA.Finalize(true)
+	assert.Nil(t, err)
+
+	v, ok, err := fA.AggregatePacked(packer.AggregateCountEqual, 1)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(4), v)
+
+	v, ok, err = fA.AggregatePacked(packer.AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), v)
+	assert.Nil(t, fA.values)
+}
+
+func TestFrame_CountPackedDecimal(t *testing.T) {
+
+	f := NewEmptyFrame[packer.Decimal](10, packer.NewRLE[packer.Decimal]())
+	for i := 0; i < 10; i++ {
+		f.SetValue(i, packer.Decimal(i/4*150))
+	}
+	assert.Nil(t, f.Finalize(true))
+
+	n, ok, err := f.CountPacked(packer.AggregateCountEqual, 150)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), n)
+
+	n, ok, err = f.CountPacked(packer.AggregateCount, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), n)
+
+	// Counts are never returned as Decimal values
+	_, _, err = f.AggregatePacked(packer.AggregateCount, 0)
+	assert.NotNil(t, err)
+
+	v, ok, err := f.AggregatePacked(packer.AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, packer.Decimal(1200), v)
+}
+
+func TestFrame_AppendableFrame(t *testing.T) {
+
+	fA, err := NewAppendableFrame[float64](10, packer.NewChimp[float64]())
+	assert.Nil(t, err)
+	assert.True(t, fA.IsAppendable())
+	assert.Equal(t, uint64(0), fA.Length())
+
+	for i := 0; i < 6; i++ {
+		err := fA.Append(float64(i) * 1.5)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, uint64(6), fA.Length())
+
+	// Values are held packed while appending
+	pA := packer.NewChimp[float64]()
+	assert.Nil(t, fA.values)
+	assert.Equal(t, packedSize(pA, []float64{0, 1.5, 3, 4.5, 6, 7.5}), fA.Size())
+
+	// Reading values keeps the frame appendable
+	v, err := fA.Value(3)
+	assert.Nil(t, err)
+	assert.Equal(t, 4.5, v)
+
+	// Setting a value keeps the frame appendable
+	err = fA.SetValue(0, -1.0)
+	assert.Nil(t, err)
+	assert.True(t, fA.IsAppendable())
+
+	for i := 6; i < 10; i++ {
+		err := fA.Append(float64(i) * 1.5)
+		assert.Nil(t, err)
+	}
+	assert.NotNil(t, fA.Append(99.0))
+
+	stats, err := fA.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), stats.Count)
+	assert.Equal(t, -1.0, stats.Min)
+	assert.Equal(t, 13.5, stats.Max)
+
+	// Finalized frame holds the same block as a packed frame
+	err = fA.Finalize(true)
+	assert.Nil(t, err)
+	assert.False(t, fA.IsAppendable())
+	assert.NotNil(t, fA.Append(1.0))
+
+	fB := NewPackedFrame[float64](fA.Buffer(), packer.NewChimp[float64]())
+	values := fB.Values()
+	assert.Equal(t, 10, len(values))
+	assert.Equal(t, -1.0, values[0])
+	for i := 1; i < 10; i++ {
+		assert.Equal(t, float64(i)*1.5, values[i])
+	}
+
+	packed, err := fB.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats, packed)
+
+	// Packers that do not support incremental encoding are rejected
+	_, err = NewAppendableFrame[float64](10, packer.NewALP[float64]())
+	assert.NotNil(t, err)
+}
+
+func TestFrame_SeekPackedFrame(t *testing.T) {
+
+	pA := packer.NewGorilla[float64]().WithRestartInterval(16)
+
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i) * 0.25
+	}
+	fA := NewUnpackedFrame[float64](values, pA)
+	err := fA.Finalize(true)
+	assert.Nil(t, err)
+
+	fB := NewPackedFrame[float64](fA.Buffer(), packer.NewGorilla[float64]())
+
+	// Reading single values only decodes the segment holding them
+	v, err := fB.Value(37)
+	assert.Nil(t, err)
+	assert.Equal(t, 9.25, v)
+	assert.Nil(t, fB.values)
+	assert.Equal(t, uint64(32), fB.segmentStart)
+	assert.Equal(t, 16, len(fB.segment))
+
+	v, err = fB.Value(99)
+	assert.Nil(t, err)
+	assert.Equal(t, 24.75, v)
+	assert.Equal(t, uint64(96), fB.segmentStart)
+	assert.Equal(t, 4, len(fB.segment))
+
+	// Unpacking all values still works
+	assert.Equal(t, values, fB.Values())
+}
+
+func TestFrame_CorruptPackedFrame(t *testing.T) {
+
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i) * 0.25
+	}
+
+	for _, interval := range []uint64{0, 16} {
+		pA := packer.NewChimp[float64]().WithRestartInterval(interval)
+		fA := NewUnpackedFrame[float64](values, pA)
+		fA.Finalize(true)
+
+		buffer := bytes.NewBuffer(append([]byte{}, fA.Buffer().Bytes()...))
+		buffer.Bytes()[packer.HeaderSize+3] ^= 0x01
+
+		fB := NewPackedFrame[float64](buffer, packer.NewChimp[float64]())
+		_, err := fB.Value(5)
+		assert.True(t, errors.Is(err, packer.ErrChecksumMismatch))
+		assert.Nil(t, fB.Values())
+
+		_, err = fB.Stats()
+		assert.True(t, errors.Is(err, packer.ErrChecksumMismatch))
+	}
+}
+
+// Return the size of the block packing the values
+func packedSize(p packer.Packer[float64], values []float64) uint64 {
+	buffer := &bytes.Buffer{}
+	p.Pack(values, buffer, packer.NOP, 0)
+	return uint64(buffer.Len())
+}
+
+func TestFrame_Decoder(t *testing.T) {
+
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i) * 0.25
+	}
+
+	decodeAll := func(f *Frame[float64]) []float64 {
+		dec, err := f.Decoder()
+		assert.Nil(t, err)
+
+		res := make([]float64, 0, len(values))
+		chunk := make([]float64, 16)
+		for {
+			n, err := dec.DecodeNext(chunk)
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			res = append(res, chunk[:n]...)
+		}
+		return res
+	}
+
+	// Unpacked frame
+	fA := NewUnpackedFrame[float64](values, packer.NewChimp[float64]())
+	assert.Equal(t, values, decodeAll(fA))
+
+	// Compact frame is decoded without being unpacked
+	fA.Finalize(true)
+	assert.Equal(t, values, decodeAll(fA))
+	assert.Nil(t, fA.values)
+	assert.Equal(t, Compact, fA.state)
+
+	// Appendable frame
+	fB, err := NewAppendableFrame[float64](100, packer.NewGorilla[float64]())
+	assert.Nil(t, err)
+	for _, v := range values[:40] {
+		fB.Append(v)
+	}
+	assert.Equal(t, values[:40], decodeAll(fB))
+
+	// Codec without incremental decoding
+	fC := NewUnpackedFrame[float64](values, packer.NewALP[float64]())
+	fC.Finalize(true)
+	assert.Equal(t, values, decodeAll(fC))
+}
+
+func TestFrame_Nulls(t *testing.T) {
+
+	f := NewEmptyFrame[int64](100, packer.NewSimple8b[int64]())
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, f.SetValue(i, int64(i)))
+	}
+	assert.False(t, f.HasNulls())
+
+	// A missing sample is told apart from a zero
+	for i := 40; i < 50; i++ {
+		assert.Nil(t, f.SetNull(i))
+	}
+	assert.Nil(t, f.SetValue(45, 0))
+	assert.True(t, f.HasNulls())
+	assert.Nil(t, f.Finalize(true))
+
+	hdr, err := f.Header()
+	assert.Nil(t, err)
+	assert.True(t, hdr.HasFlag(packer.FlagValidity))
+
+	stats, err := f.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(91), stats.Count)
+	assert.Equal(t, int64(4950-445), stats.Sum)
+
+	count, ok, err := f.AggregatePacked(packer.AggregateCount, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(91), count)
+	_, ok, err = f.AggregatePacked(packer.AggregateCountEqual, 0)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// The validity is carried by the packed buffer
+	g := NewPackedFrame[int64](f.Buffer(), packer.NewSimple8b[int64]())
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, i < 40 || i == 45 || i >= 50, g.IsValid(i), i)
+	}
+	assert.False(t, g.IsValid(100))
+	valid, err := g.Validity()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(91), valid.Count())
+
+	// Setting every missing value elides the validity section
+	for i := 40; i < 50; i++ {
+		assert.Nil(t, g.SetValue(i, int64(i)))
+	}
+	assert.Nil(t, g.Finalize(true))
+	hdr, err = g.Header()
+	assert.Nil(t, err)
+	assert.False(t, hdr.HasFlag(packer.FlagValidity))
+	assert.False(t, g.HasNulls())
+	v, err := g.Value(42)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), v)
+}
+
+func TestFrame_AppendNull(t *testing.T) {
+
+	f, err := NewAppendableFrame[float64](8, packer.NewGorilla[float64]())
+	assert.Nil(t, err)
+	assert.Nil(t, f.Append(1.5))
+	assert.Nil(t, f.AppendNull())
+	assert.Nil(t, f.Append(2.5))
+	assert.False(t, f.IsValid(1))
+	assert.True(t, f.IsValid(2))
+
+	stats, err := f.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), stats.Count)
+	assert.Equal(t, 1.5, stats.Min)
+
+	assert.Nil(t, f.SetNull(0))
+	stats, err = f.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), stats.Count)
+	assert.Nil(t, f.Append(3.5))
+
+	assert.Nil(t, f.Finalize(true))
+	assert.Equal(t, uint64(4), f.Length())
+	for i, exp := range []bool{false, false, true, true} {
+		assert.Equal(t, exp, f.IsValid(i), i)
+	}
+	assert.Equal(t, []float64{0, 0, 2.5, 3.5}, f.Values())
+
+	assert.NotNil(t, f.AppendNull())
+	assert.NotNil(t, NewEmptyFrame[float64](2, packer.NewChimp[float64]()).AppendNull())
 }
@@ -6,6 +6,7 @@ const (
 	Unknown FrameState = iota
 	Native
 	Compact
+	Appending
 )
 
 func (s FrameState) String() string {
@@ -16,6 +17,8 @@ func (s FrameState) String() string {
 		return "Native"
 	case Compact:
 		return "Compact"
+	case Appending:
+		return "Appending"
 	}
 	return "Invalid"
 }
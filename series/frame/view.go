@@ -0,0 +1,132 @@
+package frame
+
+import (
+	"errors"
+
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Read-only view of a range of elements of a frame. The view shares the frame
+// instead of copying its elements, which are read from the frame on access:
+// compact frames packed with restart points only decode the segments holding
+// the elements read (see Value). A view reflects later changes of the
+// elements of the frame, but its range is fixed when it is created.
+//
+// Views are read directly by the ops of the series/ops package. Frames hold no
+// times, so the times of the elements are read from a time frame attached to
+// the view (see WithTimes); views without times use the positions of the
+// elements in the frame as their times.
+type FrameView[T packer.Number] struct {
+
+	// Viewed frame
+	frame *Frame[T]
+
+	// Frame holding the times of the elements of the viewed frame, if any
+	times *Frame[uint64]
+
+	// Index of the first element of the view in the frame
+	from int
+
+	// Index past the last element of the view in the frame
+	to int
+}
+
+// Return a view of the elements of the frame in [from, to) along with nil
+// error, otherwise returns (nil, error) if the range is out of bound.
+func (frame *Frame[T]) Slice(from, to int) (*FrameView[T], error) {
+	if frame.state == Unknown {
+		return nil, errors.New("uninitialized frame")
+	}
+	if from < 0 || to < from || uint64(to) > frame.Length() {
+		return nil, errors.New("slice out of bound")
+	}
+	return &FrameView[T]{frame: frame, times: nil, from: from, to: to}, nil
+}
+
+// Return a view of the elements of the view in [from, to) along with nil
+// error, otherwise returns (nil, error) if the range is out of bound.
+func (view *FrameView[T]) Slice(from, to int) (*FrameView[T], error) {
+	if from < 0 || to < from || to > view.Length() {
+		return nil, errors.New("slice out of bound")
+	}
+	return &FrameView[T]{
+		frame: view.frame, times: view.times, from: view.from + from, to: view.from + to}, nil
+}
+
+// Return a view of the same elements whose times are read from the elements
+// at the same positions of the specified time frame, such as the time frame
+// of a series matching its value frame (see series.Series.TimeFrame). Returns
+// the view along with nil error, otherwise returns (nil, error) if the time
+// frame holds fewer elements than the viewed frame.
+func (view *FrameView[T]) WithTimes(times *Frame[uint64]) (*FrameView[T], error) {
+	if times == nil || times.state == Unknown || times.Length() < uint64(view.to) {
+		return nil, errors.New("time frame does not cover the view")
+	}
+	return &FrameView[T]{frame: view.frame, times: times, from: view.from, to: view.to}, nil
+}
+
+// Return true if the times of the elements are read from a time frame (see
+// WithTimes), false if they are the positions of the elements in the frame.
+func (view *FrameView[T]) HasTimes() bool {
+	return view.times != nil
+}
+
+// Return the element at the specified index of the view along with nil error,
+// otherwise returns (0, error).
+func (view *FrameView[T]) Value(index int) (T, error) {
+	if index < 0 || index >= view.Length() {
+		return 0, errors.New("index out of bound")
+	}
+	return view.frame.Value(view.from + index)
+}
+
+// Return the unpacked elements of the view, or nil if the elements of the
+// frame cannot be unpacked. The returned slice shares the unpacked values of
+// the frame and must not be modified.
+func (view *FrameView[T]) Values() []T {
+	values := view.frame.Values()
+	if values == nil {
+		return nil
+	}
+	return values[view.from:view.to:view.to]
+}
+
+// Return the element at the specified index, or 0 if it cannot be read.
+func (view *FrameView[T]) ValueAt(idx int) T {
+	v, _ := view.Value(idx)
+	return v
+}
+
+// Return the time of the element at the specified index, read from the time
+// frame of the view, or 0 if it cannot be read. Views without times (see
+// WithTimes) return the position of the element in the frame, so the results
+// of ops keep the order of the elements.
+func (view *FrameView[T]) TimeAt(idx int) uint64 {
+	if idx < 0 || idx >= view.Length() {
+		return 0
+	}
+	if view.times == nil {
+		return uint64(view.from + idx)
+	}
+	t, _ := view.times.Value(view.from + idx)
+	return t
+}
+
+// Return true if the element at the specified index is present (see
+// Frame.IsValid).
+func (view *FrameView[T]) IsValidAt(idx int) bool {
+	if idx < 0 || idx >= view.Length() {
+		return false
+	}
+	return view.frame.IsValid(view.from + idx)
+}
+
+// Return true if the view holds no element.
+func (view *FrameView[T]) IsEmpty() bool {
+	return view.to == view.from
+}
+
+// Return the number of elements of the view.
+func (view *FrameView[T]) Length() int {
+	return view.to - view.from
+}
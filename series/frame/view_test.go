@@ -0,0 +1,78 @@
+package frame
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series/packer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameView_Slice(t *testing.T) {
+
+	f := NewEmptyFrame[int64](100, packer.NewChimp[int64]().WithRestartInterval(16))
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, f.SetValue(i, int64(i*3)))
+	}
+	assert.Nil(t, f.SetNull(42))
+	assert.Nil(t, f.Finalize(true))
+	packed := f.Size()
+
+	view, err := f.Slice(40, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, view.Length())
+	assert.False(t, view.IsEmpty())
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, i != 2, view.IsValidAt(i), i)
+		if i != 2 {
+			assert.Equal(t, int64((40+i)*3), view.ValueAt(i))
+		}
+		assert.Equal(t, uint64(40+i), view.TimeAt(i))
+	}
+
+	// Reading the view only decodes the segments holding it
+	assert.Equal(t, packed, f.Size())
+
+	sub, err := view.Slice(5, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, sub.Length())
+	v, err := sub.Value(0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(45*3), v)
+	assert.Equal(t, []int64{135, 138, 141, 144, 147}, sub.Values())
+
+	_, err = sub.Value(5)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(0), sub.ValueAt(-1))
+	assert.False(t, sub.IsValidAt(5))
+
+	// Times are read from the attached time frame
+	times := NewEmptyFrame[uint64](100, packer.NewDeltaOfDelta[uint64]())
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, times.SetValue(i, uint64(1000+i*10)))
+	}
+	assert.False(t, sub.HasTimes())
+	timed, err := sub.WithTimes(times)
+	assert.Nil(t, err)
+	assert.True(t, timed.HasTimes())
+	assert.Equal(t, uint64(1450), timed.TimeAt(0))
+	timed, err = timed.Slice(1, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1460), timed.TimeAt(0))
+	assert.Equal(t, uint64(0), timed.TimeAt(2))
+	_, err = view.WithTimes(NewEmptyFrame[uint64](50, packer.NewDeltaOfDelta[uint64]()))
+	assert.NotNil(t, err)
+	_, err = view.WithTimes(nil)
+	assert.NotNil(t, err)
+
+	empty, err := f.Slice(100, 100)
+	assert.Nil(t, err)
+	assert.True(t, empty.IsEmpty())
+
+	_, err = f.Slice(10, 101)
+	assert.NotNil(t, err)
+	_, err = f.Slice(10, 9)
+	assert.NotNil(t, err)
+	_, err = view.Slice(0, 21)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,148 @@
+package gen
+
+// Generators of synthetic time series for tests and benchmarks. Every
+// generator is seeded, so the same seed and parameters always produce the same
+// values. Values are produced as float64 and converted to the element type:
+// integer types are rounded and unsigned types are clamped at zero.
+//
+// The generated slices can be used directly with the packers or turned into a
+// series with series.FromSlices.
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Element types of the generated values
+type Number interface {
+	~int64 | ~uint64 | ~float64 | ~int32 | ~uint32 | ~float32
+}
+
+// Random walk starting at start, every value moving from the previous one by a
+// step drawn uniformly in [-maxStep, maxStep].
+func RandomWalk[T Number](seed int64, n int, start, maxStep float64) []T {
+	rnd := rand.New(rand.NewSource(seed))
+
+	values := make([]T, n)
+	v := start
+	for i := range values {
+		values[i] = convert[T](v)
+		v += (2*rnd.Float64() - 1) * maxStep
+	}
+	return values
+}
+
+// Sinusoid of the specified amplitude and period (in number of values) around
+// offset, with uniform noise in [-noise, noise] added to every value.
+func Sinusoid[T Number](
+	seed int64, n int, offset, amplitude, period, noise float64) []T {
+
+	rnd := rand.New(rand.NewSource(seed))
+
+	values := make([]T, n)
+	for i := range values {
+		v := offset + amplitude*math.Sin(2*math.Pi*float64(i)/period)
+		values[i] = convert[T](v + (2*rnd.Float64()-1)*noise)
+	}
+	return values
+}
+
+// Monotone counter starting at zero, incremented by a value drawn uniformly in
+// [0, maxIncrement] at every step and reset to zero with the specified
+// probability, as a restarted process resets its counters.
+func Counter[T Number](seed int64, n int, maxIncrement, resetProbability float64) []T {
+	rnd := rand.New(rand.NewSource(seed))
+
+	values := make([]T, n)
+	v := 0.0
+	for i := range values {
+		if i > 0 && rnd.Float64() < resetProbability {
+			v = 0
+		}
+		values[i] = convert[T](v)
+		v += rnd.Float64() * maxIncrement
+	}
+	return values
+}
+
+// Step function holding levels drawn uniformly in [low, high] for a number of
+// values drawn uniformly in [1, 2*meanLength-1].
+func Steps[T Number](seed int64, n int, low, high float64, meanLength int) []T {
+	rnd := rand.New(rand.NewSource(seed))
+	if meanLength < 1 {
+		meanLength = 1
+	}
+
+	values := make([]T, n)
+	for i := 0; i < n; {
+		level := convert[T](low + rnd.Float64()*(high-low))
+		length := 1 + rnd.Intn(2*meanLength-1)
+		for ; length > 0 && i < n; length-- {
+			values[i] = level
+			i++
+		}
+	}
+	return values
+}
+
+// Constant base value with spikes of height drawn uniformly in [0, height]
+// added with the specified probability, as in error counts or latency
+// outliers.
+func Spikes[T Number](seed int64, n int, base, height, probability float64) []T {
+	rnd := rand.New(rand.NewSource(seed))
+
+	values := make([]T, n)
+	for i := range values {
+		v := base
+		if rnd.Float64() < probability {
+			v += rnd.Float64() * height
+		}
+		values[i] = convert[T](v)
+	}
+	return values
+}
+
+// Timestamps starting at start, spaced by interval with a jitter drawn
+// uniformly in [-jitter, jitter]. The jitter is limited to less than half of
+// the interval so that timestamps with a positive interval are strictly
+// increasing.
+func Timestamps(seed int64, n int, start, interval, jitter uint64) []uint64 {
+	rnd := rand.New(rand.NewSource(seed))
+	if interval == 0 {
+		jitter = 0
+	} else if 2*jitter >= interval {
+		jitter = (interval - 1) / 2
+	}
+
+	times := make([]uint64, n)
+	for i := range times {
+		t := start + uint64(i)*interval
+		if jitter > 0 {
+			t += uint64(rnd.Int63n(int64(2*jitter + 1)))
+			if t < jitter {
+				t = jitter
+			}
+			t -= jitter
+		}
+		times[i] = t
+	}
+	return times
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Converts the value to the element type, rounding integers and clamping
+// unsigned values at zero
+func convert[T Number](v float64) T {
+	var zero T
+	if zero-1 > 0 && v < 0 {
+		return zero
+	}
+	half := 0.5
+	if T(half) != 0 {
+		return T(v)
+	}
+	return T(math.Round(v))
+}
@@ -0,0 +1,92 @@
+package gen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGen_Reproducible(t *testing.T) {
+
+	assert.Equal(t, RandomWalk[float64](7, 100, 50, 1), RandomWalk[float64](7, 100, 50, 1))
+	assert.NotEqual(t, RandomWalk[float64](7, 100, 50, 1), RandomWalk[float64](8, 100, 50, 1))
+	assert.Equal(t, Timestamps(3, 100, 0, 60, 10), Timestamps(3, 100, 0, 60, 10))
+	assert.Equal(t, Spikes[int64](3, 100, 5, 100, 0.1), Spikes[int64](3, 100, 5, 100, 0.1))
+}
+
+func TestGen_RandomWalk(t *testing.T) {
+
+	a := RandomWalk[float64](1, 1000, 100, 0.5)
+	assert.Equal(t, 1000, len(a))
+	assert.Equal(t, 100.0, a[0])
+	for i := 1; i < len(a); i++ {
+		assert.LessOrEqual(t, math.Abs(a[i]-a[i-1]), 0.5)
+	}
+
+	// Unsigned values are clamped at zero
+	for _, v := range RandomWalk[uint64](1, 1000, 0, 10) {
+		assert.Less(t, v, uint64(1<<32))
+	}
+}
+
+func TestGen_Sinusoid(t *testing.T) {
+
+	a := Sinusoid[float64](1, 1000, 20, 5, 100, 0.1)
+	for i, v := range a {
+		expected := 20 + 5*math.Sin(2*math.Pi*float64(i)/100)
+		assert.InDelta(t, expected, v, 0.1)
+	}
+}
+
+func TestGen_Counter(t *testing.T) {
+
+	a := Counter[uint64](1, 1000, 10, 0.01)
+	resets := 0
+	for i := 1; i < len(a); i++ {
+		if a[i] < a[i-1] {
+			resets++
+			assert.LessOrEqual(t, a[i], uint64(10))
+		}
+	}
+	assert.Greater(t, resets, 0)
+	assert.Less(t, resets, 50)
+}
+
+func TestGen_Steps(t *testing.T) {
+
+	a := Steps[int64](1, 1000, -10, 10, 20)
+	changes := 0
+	for i := 1; i < len(a); i++ {
+		if a[i] != a[i-1] {
+			changes++
+		}
+		assert.GreaterOrEqual(t, a[i], int64(-10))
+		assert.LessOrEqual(t, a[i], int64(10))
+	}
+	assert.Greater(t, changes, 10)
+	assert.Less(t, changes, 100)
+}
+
+func TestGen_Spikes(t *testing.T) {
+
+	a := Spikes[float64](1, 1000, 1, 50, 0.05)
+	spikes := 0
+	for _, v := range a {
+		if v != 1 {
+			spikes++
+		}
+	}
+	assert.Greater(t, spikes, 20)
+	assert.Less(t, spikes, 100)
+}
+
+func TestGen_Timestamps(t *testing.T) {
+
+	times := Timestamps(1, 1000, 5, 60, 100)
+	for i := 1; i < len(times); i++ {
+		assert.Greater(t, times[i], times[i-1])
+		assert.InDelta(t, float64(5+i*60), float64(times[i]), 29)
+	}
+	assert.Equal(t, []uint64{0, 0, 0}, Timestamps(1, 3, 0, 0, 10))
+}
@@ -0,0 +1,203 @@
+package ops
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Number of values decoded at a time by aggregates falling back to decoding
+const aggregateChunkSize = 256
+
+// ----------------------------------------------------------------------------
+// - Aggregates
+// ----------------------------------------------------------------------------
+
+// Evaluate the aggregate over the values of the series, arg being the argument
+// of packer.AggregateCountEqual. Every frame is first asked to evaluate the
+// aggregate on its packed values (see frame.Frame.AggregatePacked), so frames
+// whose codec or statistics can answer it are not unpacked; the values of the
+// other frames are decoded in chunks. Missing values are left out of the
+// aggregate (see NullSkip). Counting aggregates are evaluated by Count and
+// returned as values (see packer.CountValue). Returns the result along with
+// nil error, otherwise returns (0, error).
+func Aggregate[T packer.Number](
+	s *series.Series[T], agg packer.Aggregate, arg T) (T, error) {
+
+	if s == nil {
+		return 0, errors.New("invalid series for aggregate")
+	}
+	if agg.IsCount() {
+		count, err := Count(s, agg, arg)
+		if err != nil {
+			return 0, err
+		}
+		return packer.CountValue[T](count)
+	}
+
+	res := aggregateResult[T]{agg: agg}
+	chunk := make([]T, aggregateChunkSize)
+	for i := 0; i < s.NumFrames(); i++ {
+		f, n, err := s.ValueFrame(i)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			continue
+		}
+
+		// Packed domain evaluation of frames holding only values of the series
+		if uint64(n) == f.Length() {
+			v, ok, err := f.AggregatePacked(agg, arg)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				res.merge(v, n)
+				continue
+			}
+		}
+
+		dec, err := f.Decoder()
+		if err != nil {
+			return 0, err
+		}
+		valid, err := f.Validity()
+		if err != nil {
+			return 0, err
+		}
+		for pos, remaining := 0, n; remaining > 0; {
+			dst := chunk
+			if remaining < len(dst) {
+				dst = dst[:remaining]
+			}
+			read, err := dec.DecodeNext(dst)
+			if err == io.EOF {
+				return 0, errors.New("frame holds fewer values than the series")
+			}
+			if err != nil {
+				return 0, err
+			}
+			for _, v := range dst[:read] {
+				if valid == nil || f.IsValid(pos) {
+					res.add(v)
+				}
+				pos++
+			}
+			remaining -= read
+		}
+	}
+
+	if res.count == 0 && (agg == packer.AggregateMin || agg == packer.AggregateMax) {
+		return 0, errors.New("no values to aggregate")
+	}
+	return res.value, nil
+}
+
+// Evaluate the counting aggregate (see packer.Aggregate.IsCount) over the
+// values of the series, arg being the argument of packer.AggregateCountEqual.
+// Counts are evaluated on the packed values of the frames when possible (see
+// frame.Frame.CountPacked), the values of the other frames being decoded in
+// chunks, and are exact whatever the type of the values. Missing values are
+// left out of the count. Returns the count along with nil error, otherwise
+// returns (0, error).
+func Count[T packer.Number](s *series.Series[T], agg packer.Aggregate, arg T) (uint64, error) {
+
+	if s == nil {
+		return 0, errors.New("invalid series for count")
+	}
+	if !agg.IsCount() {
+		return 0, errors.New("not a counting aggregate")
+	}
+
+	var count uint64 = 0
+	chunk := make([]T, aggregateChunkSize)
+	for i := 0; i < s.NumFrames(); i++ {
+		f, n, err := s.ValueFrame(i)
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			continue
+		}
+
+		// Packed domain evaluation of frames holding only values of the series
+		if uint64(n) == f.Length() {
+			c, ok, err := f.CountPacked(agg, arg)
+			if err != nil {
+				return 0, err
+			}
+			if ok {
+				count += c
+				continue
+			}
+		}
+
+		dec, err := f.Decoder()
+		if err != nil {
+			return 0, err
+		}
+		valid, err := f.Validity()
+		if err != nil {
+			return 0, err
+		}
+		for pos, remaining := 0, n; remaining > 0; {
+			dst := chunk
+			if remaining < len(dst) {
+				dst = dst[:remaining]
+			}
+			read, err := dec.DecodeNext(dst)
+			if err == io.EOF {
+				return 0, errors.New("frame holds fewer values than the series")
+			}
+			if err != nil {
+				return 0, err
+			}
+			for _, v := range dst[:read] {
+				present := valid == nil || f.IsValid(pos)
+				if present && (agg == packer.AggregateCount || v == arg) {
+					count++
+				}
+				pos++
+			}
+			remaining -= read
+		}
+	}
+
+	return count, nil
+}
+
+// -----------------
+// - PRIVATE METHODS
+// -----------------
+
+// Aggregate of the values of a series, accumulated frame by frame
+type aggregateResult[T packer.Number] struct {
+	agg   packer.Aggregate
+	value T
+	count int
+}
+
+// Merge the aggregate of n values of a frame
+func (res *aggregateResult[T]) merge(v T, n int) {
+	switch res.agg {
+	case packer.AggregateSum:
+		res.value += v
+	case packer.AggregateMin:
+		if res.count == 0 || v < res.value {
+			res.value = v
+		}
+	case packer.AggregateMax:
+		if res.count == 0 || v > res.value {
+			res.value = v
+		}
+	}
+	res.count += n
+}
+
+// Add a decoded value
+func (res *aggregateResult[T]) add(v T) {
+	res.merge(v, 1)
+}
@@ -0,0 +1,105 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/packer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate_Series(t *testing.T) {
+
+	for _, codec := range []packer.CodecID{packer.CodecRLE, packer.CodecSimple8b, packer.CodecChimp} {
+		s := series.NewSeries[int64](100, series.WithValueCodec(codec))
+
+		// Last frame is left partially filled
+		var sum, equal int64
+		for i := 0; i < 1050; i++ {
+			v := int64(i/30%5) * 10
+			assert.Nil(t, s.AppendValue(uint64(i), v))
+			sum += v
+			if v == 20 {
+				equal++
+			}
+		}
+		assert.Nil(t, s.Finalize(true))
+
+		res, err := Aggregate[int64](s, packer.AggregateCount, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1050), res)
+
+		res, err = Aggregate[int64](s, packer.AggregateSum, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, sum, res)
+
+		res, err = Aggregate[int64](s, packer.AggregateMin, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), res)
+
+		res, err = Aggregate[int64](s, packer.AggregateMax, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(40), res)
+
+		res, err = Aggregate[int64](s, packer.AggregateCountEqual, 20)
+		assert.Nil(t, err)
+		assert.Equal(t, equal, res, codec.String())
+	}
+}
+
+func TestAggregate_Appendable(t *testing.T) {
+
+	s := series.NewSeries[float64](64, series.WithAppendableFrames())
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, s.AppendValue(uint64(i), float64(i%10)))
+	}
+
+	res, err := Aggregate[float64](s, packer.AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 450.0, res)
+
+	res, err = Aggregate[float64](s, packer.AggregateCountEqual, 9)
+	assert.Nil(t, err)
+	assert.Equal(t, 10.0, res)
+}
+
+func TestAggregate_CountDecimal(t *testing.T) {
+
+	// Compact frames are counted packed, the last one is decoded
+	s := series.NewSeries[packer.Decimal](
+		100, series.WithDecimalScale(2), series.WithValueCodec(packer.CodecRLE))
+	for i := 0; i < 250; i++ {
+		assert.Nil(t, s.AppendValue(uint64(i), packer.Decimal(i%2*125)))
+	}
+	assert.Nil(t, s.Finalize(false))
+
+	n, err := Count[packer.Decimal](s, packer.AggregateCount, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(250), n)
+
+	n, err = Count[packer.Decimal](s, packer.AggregateCountEqual, 125)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(125), n)
+
+	_, err = Count[packer.Decimal](s, packer.AggregateSum, 0)
+	assert.NotNil(t, err)
+	_, err = Aggregate[packer.Decimal](s, packer.AggregateCount, 0)
+	assert.NotNil(t, err)
+
+	sum, err := Aggregate[packer.Decimal](s, packer.AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "156.25", sum.Format(s.DecimalScale()))
+}
+
+func TestAggregate_Empty(t *testing.T) {
+
+	s := series.NewSeries[float64](10)
+
+	res, err := Aggregate[float64](s, packer.AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, res)
+
+	_, err = Aggregate[float64](s, packer.AggregateMax, 0)
+	assert.NotNil(t, err)
+}
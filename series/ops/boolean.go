@@ -0,0 +1,168 @@
+package ops
+
+import (
+	"errors"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Comparison of the values of a series with a threshold (see Compare)
+type CompareOp uint8
+
+const (
+	CompareEQ CompareOp = iota
+	CompareNE
+	CompareLT
+	CompareLE
+	CompareGT
+	CompareGE
+)
+
+func (c CompareOp) String() string {
+	switch c {
+	case CompareEQ:
+		return "=="
+	case CompareNE:
+		return "!="
+	case CompareLT:
+		return "<"
+	case CompareLE:
+		return "<="
+	case CompareGT:
+		return ">"
+	case CompareGE:
+		return ">="
+	}
+	return "Invalid"
+}
+
+// ----------------------------------------------------------------------------
+// - Boolean Ops
+// ----------------------------------------------------------------------------
+
+// Return the boolean series holding the logical and of the values of the two
+// series, at the times of a. Returns the series along with nil error,
+// otherwise returns (nil, error) if the series differ in size.
+func And(a, b *series.BoolSeries) (*series.BoolSeries, error) {
+	return combineBool(a, b, (*bitmap.Bitmap).And)
+}
+
+// Return the boolean series holding the logical or of the values of the two
+// series, at the times of a. Returns the series along with nil error,
+// otherwise returns (nil, error) if the series differ in size.
+func Or(a, b *series.BoolSeries) (*series.BoolSeries, error) {
+	return combineBool(a, b, (*bitmap.Bitmap).Or)
+}
+
+// Return the boolean series holding the exclusive or of the values of the two
+// series, at the times of a. Returns the series along with nil error,
+// otherwise returns (nil, error) if the series differ in size.
+func Xor(a, b *series.BoolSeries) (*series.BoolSeries, error) {
+	return combineBool(a, b, (*bitmap.Bitmap).Xor)
+}
+
+// Return the boolean series holding the negated values of the series. Returns
+// the series along with nil error, otherwise returns (nil, error).
+func Not(a *series.BoolSeries) (*series.BoolSeries, error) {
+	if a == nil {
+		return nil, errors.New("invalid series for Not")
+	}
+	return withBits(a, a.Bits().Not())
+}
+
+// Return the boolean series holding the result of comparing every value of
+// args with the threshold, at the times of args. Missing values compare false.
+// The series is made of frames of the specified frameSize. Returns the series
+// along with nil error, otherwise returns (nil, error).
+func Compare[S packer.Number, T packer.Number](
+	args Transformable[S, T], cmp CompareOp, threshold T, frameSize int) (*series.BoolSeries, error) {
+
+	if args == nil {
+		return nil, errors.New("invalid args for Compare")
+	}
+	if cmp > CompareGE {
+		return nil, errors.New("invalid comparison")
+	}
+
+	res := series.NewBoolSeries(frameSize)
+	for idx := 0; idx < args.Length(); idx++ {
+		v := args.ValueAt(idx)
+		set := false
+		switch {
+		case !args.IsValidAt(idx):
+		case cmp == CompareEQ:
+			set = v == threshold
+		case cmp == CompareNE:
+			set = v != threshold
+		case cmp == CompareLT:
+			set = v < threshold
+		case cmp == CompareLE:
+			set = v <= threshold
+		case cmp == CompareGT:
+			set = v > threshold
+		case cmp == CompareGE:
+			set = v >= threshold
+		}
+		if err := res.AppendValue(args.TimeAt(idx), set); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Return an operator holding the values of args, along with their times,
+// whose value in the mask is true. Missing values of args are kept missing.
+// The mask must hold as many values as args.
+func Filter[S packer.Number, T packer.Number](
+	args Transformable[S, T], mask *series.BoolSeries) *MaybeOp[S, T] {
+
+	if args == nil || mask == nil || args.Length() != mask.Size() {
+		return ErrorOp[S, T](errors.New("invalid size for Filter arguments"))
+	}
+
+	bits := mask.Bits()
+	res := newResultBuilder[T](NullPropagate, int(bits.Count()))
+	bits.ForEachSet(func(index uint64) {
+		idx := int(index)
+		res.add(args.ValueAt(idx), args.TimeAt(idx), args.IsValidAt(idx))
+	})
+
+	var ret = &OpResult[S, T]{
+		values: res.result(),
+		err:    nil,
+	}
+
+	return JustOp[S, T](ret)
+}
+
+// -----------------
+// - PRIVATE METHODS
+// -----------------
+
+// Return the boolean series holding the bits of f applied on the bitmaps of
+// both series, at the times of a.
+func combineBool(a, b *series.BoolSeries,
+	f func(x, y *bitmap.Bitmap) (*bitmap.Bitmap, error)) (*series.BoolSeries, error) {
+
+	if a == nil || b == nil {
+		return nil, errors.New("invalid series for boolean op")
+	}
+
+	bits, err := f(a.Bits(), b.Bits())
+	if err != nil {
+		return nil, err
+	}
+	return withBits(a, bits)
+}
+
+// Return a boolean series holding the bits at the times of s
+func withBits(s *series.BoolSeries, bits *bitmap.Bitmap) (*series.BoolSeries, error) {
+	times, err := s.Times()
+	if err != nil {
+		return nil, err
+	}
+	return series.BoolFromBitmap(times, bits, s.FrameSize())
+}
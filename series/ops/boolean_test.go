@@ -0,0 +1,125 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolean_LogicalOps(t *testing.T) {
+
+	up := series.NewBoolSeries(4)
+	firing := series.NewBoolSeries(4)
+	upValues := []bool{true, true, false, false, true, true, true, false, true}
+	firingValues := []bool{false, true, true, false, false, false, true, true, true}
+	for i := range upValues {
+		assert.Nil(t, up.AppendValue(uint64(i), upValues[i]))
+		assert.Nil(t, firing.AppendValue(uint64(i), firingValues[i]))
+	}
+	assert.Nil(t, up.Finalize(true))
+
+	and, err := And(up, firing)
+	assert.Nil(t, err)
+	or, err := Or(up, firing)
+	assert.Nil(t, err)
+	xor, err := Xor(up, firing)
+	assert.Nil(t, err)
+	not, err := Not(up)
+	assert.Nil(t, err)
+
+	for i := range upValues {
+		time, v, err := and.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i), time)
+		assert.Equal(t, upValues[i] && firingValues[i], v)
+
+		_, v, _ = or.Value(i)
+		assert.Equal(t, upValues[i] || firingValues[i], v)
+		_, v, _ = xor.Value(i)
+		assert.Equal(t, upValues[i] != firingValues[i], v)
+		_, v, _ = not.Value(i)
+		assert.Equal(t, !upValues[i], v)
+	}
+
+	short := series.NewBoolSeries(4)
+	assert.Nil(t, short.AppendValue(0, true))
+	_, err = And(up, short)
+	assert.NotNil(t, err)
+	_, err = Not(nil)
+	assert.NotNil(t, err)
+}
+
+func TestBoolean_CompareFilter(t *testing.T) {
+
+	s := series.NewSeries[float64](10)
+	for i := 0; i < 20; i++ {
+		assert.Nil(t, s.AppendValue(uint64(i*10), float64(i%7)))
+	}
+	tx := NewTxSeries[float64](s)
+
+	mask, err := Compare[float64, float64](tx, CompareGE, 5, 16)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, mask.Size())
+	assert.Equal(t, uint64(5), mask.Bits().Count())
+
+	res := Filter[float64, float64](tx, mask)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 5, res.Values().Length())
+	expT := []uint64{50, 60, 120, 130, 190}
+	expV := []float64{5, 6, 5, 6, 5}
+	for i := range expT {
+		assert.Equal(t, expT[i], res.Values().TimeAt(i))
+		assert.Equal(t, expV[i], res.Values().ValueAt(i))
+	}
+
+	// Masks combine with logical ops before filtering
+	low, err := Compare[float64, float64](tx, CompareLT, 1, 16)
+	assert.Nil(t, err)
+	either, err := Or(mask, low)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, Filter[float64, float64](tx, either).Values().Length())
+
+	for _, cmp := range []CompareOp{CompareEQ, CompareNE, CompareLT, CompareLE, CompareGT} {
+		m, err := Compare[float64, float64](tx, cmp, 3, 16)
+		assert.Nil(t, err, cmp.String())
+		assert.Equal(t, 20, m.Size())
+	}
+	_, err = Compare[float64, float64](tx, CompareGE+1, 3, 16)
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, Filter[float64, float64](tx, series.NewBoolSeries(4)).Error())
+}
+
+// Ops on a frame view read the times of its time frame
+func TestBoolean_FrameView(t *testing.T) {
+
+	s := series.NewSeries[int64](16)
+	for i := 0; i < 40; i++ {
+		assert.Nil(t, s.AppendValue(uint64(500+i*10), int64(i%5)))
+	}
+	assert.Nil(t, s.Finalize(true))
+
+	fV, _, err := s.ValueFrame(1)
+	assert.Nil(t, err)
+	fT, _, err := s.TimeFrame(1)
+	assert.Nil(t, err)
+	view, err := fV.Slice(2, 12)
+	assert.Nil(t, err)
+	view, err = view.WithTimes(fT)
+	assert.Nil(t, err)
+
+	mask, err := Compare[int64, int64](view, CompareEQ, 4, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), mask.Bits().Count())
+	time, _, err := mask.Value(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(500+20*10), time)
+
+	res := Filter[int64, int64](view, mask)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 2, res.Values().Length())
+	assert.Equal(t, uint64(500+19*10), res.Values().TimeAt(0))
+	assert.Equal(t, uint64(500+24*10), res.Values().TimeAt(1))
+}
@@ -0,0 +1,222 @@
+package ops
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Change of the label of a categorical series
+type Transition struct {
+
+	// Time of the first value holding the new label
+	Time uint64
+
+	// Label before the transition
+	From string
+
+	// Label after the transition
+	To string
+}
+
+// ----------------------------------------------------------------------------
+// - Categorical Ops
+// ----------------------------------------------------------------------------
+
+// Return the number of values of the categorical series holding each label.
+// Codes are counted frame by frame and only resolved through the dictionary
+// of the frame once, so labels are not materialized for every value. Returns
+// the counts along with nil error, otherwise returns (nil, error).
+func ValueCounts(s *series.CategoricalSeries) (map[string]int, error) {
+	if s == nil {
+		return nil, errors.New("invalid series for value counts")
+	}
+
+	counts := make(map[string]int)
+	chunk := make([]uint32, aggregateChunkSize)
+	for i := 0; i < s.NumFrames(); i++ {
+		dict, err := s.Dictionary(i)
+		if err != nil {
+			return nil, err
+		}
+		f, n, err := s.CodeFrame(i)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			continue
+		}
+
+		dec, err := f.Decoder()
+		if err != nil {
+			return nil, err
+		}
+		codeCounts := make([]int, dict.Len())
+		err = decodeCount(dec, chunk, n, func(codes []uint32) error {
+			for _, code := range codes {
+				if int(code) >= len(codeCounts) {
+					return errors.New("code not in dictionary")
+				}
+				codeCounts[code]++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for code, count := range codeCounts {
+			if count > 0 {
+				counts[dict.Labels()[code]] += count
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// Return the transitions of the categorical series, every time the label of a
+// value differs from the label of the previous value, in time order. Returns
+// the transitions along with nil error, otherwise returns (nil, error).
+func Transitions(s *series.CategoricalSeries) ([]Transition, error) {
+	var transitions []Transition
+	var prev string
+	first := true
+	err := walkCategorical(s, func(time uint64, label string) {
+		if !first && label != prev {
+			transitions = append(transitions, Transition{Time: time, From: prev, To: label})
+		}
+		prev, first = label, false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+// Return the total time spent in each label of the categorical series. A
+// value holds its label from its time until the time of the next value, so
+// the last value of the series does not contribute. Returns the durations
+// along with nil error, otherwise returns (nil, error).
+func DurationInState(s *series.CategoricalSeries) (map[string]uint64, error) {
+	durations := make(map[string]uint64)
+	var prevTime uint64
+	var prev string
+	first := true
+	err := walkCategorical(s, func(time uint64, label string) {
+		if !first {
+			durations[prev] += time - prevTime
+		}
+		prevTime, prev, first = time, label, false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return durations, nil
+}
+
+// -----------------
+// - PRIVATE METHODS
+// -----------------
+
+// Visit the times and the labels of the categorical series in order, the
+// frames being decoded in chunks.
+func walkCategorical(
+	s *series.CategoricalSeries, visit func(time uint64, label string)) error {
+
+	if s == nil {
+		return errors.New("invalid categorical series")
+	}
+
+	times := make([]uint64, aggregateChunkSize)
+	codes := make([]uint32, aggregateChunkSize)
+	for i := 0; i < s.NumFrames(); i++ {
+		dict, err := s.Dictionary(i)
+		if err != nil {
+			return err
+		}
+		fT, n, err := s.TimeFrame(i)
+		if err != nil {
+			return err
+		}
+		fC, _, err := s.CodeFrame(i)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+
+		decT, err := fT.Decoder()
+		if err != nil {
+			return err
+		}
+		decC, err := fC.Decoder()
+		if err != nil {
+			return err
+		}
+
+		err = decodeCount(decC, codes, n, func(chunk []uint32) error {
+			if _, err := decodeFull(decT, times[:len(chunk)]); err != nil {
+				return err
+			}
+			for ndx, code := range chunk {
+				label, err := dict.Label(code)
+				if err != nil {
+					return err
+				}
+				visit(times[ndx], label)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode the first n values of the decoder in chunks of at most len(chunk)
+// values, passing every decoded chunk to visit.
+func decodeCount[T packer.Number](
+	dec packer.Decoder[T], chunk []T, n int, visit func(values []T) error) error {
+
+	for remaining := n; remaining > 0; {
+		dst := chunk
+		if remaining < len(dst) {
+			dst = dst[:remaining]
+		}
+		read, err := decodeFull(dec, dst)
+		if err != nil {
+			return err
+		}
+		if err := visit(dst[:read]); err != nil {
+			return err
+		}
+		remaining -= read
+	}
+	return nil
+}
+
+// Fill dst with the next values of the decoder. Returns the number of values
+// decoded along with nil error, otherwise returns (0, error) if the decoder
+// holds fewer values.
+func decodeFull[T packer.Number](dec packer.Decoder[T], dst []T) (int, error) {
+	filled := 0
+	for filled < len(dst) {
+		read, err := dec.DecodeNext(dst[filled:])
+		if err == io.EOF {
+			return 0, errors.New("frame holds fewer values than the series")
+		}
+		if err != nil {
+			return 0, err
+		}
+		filled += read
+	}
+	return filled, nil
+}
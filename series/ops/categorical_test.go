@@ -0,0 +1,42 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorical_Ops(t *testing.T) {
+
+	// Last frame is left partially filled
+	s := series.NewCategoricalSeries(100)
+	states := []string{"idle", "running", "running", "failed", "idle"}
+	for i := 0; i < 1050; i++ {
+		assert.Nil(t, s.AppendValue(uint64(i*60), states[i/70%len(states)]))
+	}
+	assert.Nil(t, s.Finalize(true))
+
+	counts, err := ValueCounts(s)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"idle": 420, "running": 420, "failed": 210}, counts)
+
+	transitions, err := Transitions(s)
+	assert.Nil(t, err)
+	assert.Equal(t, 9, len(transitions))
+	assert.Equal(t, Transition{Time: 70 * 60, From: "idle", To: "running"}, transitions[0])
+	assert.Equal(t, Transition{Time: 210 * 60, From: "running", To: "failed"}, transitions[1])
+	assert.Equal(t, Transition{Time: 280 * 60, From: "failed", To: "idle"}, transitions[2])
+
+	durations, err := DurationInState(s)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(419*60), durations["idle"])
+	assert.Equal(t, uint64(420*60), durations["running"])
+	assert.Equal(t, uint64(210*60), durations["failed"])
+
+	_, err = ValueCounts(nil)
+	assert.NotNil(t, err)
+	_, err = Transitions(nil)
+	assert.NotNil(t, err)
+}
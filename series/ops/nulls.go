@@ -0,0 +1,160 @@
+package ops
+
+import (
+	"errors"
+
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Identifies how an op handles the missing values of its operands (see
+// series.Series.AppendNull). The policy of an op is set with
+// MaybeOp.WithNullPolicy.
+type NullPolicy uint8
+
+const (
+	// A result computed from a missing value is missing. This is the default
+	// policy of every op.
+	NullPropagate NullPolicy = iota
+
+	// Missing values are left out. Element-wise ops leave out the results of
+	// missing operands and windowed ops are computed over the present values
+	// of every window, leaving out windows without any.
+	NullSkip
+
+	// Missing values are replaced by the fill value of the op
+	NullFill
+)
+
+func (p NullPolicy) String() string {
+	switch p {
+	case NullPropagate:
+		return "Propagate"
+	case NullSkip:
+		return "Skip"
+	case NullFill:
+		return "Fill"
+	}
+	return "Invalid"
+}
+
+// ----------------------------------------------------------------------------
+// - Null Policies
+// ----------------------------------------------------------------------------
+
+// Set the policy of the operation for missing values, fill being the value of
+// missing operands under NullFill. Returns the operation, otherwise returns an
+// error operation if the operation does not handle missing values.
+func (op *MaybeOp[S, T]) WithNullPolicy(policy NullPolicy, fill T) *MaybeOp[S, T] {
+
+	if op.Error() != nil {
+		return op
+	}
+	if policy > NullFill {
+		return ErrorOp[S, T](errors.New("invalid null policy"))
+	}
+
+	setter, ok := op.op.(nullPolicySetter[T])
+	if !ok {
+		return ErrorOp[S, T](errors.New("operation does not handle missing values"))
+	}
+	setter.setNullPolicy(policy, fill)
+
+	return op
+}
+
+// -----------------
+// - PRIVATE METHODS
+// -----------------
+
+// Implemented by the operations handling missing values
+type nullPolicySetter[T packer.Number] interface {
+	setNullPolicy(policy NullPolicy, fill T)
+}
+
+// Policy of an operation for missing values, embedded by the operations
+type nullPolicy[T packer.Number] struct {
+	policy NullPolicy
+	fill   T
+}
+
+func (np *nullPolicy[T]) setNullPolicy(policy NullPolicy, fill T) {
+	np.policy = policy
+	np.fill = fill
+}
+
+// Return the value of args at the specified index and true if it is present,
+// the fill value and true if it is missing under NullFill, otherwise (0,
+// false).
+func presentValue[S packer.Number, T packer.Number](
+	np *nullPolicy[T], args Transformable[S, T], idx int) (T, bool) {
+
+	if args.IsValidAt(idx) {
+		return args.ValueAt(idx), true
+	}
+	if np.policy == NullFill {
+		return np.fill, true
+	}
+	return 0, false
+}
+
+// Return the present values of the window of args starting at the specified
+// index, appended to dst, and false if a value of the window is missing under
+// NullPropagate, the result of the window being missing.
+func windowValues[S packer.Number, T packer.Number](
+	np *nullPolicy[T], args Transformable[S, T], idx int, size int, dst []T) ([]T, bool) {
+
+	for jdx := idx; jdx < idx+size; jdx++ {
+		v, ok := presentValue(np, args, jdx)
+		if ok {
+			dst = append(dst, v)
+		} else if np.policy == NullPropagate {
+			return dst, false
+		}
+	}
+	return dst, true
+}
+
+// Accumulates the results of an operation, the missing results being marked
+// or left out depending on the policy.
+type resultBuilder[T packer.Number] struct {
+	policy NullPolicy
+	values []T
+	times  []uint64
+	valid  *bitmap.Bitmap
+}
+
+func newResultBuilder[T packer.Number](policy NullPolicy, capacity int) *resultBuilder[T] {
+	return &resultBuilder[T]{
+		policy: policy,
+		values: make([]T, 0, capacity),
+		times:  make([]uint64, 0, capacity),
+		valid:  nil,
+	}
+}
+
+// Add the result at the specified time, present being false if the result is
+// missing.
+func (res *resultBuilder[T]) add(v T, t uint64, present bool) {
+	if !present {
+		if res.policy == NullSkip {
+			return
+		}
+		if res.valid == nil {
+			res.valid = bitmap.New()
+			res.valid.AppendRun(true, uint64(len(res.values)))
+		}
+		v = 0
+	}
+
+	res.values = append(res.values, v)
+	res.times = append(res.times, t)
+	if res.valid != nil {
+		res.valid.Append(present)
+	}
+}
+
+// Return the results as an operator
+func (res *resultBuilder[T]) result() *TxIdentity[T, T] {
+	return NewTxIdentityWithNulls[T](res.values, res.times, res.valid)
+}
@@ -0,0 +1,159 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/rmravindran/ats/series/packer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNulls_ElementWise(t *testing.T) {
+
+	times := []uint64{10, 20, 30, 40, 50}
+	a := NewTxIdentityWithNulls[float64]([]float64{1, 0, 3, 4, 5}, times,
+		bitmap.FromBools([]bool{true, false, true, true, true}))
+	b := NewTxIdentityWithNulls[float64]([]float64{10, 20, 30, 0, 50}, times,
+		bitmap.FromBools([]bool{true, true, true, false, true}))
+
+	// Propagate
+	res := NewOpAdd[float64, float64]().Apply(a).Apply(b)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 5, res.Values().Length())
+	valid := []bool{true, false, true, false, true}
+	for i := range valid {
+		assert.Equal(t, valid[i], res.Values().IsValidAt(i), i)
+	}
+	assert.Equal(t, 33.0, res.Values().ValueAt(2))
+
+	// Skip
+	res = NewOpAdd[float64, float64]().WithNullPolicy(NullSkip, 0).Apply(a).Apply(b)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 3, res.Values().Length())
+	exp := []float64{11, 33, 55}
+	expT := []uint64{10, 30, 50}
+	for i := range exp {
+		assert.Equal(t, exp[i], res.Values().ValueAt(i))
+		assert.Equal(t, expT[i], res.Values().TimeAt(i))
+		assert.True(t, res.Values().IsValidAt(i))
+	}
+
+	// Fill
+	res = NewOpMul[float64, float64]().WithNullPolicy(NullFill, 1).Apply(a).Apply(b)
+	assert.Nil(t, res.Error())
+	exp = []float64{10, 20, 90, 4, 250}
+	for i := range exp {
+		assert.Equal(t, exp[i], res.Values().ValueAt(i))
+		assert.True(t, res.Values().IsValidAt(i))
+	}
+}
+
+func TestNulls_Windowed(t *testing.T) {
+
+	s := series.NewSeries[int64](4)
+	for i := 0; i < 8; i++ {
+		if i == 1 || i == 4 || i == 5 || i == 6 || i == 7 {
+			assert.Nil(t, s.AppendNull(uint64(i)))
+		} else {
+			assert.Nil(t, s.AppendValue(uint64(i), int64(i)))
+		}
+	}
+	tx := NewTxSeries[int64](s)
+
+	res := NewOpSum[int64](0, 4).Apply(tx)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 2, res.Values().Length())
+	assert.False(t, res.Values().IsValidAt(0))
+	assert.False(t, res.Values().IsValidAt(1))
+
+	// Windows without present values are left out
+	res = NewOpSum[int64](0, 4).WithNullPolicy(NullSkip, 0).Apply(tx)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 1, res.Values().Length())
+	assert.Equal(t, int64(0+2+3), res.Values().ValueAt(0))
+
+	res = NewOpMin[int64](4).WithNullPolicy(NullFill, -1).Apply(tx)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 2, res.Values().Length())
+	assert.Equal(t, int64(-1), res.Values().ValueAt(0))
+	assert.Equal(t, int64(-1), res.Values().ValueAt(1))
+
+	res = NewOpMax[int64](2).WithNullPolicy(NullSkip, 0).Apply(tx)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 2, res.Values().Length())
+	assert.Equal(t, int64(0), res.Values().ValueAt(0))
+	assert.Equal(t, int64(3), res.Values().ValueAt(1))
+	assert.Equal(t, uint64(2), res.Values().TimeAt(1))
+
+	res = NewOpPct[int64](4, 50).WithNullPolicy(NullSkip, 0).Apply(tx)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 1, res.Values().Length())
+}
+
+func TestNulls_Policy(t *testing.T) {
+
+	res := NewOpAdd[float64, float64]().WithNullPolicy(NullFill+1, 0)
+	assert.NotNil(t, res.Error())
+
+	// Results do not handle missing values
+	a := NewTxIdentity[float64]([]float64{1, 2})
+	res = NewOpAdd[float64, float64]().Apply(a).Apply(a).WithNullPolicy(NullSkip, 0)
+	assert.NotNil(t, res.Error())
+
+	// Errors are kept
+	dec := NewOpDecimalSum(0, 0).WithNullPolicy(NullSkip, 0)
+	assert.NotNil(t, dec.Error())
+
+	assert.Equal(t, "Skip", NullSkip.String())
+}
+
+func TestNulls_CompareFilterAggregate(t *testing.T) {
+
+	s := series.NewSeries[int64](8)
+	for i := 0; i < 20; i++ {
+		if i%4 == 0 {
+			assert.Nil(t, s.AppendNull(uint64(i)))
+		} else {
+			assert.Nil(t, s.AppendValue(uint64(i), int64(i)))
+		}
+	}
+
+	// Missing values compare false and are kept missing by Filter
+	tx := NewTxSeries[int64](s)
+	mask, err := Compare[int64, int64](tx, CompareLE, 5, 8)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), mask.Bits().Count())
+
+	all, err := Compare[int64, int64](tx, CompareGE, -1, 8)
+	assert.Nil(t, err)
+	res := Filter[int64, int64](tx, all)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 15, res.Values().Length())
+
+	above, err := Not(mask)
+	assert.Nil(t, err)
+	res = Filter[int64, int64](tx, above)
+	assert.Nil(t, res.Error())
+	assert.False(t, res.Values().IsValidAt(0))
+	assert.Equal(t, uint64(0), res.Values().TimeAt(0))
+
+	// Aggregates leave out the missing values, packed or not
+	for _, reduce := range []bool{false, true} {
+		if reduce {
+			assert.Nil(t, s.Finalize(true))
+		}
+		count, err := Aggregate[int64](s, packer.AggregateCount, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(15), count)
+
+		min, err := Aggregate[int64](s, packer.AggregateMin, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), min)
+
+		zeros, err := Aggregate[int64](s, packer.AggregateCountEqual, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), zeros)
+	}
+}
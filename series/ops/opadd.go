@@ -14,12 +14,14 @@ import (
 // invokation takes one time series as an input and return an instance of OpAdd1
 // which can be curried further with another time series to generate final sum.
 type OpAdd[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 }
 
 // Represents an add operation that can be applied on two time series. OpAdd1
 // is an internal representation of OpAdd which contains the first time series
 // and can take the second operand to generate the final sum.
 type OpAdd1[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	a Transformable[S, T]
 }
 
@@ -44,7 +46,7 @@ func (op *OpAdd[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("OpAdd on nil/empty array"))
 	}
 
-	return JustOp[S, T](&OpAdd1[S, T]{a: args})
+	return JustOp[S, T](&OpAdd1[S, T]{nullPolicy: op.nullPolicy, a: args})
 }
 
 // Returns a nil TxIdentity. Add operation require two time series. OpAdd
@@ -67,14 +69,16 @@ func (op1 *OpAdd1[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("invalid size for OpAdd arguments"))
 	}
 
-	result := make([]T, args.Length())
+	res := newResultBuilder[T](op1.policy, args.Length())
 
 	for idx := 0; idx < op1.a.Length(); idx++ {
-		result[idx] = op1.a.ValueAt(idx) + args.ValueAt(idx)
+		a, okA := presentValue(&op1.nullPolicy, op1.a, idx)
+		b, okB := presentValue(&op1.nullPolicy, args, idx)
+		res.add(a+b, op1.a.TimeAt(idx), okA && okB)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentity[T](result),
+		values: res.result(),
 		err:    nil,
 	}
 
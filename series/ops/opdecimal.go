@@ -0,0 +1,208 @@
+package ops
+
+import (
+	"errors"
+
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// ----------------------------------------------------------------------------
+// - OpDecimalMul Struct
+// ----------------------------------------------------------------------------
+
+// Represents an exact multiply operation that can be applied on two decimal
+// time series of the same scale. Products are computed on the mantissas and
+// rounded half away from zero to the scale of the operands (see
+// packer.Decimal.Mul). OpDecimalMul invokation takes one time series as an
+// input and return an instance of OpDecimalMul1 which can be curried further
+// with another time series to generate final product.
+type OpDecimalMul[S packer.Number] struct {
+	nullPolicy[packer.Decimal]
+	scale uint8
+}
+
+// Represents an exact multiply operation that can be applied on two decimal
+// time series. OpDecimalMul1 is an internal representation of OpDecimalMul
+// which contains the first time series and can take the second operand to
+// generate the final product.
+type OpDecimalMul1[S packer.Number] struct {
+	nullPolicy[packer.Decimal]
+	scale uint8
+	a     Transformable[S, packer.Decimal]
+}
+
+// ----------------------------------------------------------------------------
+// - OpDecimalSum Struct
+// ----------------------------------------------------------------------------
+
+// Represents an operation that can be applied on a decimal time series to
+// generate windowed sum of the values. Sums are exact and fail on overflow of
+// the mantissa instead of wrapping around.
+type OpDecimalSum[S packer.Number] struct {
+	nullPolicy[packer.Decimal]
+	initialValue packer.Decimal
+	windowSize   int
+}
+
+// --------------
+// - CONSTRUCTORS
+// --------------
+
+// Create a new OpDecimalMul operator on decimals of the specified scale
+func NewOpDecimalMul(scale uint8) *MaybeOp[packer.Decimal, packer.Decimal] {
+	if scale > packer.MaxDecimalScale {
+		return ErrorOp[packer.Decimal, packer.Decimal](
+			errors.New("unsupported decimal scale"))
+	}
+	return JustOp[packer.Decimal, packer.Decimal](
+		&OpDecimalMul[packer.Decimal]{scale: scale})
+}
+
+// Create a new OpDecimalSum operator with the specified initial value and
+// windowSize.
+func NewOpDecimalSum(
+	initialValue packer.Decimal, windowSize int) *MaybeOp[packer.Decimal, packer.Decimal] {
+
+	if windowSize <= 0 {
+		return ErrorOp[packer.Decimal, packer.Decimal](
+			errors.New("invalid window size for OpDecimalSum"))
+	}
+	return JustOp[packer.Decimal, packer.Decimal](&OpDecimalSum[packer.Decimal]{
+		initialValue: initialValue,
+		windowSize:   windowSize})
+}
+
+// ----------------
+// - PUBLIC METHODS
+// ----------------
+
+// Apply the OpDecimalMul operator on the specified args and return an operator
+// that can be curreried further.
+func (op *OpDecimalMul[S]) Apply(
+	args Transformable[S, packer.Decimal]) *MaybeOp[S, packer.Decimal] {
+
+	if args == nil || args.IsEmpty() {
+		return ErrorOp[S, packer.Decimal](errors.New("OpDecimalMul on nil/empty array"))
+	}
+
+	return JustOp[S, packer.Decimal](&OpDecimalMul1[S]{
+		nullPolicy: op.nullPolicy, scale: op.scale, a: args})
+}
+
+// Returns a nil TxIdentity. OpDecimalMul Apply() results in an operator which
+// needs to be further applied on another time series before producing results.
+func (op *OpDecimalMul[S]) Values() *TxIdentity[packer.Decimal, packer.Decimal] {
+	return &TxIdentity[packer.Decimal, packer.Decimal]{values: nil}
+}
+
+// Returns nil error.
+func (op *OpDecimalMul[S]) Error() error {
+	return nil
+}
+
+// Apply the OpDecimalMul1 operator on the specified args and returns the final
+// result (product of the two series) as an operator. Returns an error operator
+// if a product overflows the mantissa.
+func (op1 *OpDecimalMul1[S]) Apply(
+	args Transformable[S, packer.Decimal]) *MaybeOp[S, packer.Decimal] {
+
+	if args == nil || op1.a.Length() != args.Length() {
+		return ErrorOp[S, packer.Decimal](
+			errors.New("invalid size for OpDecimalMul arguments"))
+	}
+
+	res := newResultBuilder[packer.Decimal](op1.policy, args.Length())
+
+	for idx := 0; idx < op1.a.Length(); idx++ {
+		a, okA := presentValue(&op1.nullPolicy, op1.a, idx)
+		b, okB := presentValue(&op1.nullPolicy, args, idx)
+		if !okA || !okB {
+			res.add(0, op1.a.TimeAt(idx), false)
+			continue
+		}
+		v, err := a.Mul(b, op1.scale)
+		if err != nil {
+			return ErrorOp[S, packer.Decimal](err)
+		}
+		res.add(v, op1.a.TimeAt(idx), true)
+	}
+
+	var ret = &OpResult[S, packer.Decimal]{
+		values: res.result(),
+		err:    nil,
+	}
+
+	return JustOp[S, packer.Decimal](ret)
+}
+
+// Returns a nil TxIdentity. OpDecimalMul1 is a curried operator which needs to
+// be further applied on another time series before producing results.
+func (op *OpDecimalMul1[S]) Values() *TxIdentity[packer.Decimal, packer.Decimal] {
+	return &TxIdentity[packer.Decimal, packer.Decimal]{values: nil}
+}
+
+// Returns nil error.
+func (op *OpDecimalMul1[S]) Error() error {
+	return nil
+}
+
+// Apply the OpDecimalSum operator on the specified args and return an operator
+// that contains the sum of the values over the specified windowSize. Returns an
+// error operator if a sum overflows the mantissa.
+func (op *OpDecimalSum[S]) Apply(
+	args Transformable[S, packer.Decimal]) *MaybeOp[S, packer.Decimal] {
+
+	if args == nil || args.IsEmpty() {
+		return ErrorOp[S, packer.Decimal](
+			errors.New("invalid size for OpDecimalSum arguments"))
+	}
+
+	resultSize := args.Length() / op.windowSize
+
+	// If not enough values to compute the sum, return nil
+	if resultSize == 0 {
+		return ErrorOp[S, packer.Decimal](errors.New("not enough values to compute sum"))
+	}
+
+	res := newResultBuilder[packer.Decimal](op.policy, resultSize)
+	window := make([]packer.Decimal, 0, op.windowSize)
+
+	for resNdx := 0; resNdx < resultSize; resNdx++ {
+		idx := resNdx * op.windowSize
+		values, ok := windowValues(&op.nullPolicy, args, idx, op.windowSize, window[:0])
+		if !ok || len(values) == 0 {
+			res.add(0, args.TimeAt(idx), false)
+			continue
+		}
+
+		sumV := packer.Decimal(0)
+		if idx == 0 {
+			sumV = op.initialValue
+		}
+		for _, v := range values {
+			var err error
+			if sumV, err = sumV.Add(v); err != nil {
+				return ErrorOp[S, packer.Decimal](err)
+			}
+		}
+		res.add(sumV, args.TimeAt(idx), true)
+	}
+
+	var ret = &OpResult[S, packer.Decimal]{
+		values: res.result(),
+		err:    nil,
+	}
+
+	return JustOp[S, packer.Decimal](ret)
+}
+
+// Returns a nil TxIdentity. Sum operation is the result of the Apply function.
+// The result is returned as an operator from the Apply invocation.
+func (op *OpDecimalSum[S]) Values() *TxIdentity[packer.Decimal, packer.Decimal] {
+	return &TxIdentity[packer.Decimal, packer.Decimal]{values: nil}
+}
+
+// Returns a nil error.
+func (op *OpDecimalSum[S]) Error() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package ops
+
+import (
+	"testing"
+
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/packer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpDecimalMul_Multiply(t *testing.T) {
+
+	// 1.10 * 1.10 = 1.21, 0.10 * 0.20 = 0.02 and 1.25 * 1.25 = 1.5625 rounded
+	// to 1.56
+	a := []packer.Decimal{110, 10, 125}
+	b := []packer.Decimal{110, 20, 125}
+
+	res := NewOpDecimalMul(2).Apply(NewTxIdentity(a)).Apply(NewTxIdentity(b))
+	assert.Nil(t, res.Error())
+
+	exp := []packer.Decimal{121, 2, 156}
+	for i := range exp {
+		assert.Equal(t, exp[i], res.Values().ValueAt(i))
+	}
+
+	res = NewOpDecimalMul(0).
+		Apply(NewTxIdentity([]packer.Decimal{1 << 62})).
+		Apply(NewTxIdentity([]packer.Decimal{4}))
+	assert.ErrorIs(t, res.Error(), packer.ErrDecimalOverflow)
+
+	res = NewOpDecimalMul(2).Apply(NewTxIdentity(a)).Apply(NewTxIdentity(b[:1]))
+	assert.NotNil(t, res.Error())
+	assert.NotNil(t, NewOpDecimalMul(packer.MaxDecimalScale+1).Error())
+}
+
+func TestOpDecimalSum_Series(t *testing.T) {
+
+	s := series.NewSeries[packer.Decimal](10, series.WithDecimalScale(1))
+
+	// Ten values of 0.1 sum to exactly 1.0
+	for i := 0; i < 10; i++ {
+		err := s.AppendValue(uint64(i), 1)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, s.Finalize(true))
+
+	res := NewOpDecimalSum(0, 10).Apply(NewTxSeries[packer.Decimal](s))
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 1, res.Values().Length())
+	assert.Equal(t, "1.0", res.Values().ValueAt(0).Format(s.DecimalScale()))
+
+	res = NewOpDecimalSum(5, 2).Apply(NewTxSeries[packer.Decimal](s))
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 5, res.Values().Length())
+	assert.Equal(t, packer.Decimal(7), res.Values().ValueAt(0))
+	assert.Equal(t, packer.Decimal(2), res.Values().ValueAt(4))
+	assert.Equal(t, uint64(8), res.Values().TimeAt(4))
+
+	res = NewOpDecimalSum(packer.Decimal(1<<63-1), 2).Apply(NewTxSeries[packer.Decimal](s))
+	assert.ErrorIs(t, res.Error(), packer.ErrDecimalOverflow)
+	assert.NotNil(t, NewOpDecimalSum(0, 0).Error())
+}
@@ -13,6 +13,7 @@ import (
 // Return an operation that return the minimum value of a time series over
 // a specified window size.
 type OpMin[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	windowSize int
 }
 
@@ -45,25 +46,28 @@ func (op *OpMin[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("not enough values to compute sum"))
 	}
 
-	resV := make([]T, resultSize)
-	resT := make([]uint64, resultSize)
+	res := newResultBuilder[T](op.policy, resultSize)
+	window := make([]T, 0, op.windowSize)
 
-	resNdx := 0
-	for idx := 0; idx < args.Length(); idx, resNdx = idx+op.windowSize, resNdx+1 {
-		minV := args.ValueAt(idx)
-		tStart := args.TimeAt(idx)
-		for jdx := 1; jdx < op.windowSize; jdx++ {
-			tmp := args.ValueAt(idx + jdx)
+	for resNdx := 0; resNdx < resultSize; resNdx++ {
+		idx := resNdx * op.windowSize
+		values, ok := windowValues(&op.nullPolicy, args, idx, op.windowSize, window[:0])
+		if !ok || len(values) == 0 {
+			res.add(0, args.TimeAt(idx), false)
+			continue
+		}
+
+		minV := values[0]
+		for _, tmp := range values[1:] {
 			if tmp < minV {
 				minV = tmp
 			}
 		}
-		resV[resNdx] = T(minV)
-		resT[resNdx] = tStart
+		res.add(minV, args.TimeAt(idx), true)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentityWithTime[T](resV, resT),
+		values: res.result(),
 		err:    nil,
 	}
 
@@ -15,12 +15,14 @@ import (
 // OpMul1 which can be curried further with another time series to generate
 // final product.
 type OpMul[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 }
 
 // Represents a multiply operation that can be applied on two time series.
 // OpMul1 is an internal representation of OpAdd which contains the first time
 // series and can take the second operand to generate the final product.
 type OpMul1[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	a Transformable[S, T]
 }
 
@@ -45,7 +47,7 @@ func (op *OpMul[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("OpMul on nil/empty array"))
 	}
 
-	return JustOp[S, T](&OpMul1[S, T]{a: args})
+	return JustOp[S, T](&OpMul1[S, T]{nullPolicy: op.nullPolicy, a: args})
 }
 
 // Returns a nil TxIdentity. Mul operation require two time series. OpMula
@@ -68,14 +70,16 @@ func (op1 *OpMul1[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("invalid size for OpAdd arguments"))
 	}
 
-	result := make([]T, args.Length())
+	res := newResultBuilder[T](op1.policy, args.Length())
 
 	for idx := 0; idx < op1.a.Length(); idx++ {
-		result[idx] = op1.a.ValueAt(idx) * args.ValueAt(idx)
+		a, okA := presentValue(&op1.nullPolicy, op1.a, idx)
+		b, okB := presentValue(&op1.nullPolicy, args, idx)
+		res.add(a*b, op1.a.TimeAt(idx), okA && okB)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentity[T](result),
+		values: res.result(),
 		err:    nil,
 	}
 
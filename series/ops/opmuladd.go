@@ -7,10 +7,12 @@ import (
 )
 
 type OpMulAdd[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	c T
 }
 
 type OpMulAdd1[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	c T
 	a Transformable[S, T]
 }
@@ -34,7 +36,7 @@ func (op *OpMulAdd[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("OpAdd on nil/empty array"))
 	}
 
-	return JustOp[S, T](&OpMulAdd1[S, T]{c: op.c, a: args})
+	return JustOp[S, T](&OpMulAdd1[S, T]{nullPolicy: op.nullPolicy, c: op.c, a: args})
 }
 
 func (op *OpMulAdd[S, T]) Values() *TxIdentity[T, T] {
@@ -51,14 +53,16 @@ func (op1 *OpMulAdd1[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("invalid size for OpAdd arguments"))
 	}
 
-	result := make([]T, args.Length())
+	res := newResultBuilder[T](op1.policy, args.Length())
 
 	for idx := 0; idx < op1.a.Length(); idx++ {
-		result[idx] = (op1.c * op1.a.ValueAt(idx)) + args.ValueAt(idx)
+		a, okA := presentValue(&op1.nullPolicy, op1.a, idx)
+		b, okB := presentValue(&op1.nullPolicy, args, idx)
+		res.add((op1.c*a)+b, op1.a.TimeAt(idx), okA && okB)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentity[T](result),
+		values: res.result(),
 		err:    nil,
 	}
 
@@ -13,6 +13,7 @@ import (
 // Represents a percentile operation that can be applied on a transformable
 // array. The percentile is computed over a window of values.
 type OpPct[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	windowSize int
 	pct        float64
 }
@@ -48,26 +49,26 @@ func (op *OpPct[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("not enough values to compute percentile"))
 	}
 
-	// Copy the values from args into a slice of float64. This is needed because
-	// the quickselect algorithm works on random access arrays and transformable
-	// does not impose a random access accessor on the values.
-	values := make([]T, args.Length())
-	for idx := 0; idx < args.Length(); idx++ {
-		values[idx] = args.ValueAt(idx)
-	}
+	// Copy the present values of every window from args into a slice. This is
+	// needed because the quickselect algorithm works on random access arrays
+	// and transformable does not impose a random access accessor on the
+	// values.
+	window := make([]T, 0, op.windowSize)
 
 	// Compute the percentile for every windowSize elements in the values.
-	resV := make([]T, resultSize)
-	resT := make([]uint64, resultSize)
-	resNdx := 0
-	for idx := 0; idx < args.Length(); idx, resNdx = idx+op.windowSize, resNdx+1 {
-		pct := calculatePercentile(values[idx:idx+op.windowSize], op.pct)
-		resV[resNdx] = T(pct)
-		resT[resNdx] = args.TimeAt(idx)
+	res := newResultBuilder[T](op.policy, resultSize)
+	for resNdx := 0; resNdx < resultSize; resNdx++ {
+		idx := resNdx * op.windowSize
+		values, ok := windowValues(&op.nullPolicy, args, idx, op.windowSize, window[:0])
+		if !ok || len(values) == 0 {
+			res.add(0, args.TimeAt(idx), false)
+			continue
+		}
+		res.add(calculatePercentile(values, op.pct), args.TimeAt(idx), true)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentityWithTime[T](resV, resT),
+		values: res.result(),
 		err:    nil,
 	}
 
@@ -13,6 +13,7 @@ import (
 // Represents an operation that can be applied on a time series to generate
 // windowed sum of the values.
 type OpSum[S packer.Number, T packer.Number] struct {
+	nullPolicy[T]
 	initialValue T
 	windowSize   int
 }
@@ -48,26 +49,29 @@ func (op *OpSum[S, T]) Apply(args Transformable[S, T]) *MaybeOp[S, T] {
 		return ErrorOp[S, T](errors.New("not enough values to compute sum"))
 	}
 
-	resV := make([]T, resultSize)
-	resT := make([]uint64, resultSize)
+	res := newResultBuilder[T](op.policy, resultSize)
+	window := make([]T, 0, op.windowSize)
 
-	resNdx := 0
-	for idx := 0; idx < args.Length(); idx, resNdx = idx+op.windowSize, resNdx+1 {
-		sumV := args.ValueAt(idx)
-		if idx == 0 {
-			sumV += op.initialValue
+	for resNdx := 0; resNdx < resultSize; resNdx++ {
+		idx := resNdx * op.windowSize
+		values, ok := windowValues(&op.nullPolicy, args, idx, op.windowSize, window[:0])
+		if !ok || len(values) == 0 {
+			res.add(0, args.TimeAt(idx), false)
+			continue
 		}
-		tStart := args.TimeAt(idx)
 
-		for jdx := 1; jdx < op.windowSize; jdx++ {
-			sumV += args.ValueAt(idx + jdx)
+		var sumV T
+		if idx == 0 {
+			sumV = op.initialValue
+		}
+		for _, v := range values {
+			sumV += v
 		}
-		resV[resNdx] = T(sumV)
-		resT[resNdx] = tStart
+		res.add(sumV, args.TimeAt(idx), true)
 	}
 
 	var ret = &OpResult[S, T]{
-		values: NewTxIdentityWithTime[T](resV, resT),
+		values: res.result(),
 		err:    nil,
 	}
 
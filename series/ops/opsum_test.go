@@ -51,3 +51,28 @@ func TestOpSum_Series(t *testing.T) {
 		assert.Equal(t, uint64(i*2), res.Values().TimeAt(i))
 	}
 }
+
+func TestOpSum_Range(t *testing.T) {
+
+	budget := frame.NewBudget(1 << 20)
+	s := series.NewSeries[float64](100, series.WithMemoryBudget(budget))
+	for i := 0; i < 10000; i++ {
+		err := s.AppendValue(uint64(i), float64(i%10))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, s.Finalize(true))
+
+	view, err := s.Range(5050, 5250)
+	assert.Nil(t, err)
+
+	res := NewOpSum[float64](0, 10).Apply(view)
+	assert.Nil(t, res.Error())
+	assert.Equal(t, 20, res.Values().Length())
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, 45.0, res.Values().ValueAt(i))
+		assert.Equal(t, uint64(5050+i*10), res.Values().TimeAt(i))
+	}
+
+	// Only the frames holding the range were unpacked
+	assert.LessOrEqual(t, budget.Metrics().Frames, 2*3)
+}
@@ -1,6 +1,8 @@
 package ops
 
 import (
+	"github.com/rmravindran/ats/series"
+	"github.com/rmravindran/ats/series/frame"
 	"github.com/rmravindran/ats/series/packer"
 )
 
@@ -19,4 +21,14 @@ type Transformable[S packer.Number, T packer.Number] interface {
 
 	// Return the length of the underlying transformable array.
 	Length() int
+
+	// Return true if the value at the specified index is present, false if it
+	// is missing (see NullPolicy).
+	IsValidAt(idx int) bool
 }
+
+// Views of frames and series are transformable without being adapted
+var (
+	_ Transformable[float64, float64] = (*frame.FrameView[float64])(nil)
+	_ Transformable[int64, int64]     = (*series.SeriesView[int64])(nil)
+)
@@ -37,3 +37,7 @@ func (tx *TxConst[S, T]) IsEmpty() bool {
 func (tx *TxConst[S, T]) Length() int {
 	return tx.size
 }
+
+func (tx *TxConst[S, T]) IsValidAt(idx int) bool {
+	return true
+}
@@ -1,6 +1,7 @@
 package ops
 
 import (
+	"github.com/rmravindran/ats/series/bitmap"
 	"github.com/rmravindran/ats/series/packer"
 )
 
@@ -13,6 +14,7 @@ import (
 type TxIdentity[S packer.Number, T packer.Number] struct {
 	values []T
 	time   []uint64
+	valid  *bitmap.Bitmap
 }
 
 // --------------
@@ -21,12 +23,21 @@ type TxIdentity[S packer.Number, T packer.Number] struct {
 
 // Create a new TxIdentity struct with the specified values.
 func NewTxIdentity[T packer.Number](values []T) *TxIdentity[T, T] {
-	return &TxIdentity[T, T]{values: values, time: nil}
+	return &TxIdentity[T, T]{values: values, time: nil, valid: nil}
 }
 
 // Create a new TxIdentity struct with the specified values and time.
 func NewTxIdentityWithTime[T packer.Number](values []T, time []uint64) *TxIdentity[T, T] {
-	return &TxIdentity[T, T]{values: values, time: time}
+	return &TxIdentity[T, T]{values: values, time: time, valid: nil}
+}
+
+// Create a new TxIdentity struct with the specified values and time, where
+// valid marks the values that are present. A nil valid marks every value as
+// present.
+func NewTxIdentityWithNulls[T packer.Number](
+	values []T, time []uint64, valid *bitmap.Bitmap) *TxIdentity[T, T] {
+
+	return &TxIdentity[T, T]{values: values, time: time, valid: valid}
 }
 
 // ----------------
@@ -55,3 +66,12 @@ func (tx *TxIdentity[S, T]) IsEmpty() bool {
 func (tx *TxIdentity[S, T]) Length() int {
 	return len(tx.values)
 }
+
+// Return true if the value at the specified index is present.
+func (tx *TxIdentity[S, T]) IsValidAt(idx int) bool {
+	if tx.valid == nil {
+		return true
+	}
+	v, _ := tx.valid.Get(uint64(idx))
+	return v
+}
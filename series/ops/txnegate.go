@@ -39,3 +39,7 @@ func (tx *TxNegate[S, T]) IsEmpty() bool {
 func (tx *TxNegate[S, T]) Length() int {
 	return len(tx.values)
 }
+
+func (tx *TxNegate[S, T]) IsValidAt(idx int) bool {
+	return true
+}
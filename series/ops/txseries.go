@@ -40,6 +40,11 @@ func (tx *TxSeries[S, T]) Length() int {
 	return tx.s.Size()
 }
 
+func (tx *TxSeries[S, T]) IsValidAt(idx int) bool {
+	valid, _ := tx.s.IsValid(idx)
+	return valid
+}
+
 func (tx *TxSeries[S, T]) Offset(offset int) *TxSeries[T, T] {
 	if (offset + tx.offset) >= tx.s.Size() {
 		return nil
@@ -0,0 +1,116 @@
+package series
+
+import (
+	"github.com/rmravindran/ats/series/frame"
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Configures a series at construction time
+type Option func(*options)
+
+type options struct {
+
+	// Codec used to pack the time frames
+	timeCodec packer.CodecID
+
+	// Codec used to pack the value frames
+	valueCodec packer.CodecID
+
+	// Keep the frames packed while appending
+	appendable bool
+
+	// Number of values between restart points of the packed frames
+	restartInterval uint64
+
+	// Error bound of the values of lossy value frames
+	errorMode  packer.ErrorMode
+	errorBound float64
+
+	// Scale of the values of decimal value frames
+	decimalScale uint8
+
+	// Budget accounting the values decoded from the packed frames
+	budget *frame.Budget
+}
+
+func defaultOptions() options {
+	return options{
+		timeCodec:       packer.CodecDeltaOfDelta,
+		valueCodec:      packer.CodecChimp,
+		appendable:      false,
+		restartInterval: 0,
+		errorMode:       packer.AbsoluteError,
+		errorBound:      0,
+		decimalScale:    0,
+		budget:          nil,
+	}
+}
+
+// Pack the time frames of the series with the codec of the specified id.
+func WithTimeCodec(id packer.CodecID) Option {
+	return func(opts *options) {
+		opts.timeCodec = id
+	}
+}
+
+// Pack the value frames of the series with the codec of the specified id.
+// Lossy codecs need an error bound and are selected with WithMaxError.
+func WithValueCodec(id packer.CodecID) Option {
+	return func(opts *options) {
+		opts.valueCodec = id
+	}
+}
+
+// Keep the head frames of the series packed while values are appended to
+// them, instead of holding frameSize unpacked elements. Only frames packed
+// with a codec supporting incremental encoding (Chimp, Gorilla and
+// DeltaOfDelta, the default time codec) are kept packed; other frames, such
+// as value frames packed with Simple8b or RLE, are filled unpacked as usual.
+func WithAppendableFrames() Option {
+	return func(opts *options) {
+		opts.appendable = true
+	}
+}
+
+// Write restart points every interval values in the packed frames, so single
+// values of packed frames can be read by decoding the segment holding them
+// (see packer.Seeker). Only applies to codecs supporting restart points
+// (Chimp, Gorilla).
+func WithRestartInterval(interval uint64) Option {
+	return func(opts *options) {
+		opts.restartInterval = interval
+	}
+}
+
+// Pack the value frames of the series with the lossy Quantized codec, keeping
+// every value within the specified absolute or relative error bound of the
+// appended value (see packer.Quantized). Only applies to float64 series;
+// packed value frames are flagged as lossy (see frame.IsLossy).
+func WithMaxError(mode packer.ErrorMode, bound float64) Option {
+	return func(opts *options) {
+		opts.valueCodec = packer.CodecQuantized
+		opts.errorMode = mode
+		opts.errorBound = bound
+	}
+}
+
+// Pack the value frames of the series with the Decimal codec, recording the
+// specified scale in every packed frame (see packer.DecimalPacker). Only
+// applies to packer.Decimal series; the values are the mantissas of decimals
+// of that scale.
+func WithDecimalScale(scale uint8) Option {
+	return func(opts *options) {
+		opts.valueCodec = packer.CodecDecimal
+		opts.decimalScale = scale
+	}
+}
+
+// Account the values decoded from the packed frames of the series in the
+// specified memory budget, so reading a large series only keeps the most
+// recently used frames unpacked (see frame.Budget). The budget may be shared
+// by many series.
+func WithMemoryBudget(b *frame.Budget) Option {
+	return func(opts *options) {
+		opts.budget = b
+	}
+}
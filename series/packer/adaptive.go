@@ -0,0 +1,284 @@
+package packer
+
+// Adaptive packing selects the codec and pack operation per frame. Before
+// packing, a sample of the frame is trial compressed with every candidate
+// codec and PackOp, and the frame is packed with the candidate that scores
+// best under the configured policy. The block is produced by the selected
+// codec, so its header records the choice and the block can be unpacked by
+// any packer of that codec (or by an Adaptive packer, which dispatches on the
+// header).
+//
+// Offset and Delta are not guaranteed to be lossless on floating point values,
+// so for floats those candidates are only kept if the frame survives the
+// round trip. Lossy codecs (see FlagLossy) are never selected.
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Criteria used to rank the candidates of adaptive packing
+type AdaptivePolicy int64
+
+const (
+	// Keep the candidate producing the smallest packed size
+	SmallestSize AdaptivePolicy = iota
+
+	// Keep the candidate that unpacks the sample the fastest
+	FastestDecode
+)
+
+func (s AdaptivePolicy) String() string {
+	switch s {
+	case SmallestSize:
+		return "SmallestSize"
+	case FastestDecode:
+		return "FastestDecode"
+	}
+	return "Invalid"
+}
+
+// Default number of values trial compressed per candidate
+const DefaultAdaptiveSampleSize = 1024
+
+// Number of contiguous chunks the sample is made of
+const adaptiveSampleChunks = 4
+
+// Configuration of adaptive packing
+type AdaptiveConfig struct {
+
+	// Candidate codecs. If empty, every registered codec supporting the
+	// element type is a candidate.
+	Codecs []CodecID
+
+	// Candidate pack operations. If empty, NOP, Offset and Delta are the
+	// candidates.
+	Ops []PackOp
+
+	// Policy used to rank the candidates
+	Policy AdaptivePolicy
+
+	// Number of values trial compressed per candidate. If 0,
+	// DefaultAdaptiveSampleSize is used.
+	SampleSize int
+}
+
+type Adaptive[T Number] struct {
+	config AdaptiveConfig
+}
+
+type adaptiveCandidate[T Number] struct {
+	codec   CodecID
+	op      PackOp
+	opParam T
+	score   uint64
+}
+
+func init() {
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[int64] { return NewAdaptive[int64](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[uint64] { return NewAdaptive[uint64](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[float64] { return NewAdaptive[float64](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[int32] { return NewAdaptive[int32](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[uint32] { return NewAdaptive[uint32](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[float32] { return NewAdaptive[float32](AdaptiveConfig{}) })
+	mustRegister(CodecAdaptive, "adaptive", func() Packer[Decimal] { return NewAdaptive[Decimal](AdaptiveConfig{}) })
+}
+
+// Create a new adaptive packer with the specified configuration
+func NewAdaptive[T Number](config AdaptiveConfig) *Adaptive[T] {
+	return &Adaptive[T]{
+		config: config,
+	}
+}
+
+// Packs the data in the src slice to the dst buffer with the best candidate
+// codec and pack operation, and returns nil if packing was completed
+// successfuly. Otherwise, returns the error. The op and opParam are only used
+// if the configuration has no candidate pack operations and a single
+// candidate codec.
+func (ad *Adaptive[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	candidates := ad.rank(src, op, opParam)
+	if len(candidates) == 0 {
+		return errors.New("no candidate codec for adaptive pack")
+	}
+
+	start := dst.Len()
+	isFloat := elemTypeOf[T]().isFloat()
+	for _, candidate := range candidates {
+		p, err := New[T](candidate.codec)
+		if err != nil {
+			continue
+		}
+		if err := p.Pack(src, dst, candidate.op, candidate.opParam); err != nil {
+			dst.Truncate(start)
+			continue
+		}
+		if isFloat && candidate.op != NOP && !roundTrips(p, dst.Bytes()[start:], src) {
+			dst.Truncate(start)
+			continue
+		}
+
+		return nil
+	}
+
+	return errors.New("no candidate codec could pack the frame")
+}
+
+// Unpacks the data in the src buffer to the dst slice with the codec recorded
+// in the block header and returns number of elements unpacked along with nil
+// error. Otherwise, returns (0, error).
+func (ad *Adaptive[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	p, err := ForBuffer[T](src)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.Unpack(src, dst, op, opParam)
+}
+
+// Return the id of the codec
+func (ad *Adaptive[T]) Codec() CodecID {
+	return CodecAdaptive
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Trial compresses a sample of src with every candidate and returns the
+// candidates sorted from best to worst.
+func (ad *Adaptive[T]) rank(src []T, op PackOp, opParam T) []adaptiveCandidate[T] {
+	codecs := ad.config.Codecs
+	if len(codecs) == 0 {
+		for _, id := range Codecs() {
+			if id != CodecAdaptive && Supports[T](id) {
+				codecs = append(codecs, id)
+			}
+		}
+	}
+
+	ops := ad.config.Ops
+	if len(ops) == 0 {
+		if len(ad.config.Codecs) == 1 {
+			ops = []PackOp{op}
+		} else {
+			ops = []PackOp{NOP, Offset, Delta}
+		}
+	}
+
+	sample := ad.sample(src)
+	isFloat := elemTypeOf[T]().isFloat()
+
+	candidates := make([]adaptiveCandidate[T], 0, len(codecs)*len(ops))
+	buffer := &bytes.Buffer{}
+	for _, codec := range codecs {
+		if codec == CodecAdaptive || !Supports[T](codec) {
+			continue
+		}
+		p, err := New[T](codec)
+		if err != nil {
+			continue
+		}
+
+		for _, candidateOp := range ops {
+			candidate := adaptiveCandidate[T]{codec: codec, op: candidateOp, opParam: opParam}
+			if len(ops) > 1 {
+				candidate.opParam = adaptiveOpParam(src, candidateOp)
+			}
+
+			buffer.Reset()
+			if err := p.Pack(sample, buffer, candidate.op, candidate.opParam); err != nil {
+				continue
+			}
+			if hdr, _ := decodeHeader(buffer.Bytes()); hdr.HasFlag(FlagLossy) {
+				continue
+			}
+			if isFloat && candidate.op != NOP && !roundTrips(p, buffer.Bytes(), sample) {
+				continue
+			}
+
+			switch ad.config.Policy {
+			case FastestDecode:
+				dst := make([]T, len(sample))
+				begin := time.Now()
+				if _, err := p.Unpack(buffer, dst, candidate.op, candidate.opParam); err != nil {
+					continue
+				}
+				candidate.score = uint64(time.Since(begin))
+			default:
+				candidate.score = uint64(buffer.Len())
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score < candidates[j].score
+	})
+
+	return candidates
+}
+
+// Return a sample of src made of evenly spaced contiguous chunks, so that the
+// runs and deltas of the values are preserved.
+func (ad *Adaptive[T]) sample(src []T) []T {
+	sampleSize := ad.config.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = DefaultAdaptiveSampleSize
+	}
+	if len(src) <= sampleSize {
+		return src
+	}
+
+	chunkSize := sampleSize / adaptiveSampleChunks
+	if chunkSize == 0 {
+		chunkSize = 1
+	}
+	numChunks := sampleSize / chunkSize
+	stride := len(src) / numChunks
+
+	sample := make([]T, 0, numChunks*chunkSize)
+	for ndx := 0; ndx < numChunks; ndx++ {
+		sample = append(sample, src[ndx*stride:ndx*stride+chunkSize]...)
+	}
+	return sample
+}
+
+// Return the op parameter used for the candidate pack operation. Offset
+// shifts the values by their minimum and Delta starts from the first value.
+func adaptiveOpParam[T Number](src []T, op PackOp) T {
+	if len(src) == 0 {
+		return 0
+	}
+
+	switch op {
+	case Offset:
+		minVal := src[0]
+		for _, v := range src {
+			if v < minVal {
+				minVal = v
+			}
+		}
+		return -minVal
+	case Delta:
+		return src[0]
+	}
+	return 0
+}
+
+// Return true if the block unpacks to exactly the values of src
+func roundTrips[T Number](p Packer[T], block []byte, src []T) bool {
+	dst := make([]T, len(src))
+	if _, err := p.Unpack(bytes.NewBuffer(block), dst, NOP, 0); err != nil {
+		return false
+	}
+
+	for ndx := range src {
+		if toBits(src[ndx]) != toBits(dst[ndx]) {
+			return false
+		}
+	}
+	return true
+}
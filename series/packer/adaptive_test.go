@@ -0,0 +1,111 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func adaptivePack[T Number](t *testing.T, config AdaptiveConfig, a []T) Header {
+	buffer := &bytes.Buffer{}
+	ad := NewAdaptive[T](config)
+
+	err := ad.Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	res := make([]T, len(a))
+	numElements, err := NewAdaptive[T](AdaptiveConfig{}).Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	assert.Equal(t, a, res)
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), hdr.NumElements)
+	assert.Equal(t, hdr.BlockSize(), uint64(buffer.Len()))
+
+	return hdr
+}
+
+func TestAdaptive_ConstFrame(t *testing.T) {
+
+	a := make([]float64, 5000)
+	for i := range a {
+		a[i] = 1999.9999
+	}
+
+	hdr := adaptivePack(t, AdaptiveConfig{}, a)
+	assert.Equal(t, CodecRLE, hdr.Codec)
+}
+
+func TestAdaptive_Timestamps(t *testing.T) {
+
+	a := make([]uint64, 5000)
+	for i := range a {
+		a[i] = 1700000000000 + uint64(i)*1000
+	}
+
+	hdr := adaptivePack(t, AdaptiveConfig{}, a)
+
+	// A single run of constant deltas beats every other encoding
+	assert.Equal(t, CodecRLE, hdr.Codec)
+	assert.Equal(t, Delta, hdr.Op)
+
+	// Without RLE, delta-of-delta wins
+	hdr = adaptivePack(t, AdaptiveConfig{
+		Codecs: []CodecID{CodecChimp, CodecGorilla, CodecDeltaOfDelta},
+		Ops:    []PackOp{NOP}}, a)
+	assert.Equal(t, CodecDeltaOfDelta, hdr.Codec)
+}
+
+func TestAdaptive_Prices(t *testing.T) {
+
+	a := make([]float64, 5000)
+	price := 250.0
+	for i := range a {
+		price = math.Round((price+float64((i*7919)%21-10)*0.01)*100) / 100
+		a[i] = price
+	}
+
+	hdr := adaptivePack(t, AdaptiveConfig{}, a)
+	assert.Equal(t, CodecALP, hdr.Codec)
+}
+
+func TestAdaptive_FastestDecode(t *testing.T) {
+
+	a := make([]int64, 5000)
+	for i := range a {
+		a[i] = int64(i % 100)
+	}
+
+	hdr := adaptivePack(t, AdaptiveConfig{
+		Codecs:     []CodecID{CodecChimp, CodecSimple8b},
+		Policy:     FastestDecode,
+		SampleSize: 256}, a)
+	assert.Contains(t, []CodecID{CodecChimp, CodecSimple8b}, hdr.Codec)
+}
+
+func TestAdaptive_NoCandidate(t *testing.T) {
+
+	ad := NewAdaptive[float64](AdaptiveConfig{Codecs: []CodecID{CodecSimple8b}})
+	err := ad.Pack([]float64{1, 2, 3}, &bytes.Buffer{}, NOP, 0)
+	assert.NotNil(t, err)
+}
+
+// Packing through the registry with the frame provided op and opParam
+func TestAdaptive_SingleCodec(t *testing.T) {
+
+	a := []float64{1, 2, 3, 4}
+	buffer := &bytes.Buffer{}
+
+	ad := NewAdaptive[float64](AdaptiveConfig{Codecs: []CodecID{CodecGorilla}})
+	err := ad.Pack(a, buffer, Offset, -1.0)
+	assert.Nil(t, err)
+
+	hdr, _ := ReadHeader(buffer)
+	assert.Equal(t, CodecGorilla, hdr.Codec)
+	assert.Equal(t, Offset, hdr.Op)
+	assert.Equal(t, -1.0, fromBits[float64](hdr.OpParam))
+}
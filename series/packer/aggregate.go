@@ -0,0 +1,469 @@
+package packer
+
+// Aggregates evaluated on packed blocks. Some aggregates can be answered from
+// a packed block without unpacking its values: the count is held by the block
+// header, the sum and extrema by the statistics section when present, and
+// codecs storing runs or integer blocks (RLE, Simple-8b) evaluate them while
+// walking the bitstream, a whole run at a time. AggregateBlock tries these in
+// order and reports when the block has to be unpacked instead.
+//
+// Sums of float64 runs are computed as the run value multiplied by the run
+// length, so they may differ from the sum of the unpacked values by rounding.
+//
+// Counting aggregates (see Aggregate.IsCount) are evaluated as unsigned
+// integers by CountBlock. AggregateBlock returns them as values of the element
+// type, which is rejected for types that do not hold counts exactly (see
+// CountValue).
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+// Aggregate evaluated over the values of a block
+type Aggregate uint8
+
+const (
+	// Number of values
+	AggregateCount Aggregate = iota
+
+	// Sum of the values, wrapping around on overflow for integers
+	AggregateSum
+
+	// Smallest value
+	AggregateMin
+
+	// Largest value
+	AggregateMax
+
+	// Number of values equal to the argument of the aggregate
+	AggregateCountEqual
+)
+
+func (a Aggregate) String() string {
+	switch a {
+	case AggregateCount:
+		return "Count"
+	case AggregateSum:
+		return "Sum"
+	case AggregateMin:
+		return "Min"
+	case AggregateMax:
+		return "Max"
+	case AggregateCountEqual:
+		return "CountEqual"
+	}
+	return "Unknown"
+}
+
+// Return true if the aggregate counts values, its result being a number of
+// values rather than a value
+func (a Aggregate) IsCount() bool {
+	return a == AggregateCount || a == AggregateCountEqual
+}
+
+// Implemented by packers able to evaluate aggregates on their packed blocks
+// without unpacking the values.
+type Aggregator[T Number] interface {
+	Packer[T]
+
+	// Evaluates the aggregate over the values of the block at the start of the
+	// src buffer, arg being the argument of AggregateCountEqual. The buffer is
+	// not consumed. Returns (result, true, nil) if the aggregate was evaluated,
+	// (0, false, nil) if the codec cannot evaluate it on this block, otherwise
+	// returns (0, false, error).
+	Aggregate(src *bytes.Buffer, agg Aggregate, arg T) (T, bool, error)
+}
+
+// Implemented by packers able to count values of their packed blocks without
+// unpacking them.
+type Counter[T Number] interface {
+	Packer[T]
+
+	// Evaluates the counting aggregate (see Aggregate.IsCount) over the
+	// values of the block at the start of the src buffer, arg being the
+	// argument of AggregateCountEqual. The buffer is not consumed. Returns
+	// (count, true, nil) if the aggregate was evaluated, (0, false, nil) if
+	// the codec cannot evaluate it on this block, otherwise returns (0,
+	// false, error).
+	Count(src *bytes.Buffer, agg Aggregate, arg T) (uint64, bool, error)
+}
+
+// Evaluates the aggregate over the values of the block at the start of the src
+// buffer without unpacking them, using the block header, the statistics
+// section or the packer when it implements Aggregator. Statistics of lossy
+// blocks describe the original values and are not used. Missing values of
+// blocks holding a validity section are left out of the aggregate, so such
+// blocks are only evaluated from their statistics. Returns (result,
+// true, nil) if the aggregate was evaluated, (0, false, nil) if the block has
+// to be unpacked, otherwise returns (0, false, error). Counting aggregates are
+// evaluated by CountBlock and returned as values (see CountValue).
+func AggregateBlock[T Number](
+	p Packer[T], src *bytes.Buffer, agg Aggregate, arg T) (T, bool, error) {
+
+	if agg.IsCount() {
+		return countValue[T](CountBlock(p, src, agg, arg))
+	}
+
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return 0, false, err
+	}
+	if hdr.ElemType != elemTypeOf[T]() {
+		return 0, false, errors.New("block holds a different element type")
+	}
+
+	if hdr.HasFlag(FlagValidity) {
+		return aggregateStats[T](&hdr, src, agg)
+	}
+
+	if hdr.HasFlag(FlagStats) && !hdr.HasFlag(FlagLossy) && hdr.NumElements > 0 {
+		if v, ok, err := aggregateStats[T](&hdr, src, agg); ok || err != nil {
+			return v, ok, err
+		}
+	}
+
+	if a, ok := p.(Aggregator[T]); ok {
+		return a.Aggregate(src, agg, arg)
+	}
+	return 0, false, nil
+}
+
+// Evaluates the counting aggregate (see Aggregate.IsCount) over the values of
+// the block at the start of the src buffer, without unpacking them, using the
+// block header, the statistics section or the packer when it implements
+// Counter. Missing values of blocks holding a validity section are left out of
+// the count, so such blocks are only counted from their statistics. Returns
+// (count, true, nil) if the aggregate was evaluated, (0, false, nil) if the
+// block has to be unpacked, otherwise returns (0, false, error).
+func CountBlock[T Number](
+	p Packer[T], src *bytes.Buffer, agg Aggregate, arg T) (uint64, bool, error) {
+
+	if !agg.IsCount() {
+		return 0, false, errors.New("not a counting aggregate")
+	}
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return 0, false, err
+	}
+	if hdr.ElemType != elemTypeOf[T]() {
+		return 0, false, errors.New("block holds a different element type")
+	}
+
+	if hdr.HasFlag(FlagValidity) {
+		if agg != AggregateCount || !hdr.HasFlag(FlagStats) || hdr.HasFlag(FlagLossy) {
+			return 0, false, nil
+		}
+		stats, err := ReadStats[T](src)
+		if err != nil {
+			return 0, false, err
+		}
+		return stats.Count, true, nil
+	}
+
+	if agg == AggregateCount {
+		return hdr.NumElements, true, nil
+	}
+
+	if c, ok := p.(Counter[T]); ok {
+		return c.Count(src, agg, arg)
+	}
+	return 0, false, nil
+}
+
+// Return the count as a value of type T along with nil error, otherwise
+// returns (0, error) if T does not hold counts exactly: Decimal values are
+// mantissas whose meaning depends on their scale, and float32 values lose
+// counts beyond 2^24. Such counts are evaluated with CountBlock instead.
+func CountValue[T Number](count uint64) (T, error) {
+	switch e := elemTypeOf[T](); e {
+	case ElemDecimal, ElemFloat32:
+		return 0, fmt.Errorf("counts of %s values are not values, use CountBlock", e)
+	}
+	return T(count), nil
+}
+
+// Evaluates the aggregate on the runs of the block
+func (rle *RLE[T]) Aggregate(src *bytes.Buffer, agg Aggregate, arg T) (T, bool, error) {
+	if agg.IsCount() {
+		return countValue[T](rle.Count(src, agg, arg))
+	}
+	acc, ok, err := rle.walk(src, agg, arg)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	return acc.result, true, nil
+}
+
+// Counts the values of the block a run at a time
+func (rle *RLE[T]) Count(src *bytes.Buffer, agg Aggregate, arg T) (uint64, bool, error) {
+	if !agg.IsCount() {
+		return 0, false, errors.New("not a counting aggregate")
+	}
+	acc, ok, err := rle.walk(src, agg, arg)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	return acc.counted, true, nil
+}
+
+// Evaluates the aggregate on the integer block, a run of zero residuals at a
+// time
+func (s8 *Simple8b[T]) Aggregate(src *bytes.Buffer, agg Aggregate, arg T) (T, bool, error) {
+	if agg.IsCount() {
+		return countValue[T](s8.Count(src, agg, arg))
+	}
+	acc, ok, err := s8.walk(src, agg, arg)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	return acc.result, true, nil
+}
+
+// Counts the values of the integer block, a run of zero residuals at a time
+func (s8 *Simple8b[T]) Count(src *bytes.Buffer, agg Aggregate, arg T) (uint64, bool, error) {
+	if !agg.IsCount() {
+		return 0, false, errors.New("not a counting aggregate")
+	}
+	acc, ok, err := s8.walk(src, agg, arg)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	return acc.counted, true, nil
+}
+
+// Evaluates the aggregate with the codec recorded in the block header, when it
+// implements Aggregator
+func (ad *Adaptive[T]) Aggregate(src *bytes.Buffer, agg Aggregate, arg T) (T, bool, error) {
+	p, err := ForBuffer[T](src)
+	if err != nil {
+		return 0, false, err
+	}
+	if a, ok := p.(Aggregator[T]); ok {
+		return a.Aggregate(src, agg, arg)
+	}
+	return 0, false, nil
+}
+
+// Counts the values with the codec recorded in the block header, when it
+// implements Counter
+func (ad *Adaptive[T]) Count(src *bytes.Buffer, agg Aggregate, arg T) (uint64, bool, error) {
+	p, err := ForBuffer[T](src)
+	if err != nil {
+		return 0, false, err
+	}
+	if c, ok := p.(Counter[T]); ok {
+		return c.Count(src, agg, arg)
+	}
+	return 0, false, nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Accumulates the aggregate over the runs of the block. Returns (accumulator,
+// true, nil) if the aggregate was evaluated, (nil, false, nil) if it cannot be
+// evaluated on the block, otherwise returns (nil, false, error).
+func (rle *RLE[T]) walk(src *bytes.Buffer, agg Aggregate, arg T) (*runAggregate[T], bool, error) {
+	hdr, payload, err := openBlock[T](src, CodecRLE)
+	if err != nil {
+		return nil, false, streamError(err)
+	}
+	acc := newRunAggregate(&hdr, agg, arg)
+	if !acc.supported() {
+		return nil, false, nil
+	}
+	if hdr.NumElements == 0 {
+		return acc, true, nil
+	}
+
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+	constant, err := bitStream.ReadBit()
+	if err != nil {
+		return nil, false, streamError(err)
+	}
+	for read := uint64(0); read < hdr.NumElements; {
+		value, err := bitStream.ReadBits(64)
+		if err != nil {
+			return nil, false, streamError(err)
+		}
+
+		run := hdr.NumElements
+		if !constant {
+			width, err := bitStream.ReadBits(6)
+			if err != nil {
+				return nil, false, streamError(err)
+			}
+			if run, err = bitStream.ReadBits(int(width)); err != nil {
+				return nil, false, streamError(err)
+			}
+			run++
+		}
+		if run > hdr.NumElements-read {
+			return nil, false, fmt.Errorf("%w: invalid run length in aggregate", ErrCorrupt)
+		}
+
+		acc.addStored(value, run)
+		read += run
+	}
+
+	return acc, true, nil
+}
+
+// Accumulates the aggregate over the integer block, a run of zero residuals at
+// a time. Returns (accumulator, true, nil) if the aggregate was evaluated,
+// (nil, false, nil) if it cannot be evaluated on the block, otherwise returns
+// (nil, false, error).
+func (s8 *Simple8b[T]) walk(src *bytes.Buffer, agg Aggregate, arg T) (*runAggregate[T], bool, error) {
+	hdr, payload, err := openBlock[T](src, CodecSimple8b)
+	if err != nil {
+		return nil, false, streamError(err)
+	}
+	acc := newRunAggregate(&hdr, agg, arg)
+	if !acc.supported() {
+		return nil, false, nil
+	}
+
+	signed := s8.isSigned(hdr.Op)
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+	err = walkIntBlock(bitStream, hdr.NumElements, func(v, count uint64) {
+		if signed {
+			v = uint64(unzigzag(v))
+		}
+		acc.addStored(v, count)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return acc, true, nil
+}
+
+// Converts the result of a counting aggregate to a value (see CountValue).
+// Counts of types that do not hold them are rejected even when the count was
+// not evaluated.
+func countValue[T Number](count uint64, ok bool, err error) (T, bool, error) {
+	if err != nil {
+		return 0, false, err
+	}
+	v, err := CountValue[T](count)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+// Evaluates the aggregate from the statistics section of the block, if it has
+// one and is not lossy. Extrema of a block without values are undefined.
+func aggregateStats[T Number](hdr *Header, src *bytes.Buffer, agg Aggregate) (T, bool, error) {
+	if !hdr.HasFlag(FlagStats) || hdr.HasFlag(FlagLossy) {
+		return 0, false, nil
+	}
+	switch agg {
+	case AggregateSum, AggregateMin, AggregateMax:
+	default:
+		return 0, false, nil
+	}
+
+	stats, err := ReadStats[T](src)
+	if err != nil {
+		return 0, false, err
+	}
+	switch agg {
+	case AggregateSum:
+		return stats.Sum, true, nil
+	case AggregateMin:
+		return stats.Min, stats.Count > 0, nil
+	case AggregateMax:
+		return stats.Max, stats.Count > 0, nil
+	}
+	return 0, false, nil
+}
+
+// Accumulates an aggregate over runs of stored values of a block, the stored
+// values being transformed back by the op of the block
+type runAggregate[T Number] struct {
+	agg     Aggregate
+	arg     T
+	op      PackOp
+	opParam T
+	prev    T
+	count   uint64
+	numElem uint64
+	result  T
+
+	// Number of values counted by a counting aggregate
+	counted uint64
+}
+
+func newRunAggregate[T Number](hdr *Header, agg Aggregate, arg T) *runAggregate[T] {
+	opParam := fromBits[T](hdr.OpParam)
+	return &runAggregate[T]{
+		agg:     agg,
+		arg:     arg,
+		op:      hdr.Op,
+		opParam: opParam,
+		prev:    opParam,
+		numElem: hdr.NumElements,
+	}
+}
+
+// Return true if the aggregate can be evaluated on the runs of the block.
+// Extrema of an empty block are undefined.
+func (acc *runAggregate[T]) supported() bool {
+	switch acc.agg {
+	case AggregateCount, AggregateSum, AggregateCountEqual:
+		return true
+	case AggregateMin, AggregateMax:
+		return acc.numElem > 0
+	}
+	return false
+}
+
+// Adds a run of count equal stored values. A run of equal deltas is a run of
+// equal values only when the delta is zero, other runs of deltas are added a
+// value at a time.
+func (acc *runAggregate[T]) addStored(u uint64, count uint64) {
+	v := fromBits[T](u)
+	switch acc.op {
+	case NOP:
+		acc.addRun(v, count)
+	case Offset:
+		acc.addRun(v-acc.opParam, count)
+	case Delta:
+		if v == 0 {
+			acc.addRun(acc.prev, count)
+			return
+		}
+		for ; count > 0; count-- {
+			acc.prev += v
+			acc.addRun(acc.prev, 1)
+		}
+	}
+}
+
+func (acc *runAggregate[T]) addRun(v T, count uint64) {
+	switch acc.agg {
+	case AggregateCount:
+		acc.counted += count
+	case AggregateSum:
+		acc.result += v * T(count)
+	case AggregateMin:
+		if acc.count == 0 || v < acc.result {
+			acc.result = v
+		}
+	case AggregateMax:
+		if acc.count == 0 || v > acc.result {
+			acc.result = v
+		}
+	case AggregateCountEqual:
+		if v == acc.arg {
+			acc.counted += count
+		}
+	}
+	acc.count += count
+}
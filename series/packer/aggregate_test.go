@@ -0,0 +1,154 @@
+package packer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate_RunCodecs(t *testing.T) {
+
+	ints := make([]int64, 1000)
+	uints := make([]uint64, 1000)
+	floats := make([]float64, 1000)
+	for i := range ints {
+		ints[i] = int64(i/40%7) - 3
+		uints[i] = uint64(1000 + i/25*10)
+		floats[i] = float64(i/50) * 0.5
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		checkAggregates[int64](t, NewRLE[int64](), ints, op, 5, -3)
+		checkAggregates[int64](t, NewSimple8b[int64](), ints, op, 5, 2)
+		checkAggregates[uint64](t, NewRLE[uint64](), uints, op, 3, 1050)
+		checkAggregates[uint64](t, NewSimple8b[uint64](), uints, op, 3, 1100)
+		checkAggregates[float64](t, NewRLE[float64](), floats, op, 1.5, 4.5)
+	}
+}
+
+func TestAggregate_Fallback(t *testing.T) {
+
+	a := []float64{1.5, 2.5, 2.5, -1}
+	buffer := &bytes.Buffer{}
+	p := NewChimp[float64]()
+	assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+
+	// The count is held by the header, other aggregates need unpacking
+	v, ok, err := AggregateBlock[float64](p, buffer, AggregateCount, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 4.0, v)
+
+	_, ok, err = AggregateBlock[float64](p, buffer, AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// Sum and extrema are read from the statistics section
+	assert.Nil(t, AppendStats(buffer, ComputeStats(a)))
+	v, ok, err = AggregateBlock[float64](p, buffer, AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5.5, v)
+
+	v, ok, err = AggregateBlock[float64](p, buffer, AggregateMin, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, -1.0, v)
+
+	_, ok, err = AggregateBlock[float64](p, buffer, AggregateCountEqual, 2.5)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+// Counts are not values of Decimal and float32 blocks: a count read as a
+// Decimal would be a mantissa and float32 cannot hold every count.
+func TestAggregate_CountDecimal(t *testing.T) {
+
+	a := make([]Decimal, 1000)
+	for i := range a {
+		a[i] = Decimal(i / 100 * 25)
+	}
+	for _, p := range []Packer[Decimal]{NewRLE[Decimal](), NewSimple8b[Decimal]()} {
+		buffer := &bytes.Buffer{}
+		assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+
+		n, ok, err := CountBlock[Decimal](p, buffer, AggregateCount, 0)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1000), n)
+
+		n, ok, err = CountBlock[Decimal](p, buffer, AggregateCountEqual, 50)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(100), n)
+
+		_, _, err = AggregateBlock[Decimal](p, buffer, AggregateCount, 0)
+		assert.NotNil(t, err)
+		_, _, err = CountBlock[Decimal](p, buffer, AggregateSum, 0)
+		assert.NotNil(t, err)
+
+		// Other aggregates are still values
+		v, ok, err := AggregateBlock[Decimal](p, buffer, AggregateMax, 0)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, Decimal(225), v)
+	}
+
+	_, err := CountValue[float32](1 << 25)
+	assert.NotNil(t, err)
+	v, err := CountValue[int64](1 << 25)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1<<25), v)
+}
+
+func TestAggregate_EmptyAndCorrupt(t *testing.T) {
+
+	p := NewRLE[int64]()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, p.Pack([]int64{}, buffer, NOP, 0))
+
+	v, ok, err := AggregateBlock[int64](p, buffer, AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), v)
+
+	_, ok, err = AggregateBlock[int64](p, buffer, AggregateMax, 0)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	buffer = packTestBlock(t, CodecRLE)
+	buffer.Bytes()[HeaderSize] ^= 0x01
+	_, _, err = AggregateBlock[float64](NewRLE[float64](), buffer, AggregateSum, 0)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func checkAggregates[T Number](
+	t *testing.T, p Packer[T], values []T, op PackOp, opParam T, arg T) {
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, p.Pack(values, buffer, op, opParam))
+
+	stats := ComputeStats(values)
+	var equal T
+	for _, v := range values {
+		if v == arg {
+			equal++
+		}
+	}
+
+	expected := map[Aggregate]T{
+		AggregateCount:      T(len(values)),
+		AggregateSum:        stats.Sum,
+		AggregateMin:        stats.Min,
+		AggregateMax:        stats.Max,
+		AggregateCountEqual: equal,
+	}
+	for agg, exp := range expected {
+		v, ok, err := AggregateBlock[T](p, buffer, agg, arg)
+		assert.Nil(t, err)
+		assert.True(t, ok, agg.String())
+		assert.Equal(t, exp, v, agg.String())
+	}
+	assert.Greater(t, equal, T(0))
+}
@@ -0,0 +1,278 @@
+package packer
+
+// The core compaction logic is based on the SIGMOD 2024 paper about ALP
+// (Adaptive Lossless floating-Point compression). Floats that originate from
+// decimals with a few significant digits after the point are encoded as
+// integers d = round(v * 10^e / 10^f), where the exponent e and factor f are
+// detected per frame from a sample of its values. The integers are stored
+// with frame of reference bit packing (see writeIntBlock) and values that do
+// not survive the round trip (NaN, infinities, -0.0, too many digits) are
+// stored separately as exceptions.
+//
+// Offset is applied on the float values before encoding, as in the other
+// packers. Delta is applied on the encoded integers, which keeps the deltas
+// exact; the encoded opParam is used as the base of the first delta when it
+// is exactly representable with the detected exponent and factor.
+//
+// Ref: Azim Afroozeh, Leonardo X. Kuffo, and Peter Boncz. ALP: Adaptive
+//      Lossless floating-Point Compression. SIGMOD 2024.
+// Paper Link: https://dl.acm.org/doi/pdf/10.1145/3626717
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type ALP[T Number] struct{}
+
+const alpMaxExponent = 18
+
+// Number of values sampled for detecting the exponent and factor
+const alpSampleSize = 64
+
+// Largest magnitude of an encoded value
+const alpMaxEncoded = float64(1 << 62)
+
+var alpExp10 = [alpMaxExponent + 1]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9,
+	1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18}
+
+var alpInvExp10 = [alpMaxExponent + 1]float64{
+	1e-0, 1e-1, 1e-2, 1e-3, 1e-4, 1e-5, 1e-6, 1e-7, 1e-8, 1e-9,
+	1e-10, 1e-11, 1e-12, 1e-13, 1e-14, 1e-15, 1e-16, 1e-17, 1e-18}
+
+func init() {
+	mustRegister(CodecALP, "alp", func() Packer[float64] { return NewALP[float64]() })
+}
+
+func NewALP[T Number]() *ALP[T] {
+	return &ALP[T]{}
+}
+
+// Packs the float data in the src slice to the dst buffer and returns nil if
+// packing was completed successfuly. Otherwise, returns the error.
+func (alp *ALP[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if elemTypeOf[T]() != ElemFloat64 {
+		return errors.New("unsupported type in pack")
+	}
+
+	floats := make([]float64, len(src))
+	for ndx := range src {
+		floats[ndx] = float64(src[ndx])
+		if op == Offset {
+			floats[ndx] = float64(src[ndx] + opParam)
+		}
+	}
+
+	e, f := alpFindExponent(floats)
+
+	// Encode the values, using the previous encoded value as the placeholder
+	// of the exceptions to keep the frame of reference range tight.
+	encoded := make([]int64, len(floats))
+	exceptions := make([]int, 0)
+	var last int64 = 0
+	for ndx, v := range floats {
+		d, ok := alpEncode(v, e, f)
+		if !ok {
+			exceptions = append(exceptions, ndx)
+			d = last
+		}
+		encoded[ndx] = d
+		last = d
+	}
+
+	values := make([]uint64, len(encoded))
+	prev := alpDeltaBase(float64(opParam), e, f)
+	for ndx, d := range encoded {
+		if op == Delta {
+			d, prev = d-prev, d
+		}
+		values[ndx] = uint64(d) ^ (1 << 63)
+	}
+
+	start := reserveHeader(dst)
+
+	bitStream := bitstream.NewWriter(dst)
+	bitStream.WriteBits(uint64(e), 5)
+	bitStream.WriteBits(uint64(f), 5)
+	size := 10 + writeIntBlock(bitStream, values)
+
+	posBits := bits.Len64(uint64(len(src)))
+	bitStream.WriteBits(uint64(len(exceptions)), posBits)
+	size += uint64(posBits)
+	for _, ndx := range exceptions {
+		bitStream.WriteBits(uint64(ndx), posBits)
+		bitStream.WriteBits(math.Float64bits(floats[ndx]), 64)
+		size += uint64(posBits + 64)
+	}
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecALP,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     size,
+	})
+
+	return nil
+}
+
+// Unpacks the float data in the src buffer to the dst slice and returns
+// number of elements unpacked along with nil error. Otherwise, returns (0,
+// error). The block header in src takes precedence over op and opParam.
+func (alp *ALP[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecALP)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+	e, err := bitStream.ReadBits(5)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	f, err := bitStream.ReadBits(5)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if e > alpMaxExponent || f > e {
+		return 0, fmt.Errorf("%w: invalid exponent in unpack", ErrCorrupt)
+	}
+
+	values := make([]uint64, hdr.NumElements)
+	if err := readIntBlock(bitStream, values); err != nil {
+		return 0, streamError(err)
+	}
+
+	prev := alpDeltaBase(float64(opParam), int(e), int(f))
+	for ndx, u := range values {
+		d := int64(u ^ (1 << 63))
+		if op == Delta {
+			d += prev
+			prev = d
+		}
+		dst[ndx] = T(alpDecode(d, int(e), int(f)))
+	}
+
+	posBits := bits.Len64(hdr.NumElements)
+	numExceptions, err := bitStream.ReadBits(posBits)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	for ndx := uint64(0); ndx < numExceptions; ndx++ {
+		pos, err := bitStream.ReadBits(posBits)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		v, err := bitStream.ReadBits(64)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		if pos >= hdr.NumElements {
+			return 0, fmt.Errorf("%w: invalid exception position in unpack", ErrCorrupt)
+		}
+		dst[pos] = T(math.Float64frombits(v))
+	}
+
+	if op == Offset {
+		for ndx := uint64(0); ndx < hdr.NumElements; ndx++ {
+			dst[ndx] -= opParam
+		}
+	}
+
+	return hdr.NumElements, nil
+}
+
+// Return the id of the codec
+func (alp *ALP[T]) Codec() CodecID {
+	return CodecALP
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Encodes the value with the exponent and factor. Returns false if the value
+// does not survive the round trip.
+func alpEncode(v float64, e, f int) (int64, bool) {
+	scaled := v * alpExp10[e] * alpInvExp10[f]
+	if !(math.Abs(scaled) < alpMaxEncoded) {
+		return 0, false
+	}
+
+	d := int64(math.Round(scaled))
+	if math.Float64bits(alpDecode(d, e, f)) != math.Float64bits(v) {
+		return 0, false
+	}
+
+	return d, true
+}
+
+func alpDecode(d int64, e, f int) float64 {
+	return float64(d) * alpExp10[f] * alpInvExp10[e]
+}
+
+// Return the encoded opParam if it can be encoded exactly, otherwise 0.
+func alpDeltaBase(opParam float64, e, f int) int64 {
+	d, ok := alpEncode(opParam, e, f)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+// Detects the exponent and factor that minimize the estimated packed size of
+// a sample of the values.
+func alpFindExponent(values []float64) (int, int) {
+	step := 1
+	if len(values) > alpSampleSize {
+		step = len(values) / alpSampleSize
+	}
+
+	bestE, bestF := 0, 0
+	var bestSize uint64 = math.MaxUint64
+	for e := alpMaxExponent; e >= 0; e-- {
+		for f := 0; f <= e; f++ {
+			var minD, maxD int64 = math.MaxInt64, math.MinInt64
+			var numExceptions uint64 = 0
+			for ndx := 0; ndx < len(values); ndx += step {
+				d, ok := alpEncode(values[ndx], e, f)
+				if !ok {
+					numExceptions++
+					continue
+				}
+				if d < minD {
+					minD = d
+				}
+				if d > maxD {
+					maxD = d
+				}
+			}
+
+			var width uint64 = 0
+			if minD <= maxD {
+				width = uint64(bits.Len64(uint64(maxD - minD)))
+			}
+			numSamples := uint64((len(values) + step - 1) / step)
+			size := (numSamples-numExceptions)*width + numExceptions*(64+32)
+			if size < bestSize {
+				bestE, bestF, bestSize = e, f, size
+			}
+		}
+	}
+
+	return bestE, bestF
+}
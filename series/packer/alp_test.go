@@ -0,0 +1,154 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestALP_Float64_ValueCheck(t *testing.T) {
+
+	a := []float64{12.34, 12.35, 12.3, 1999.9999, 0.01, -7.25, 0, 100, 12.34, 3.5}
+	res := make([]float64, len(a))
+
+	alp := NewALP[float64]()
+	buffer := &bytes.Buffer{}
+
+	err := alp.Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	numElements, err := alp.Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	assert.Equal(t, a, res)
+}
+
+// Values that cannot be represented as decimals are stored as exceptions
+func TestALP_Float64_Exceptions(t *testing.T) {
+
+	a := []float64{
+		1.25, math.NaN(), 1.5, math.Inf(1), math.Copysign(0, -1), math.Pi,
+		math.Inf(-1), 1e300, 1.75, math.SmallestNonzeroFloat64}
+	res := make([]float64, len(a))
+
+	buffer := &bytes.Buffer{}
+	err := NewALP[float64]().Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	_, err = NewALP[float64]().Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	for i := range a {
+		assert.Equal(t, math.Float64bits(a[i]), math.Float64bits(res[i]))
+	}
+}
+
+func TestALP_Float64_PackOps(t *testing.T) {
+
+	a := make([]float64, 1000)
+	price := 100.0
+	for i := range a {
+		price = math.Round((price+float64(i%7-3)*0.01)*100) / 100
+		a[i] = price
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+
+		err := NewALP[float64]().Pack(a, buffer, op, 0.5)
+		assert.Nil(t, err)
+
+		res := make([]float64, len(a))
+		_, err = NewALP[float64]().Unpack(buffer, res, NOP, 0.0)
+		assert.Nil(t, err)
+		assert.Equal(t, a, res, op.String())
+	}
+}
+
+// Prices with two decimal digits should pack far better than with Chimp
+func TestALP_Float64_CompressionCheckForPrices(t *testing.T) {
+
+	a := make([]float64, 10000)
+	price := 250.0
+	for i := range a {
+		price = math.Round((price+float64((i*7919)%21-10)*0.01)*100) / 100
+		a[i] = price
+	}
+
+	alp := NewALP[float64]()
+	alpBuffer := &bytes.Buffer{}
+	alp.Pack(a, alpBuffer, NOP, 0.0)
+
+	chimp := NewChimp[float64]()
+	chimpBuffer := &bytes.Buffer{}
+	chimp.Pack(a, chimpBuffer, NOP, 0.0)
+
+	assert.Less(t, 2*alpBuffer.Len(), chimpBuffer.Len())
+
+	// With deltas every value fits into 5 bits
+	alpBuffer.Reset()
+	alp.Pack(a, alpBuffer, Delta, 0.0)
+	assert.LessOrEqual(t, packedBits(alpBuffer), uint64(10+65+64*(1+len(a)/12)+14))
+}
+
+func TestALP_UnsupportedType(t *testing.T) {
+
+	err := NewALP[int64]().Pack([]int64{1}, &bytes.Buffer{}, NOP, 0)
+	assert.NotNil(t, err)
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// prices with two decimal digits.
+func BenchmarkALPFor_Float64_PackingPrices(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	for i := range a {
+		a[i] = float64(100000+(i*7919)%5000) / 100
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		alp := NewALP[float64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		alp.Pack(a, buffer, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will unpack 1 million
+// prices with two decimal digits.
+func BenchmarkALPFor_Float64_UnpackingPrices(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	res := make([]float64, 1000000)
+	for i := range a {
+		a[i] = float64(100000+(i*7919)%5000) / 100
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		alp := NewALP[float64]()
+		buffer := &bytes.Buffer{}
+		alp.Pack(a, buffer, NOP, 0.0)
+
+		t.StartTimer()
+		alp.Unpack(buffer, res, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+func BenchmarkALPFor_StockPrice(t *testing.B) {
+
+	prices := stockPrices()
+
+	alp := NewALP[float64]()
+	buffer := &bytes.Buffer{}
+	buffer.Grow(50000000)
+	t.StartTimer()
+	alp.Pack(prices, buffer, NOP, 0.0)
+	t.StopTimer()
+	println("Num bytes: ", buffer.Len())
+}
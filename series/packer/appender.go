@@ -0,0 +1,163 @@
+package packer
+
+// Streaming compaction. An appender encodes values one at a time and keeps
+// the state of the encoder (previous value, stored leading and trailing zeros
+// and the open bit writer) between calls, so a frame being filled can stay
+// packed instead of holding its raw values. A snapshot of the appender is a
+// regular packed block, identical to the block produced by packing all the
+// appended values at once.
+
+import (
+	"bytes"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+// Appender interface specification
+type Appender[T Number] interface {
+
+	// Encodes the value after the previously appended values. Returns nil if
+	// the value was appended, otherwise returns the error.
+	Append(value T) error
+
+	// Writes the packed block (header and payload) holding all the values
+	// appended so far to the dst buffer. Appending can continue afterwards.
+	Snapshot(dst *bytes.Buffer) error
+
+	// Return the size of the packed block holding the appended values
+	PackedSize() uint64
+
+	// Return the number of appended values
+	NumElements() uint64
+}
+
+// Implemented by packers that are able to encode values incrementally.
+type AppendablePacker[T Number] interface {
+	Packer[T]
+
+	// Create an appender encoding values with the specified pre-compact
+	// operation and parameter.
+	NewAppender(op PackOp, opParam T) Appender[T]
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Appender of the XOR based codecs. Signs of signed integers are packed ahead
+// of the values in a block, so they are collected in their own bit writer and
+// concatenated with the values when a snapshot is taken. With a non zero
+// interval, restart points are written every interval values (see
+// restartIndex), their offsets being relative to the values until then.
+type xorAppender[T Number] struct {
+	codec       CodecID
+	encoder     xorCodec
+	interval    uint64
+	offsets     []uint64
+	smallInts   bool
+	op          PackOp
+	opParam     T
+	prev        T
+	signs       bitWriter
+	values      bitWriter
+	numElements uint64
+}
+
+func newXorAppender[T Number](
+	codec CodecID, encoder xorCodec, interval uint64, op PackOp, opParam T) *xorAppender[T] {
+
+	encoder.setWordBits(xorWordBits(elemTypeOf[T](), true))
+	return &xorAppender[T]{
+		codec:       codec,
+		encoder:     encoder,
+		interval:    interval,
+		offsets:     nil,
+		smallInts:   true,
+		op:          op,
+		opParam:     opParam,
+		prev:        opParam,
+		numElements: 0,
+	}
+}
+
+func (app *xorAppender[T]) Append(value T) error {
+	elemType := elemTypeOf[T]()
+
+	if app.interval > 0 && app.numElements%app.interval == 0 {
+		app.encoder.reset()
+		app.offsets = append(app.offsets, app.values.nbits)
+		app.prev = app.opParam
+	}
+
+	if elemType.isSignedInt() {
+		app.signs.WriteBit(bitstream.Bit(value < 0))
+		if value < 0 {
+			value = T(int64(value) * -1)
+		}
+	}
+
+	var uVal uint64
+	switch app.op {
+	case NOP:
+		uVal = toWord(value)
+	case Offset:
+		uVal = toWord(value + app.opParam)
+	case Delta:
+		uVal = toWord(value - app.prev)
+		app.prev = value
+	}
+	if elemType != ElemFloat64 && app.smallInts {
+		uVal = (uVal << 32) | (uVal >> 32)
+	}
+
+	app.encoder.addUIntValue(&app.values, uVal)
+	app.numElements++
+
+	return nil
+}
+
+func (app *xorAppender[T]) Snapshot(dst *bytes.Buffer) error {
+	start := reserveHeader(dst)
+
+	payload := bitWriter{buf: make([]byte, 0, len(app.signs.buf)+len(app.values.buf))}
+	payload.append(&app.signs)
+	payload.append(&app.values)
+
+	var flags uint8 = 0
+	if app.smallInts {
+		flags |= FlagSmallInts
+	}
+	if app.interval > 0 {
+		idx := restartIndex{interval: app.interval, offsets: make([]uint64, len(app.offsets))}
+		for i, offset := range app.offsets {
+			idx.offsets[i] = app.signs.nbits + offset
+		}
+		idx.write(&payload)
+		flags |= FlagIndex
+	}
+	dst.Write(payload.bytes())
+
+	writeHeader(dst, start, &Header{
+		Codec:       app.codec,
+		ElemType:    elemTypeOf[T](),
+		Op:          app.op,
+		Flags:       flags,
+		NumElements: app.numElements,
+		OpParam:     toBits(app.opParam),
+		NumBits:     payload.nbits,
+	})
+
+	return nil
+}
+
+func (app *xorAppender[T]) PackedSize() uint64 {
+	numBits := app.signs.nbits + app.values.nbits
+	if app.interval > 0 {
+		numBits = 8 * ((numBits+7)/8 + 8*uint64(len(app.offsets)) + 8)
+	}
+	return packedSize(numBits)
+}
+
+func (app *xorAppender[T]) NumElements() uint64 {
+	return app.numElements
+}
@@ -0,0 +1,114 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/dgryski/go-bitstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppender_BitWriter(t *testing.T) {
+
+	widths := []int{1, 3, 64, 7, 8, 13, 2, 64, 5}
+	values := []uint64{1, 5, math.MaxUint64, 0x55, 0xA5, 0x1ABC, 0x7, 0x0123456789ABCDEF, 0xFF}
+
+	expected := &bytes.Buffer{}
+	bs := bitstream.NewWriter(expected)
+	w := bitWriter{}
+	for i := range widths {
+		bs.WriteBits(values[i], widths[i])
+		w.WriteBits(values[i], widths[i])
+	}
+	bs.Flush(false)
+	assert.Equal(t, expected.Bytes(), w.bytes())
+
+	// Appending an unaligned writer
+	head := bitWriter{}
+	head.WriteBits(0x5, 3)
+	tail := bitWriter{}
+	tail.WriteBits(0x1ABC, 13)
+	tail.WriteBit(true)
+	head.append(&tail)
+
+	check := bitWriter{}
+	check.WriteBits(0x5, 3)
+	check.WriteBits(0x1ABC, 13)
+	check.WriteBit(true)
+	assert.Equal(t, check.nbits, head.nbits)
+	assert.Equal(t, check.bytes(), head.bytes())
+}
+
+// A snapshot of an appender must be identical to the block packed in one go
+func TestAppender_MatchesPack(t *testing.T) {
+
+	prices := make([]float64, 500)
+	ints := make([]int64, 500)
+	uints := make([]uint64, 500)
+	for i := range prices {
+		prices[i] = 100.0 + math.Sin(float64(i)/10.0)*3.25
+		ints[i] = int64(i*i%97) - 40
+		uints[i] = uint64(1700000000 + i*15 + i%3)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		checkAppender[float64](t, NewChimp[float64](), prices, op, 1.5)
+		checkAppender[float64](t, NewGorilla[float64](), prices, op, 1.5)
+		checkAppender[int64](t, NewChimp[int64](), ints, op, 7)
+		checkAppender[int64](t, NewGorilla[int64](), ints, op, 7)
+		checkAppender[uint64](t, NewChimp[uint64](), uints, op, 3)
+		checkAppender[uint64](t, NewGorilla[uint64](), uints, op, 3)
+		checkAppender[int64](t, NewDeltaOfDelta[int64](), ints, op, 7)
+		checkAppender[uint64](t, NewDeltaOfDelta[uint64](), uints, op, 3)
+	}
+}
+
+func TestAppender_SnapshotAndContinue(t *testing.T) {
+
+	app := NewChimp[int64]().NewAppender(Delta, 0)
+	a := make([]int64, 0, 100)
+
+	for i := 0; i < 100; i++ {
+		v := int64(i*3) - 120
+		assert.Nil(t, app.Append(v))
+		a = append(a, v)
+
+		if i%25 == 0 {
+			buffer := &bytes.Buffer{}
+			assert.Nil(t, app.Snapshot(buffer))
+			assert.Equal(t, app.PackedSize(), uint64(buffer.Len()))
+
+			res := make([]int64, len(a))
+			numElements, err := NewChimp[int64]().Unpack(buffer, res, NOP, 0)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(len(a)), numElements)
+			assert.Equal(t, a, res)
+		}
+	}
+	assert.Equal(t, uint64(100), app.NumElements())
+
+	// Floats are not supported by DeltaOfDelta
+	assert.NotNil(t, NewDeltaOfDelta[float64]().NewAppender(NOP, 0).Append(1.5))
+	checkAppender[uint64](t, NewDeltaOfDelta[uint64](), nil, NOP, 0)
+}
+
+func checkAppender[T Number](
+	t *testing.T, p AppendablePacker[T], values []T, op PackOp, opParam T) {
+
+	packed := &bytes.Buffer{}
+	err := p.Pack(values, packed, op, opParam)
+	assert.Nil(t, err)
+
+	app := p.NewAppender(op, opParam)
+	for _, v := range values {
+		assert.Nil(t, app.Append(v))
+	}
+	snapshot := &bytes.Buffer{}
+	err = app.Snapshot(snapshot)
+	assert.Nil(t, err)
+
+	assert.Equal(t, packed.Bytes(), snapshot.Bytes())
+	assert.Equal(t, uint64(packed.Len()), app.PackedSize())
+	assert.Equal(t, uint64(len(values)), app.NumElements())
+}
@@ -0,0 +1,70 @@
+package packer
+
+import (
+	"github.com/dgryski/go-bitstream"
+)
+
+// Destination of the bits produced by the value encoders. Implemented by
+// bitstream.BitWriter and bitWriter.
+type bitSink interface {
+	WriteBit(bit bitstream.Bit) error
+	WriteBits(u uint64, nbits int) error
+}
+
+// In-memory bit writer producing the same MSB first layout as
+// bitstream.BitWriter. Unlike the latter, the partially written last byte is
+// part of the written bytes at all times, so the writer can be snapshot and
+// written to afterwards.
+type bitWriter struct {
+	buf   []byte
+	nbits uint64
+}
+
+// Write a single bit
+func (w *bitWriter) WriteBit(bit bitstream.Bit) error {
+	if bit {
+		return w.WriteBits(1, 1)
+	}
+	return w.WriteBits(0, 1)
+}
+
+// Write the nbits least significant bits of u, most significant bit first
+func (w *bitWriter) WriteBits(u uint64, nbits int) error {
+	for nbits > 0 {
+		free := 8 - int(w.nbits%8)
+		if free == 8 {
+			w.buf = append(w.buf, 0)
+		}
+		take := nbits
+		if take > free {
+			take = free
+		}
+		chunk := byte(u>>uint(nbits-take)) & byte(1<<uint(take)-1)
+		w.buf[len(w.buf)-1] |= chunk << uint(free-take)
+		nbits -= take
+		w.nbits += uint64(take)
+	}
+	return nil
+}
+
+// Append all bits written to the other writer
+func (w *bitWriter) append(other *bitWriter) {
+	if w.nbits%8 == 0 {
+		w.buf = append(w.buf, other.buf...)
+		w.nbits += other.nbits
+		return
+	}
+
+	full := other.nbits / 8
+	for _, b := range other.buf[:full] {
+		w.WriteBits(uint64(b), 8)
+	}
+	if rem := int(other.nbits % 8); rem > 0 {
+		w.WriteBits(uint64(other.buf[full]>>uint(8-rem)), rem)
+	}
+}
+
+// Return the written bytes. The unused bits of the last byte are zero.
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
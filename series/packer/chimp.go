@@ -22,13 +22,18 @@ import (
 )
 
 type Chimp[T Number] struct {
+	smallInts       bool
+	restartInterval uint64
+}
+
+// Encoding and decoding state of a single Chimp block
+type chimpState struct {
 	storedLeadingZeros  uint64
 	storedTrailingZeros uint64
 	storedVal           uint64
 	size                uint64
-	numElements         uint64
-	smallInts           bool
 	first               bool
+	wordBits            uint64
 }
 
 var threshold uint64 = 6
@@ -55,57 +60,152 @@ var leadingRound = [...]uint64{
 	24, 24, 24, 24, 24, 24, 24, 24,
 	24, 24, 24, 24, 24, 24, 24, 24}
 
+func init() {
+	mustRegister(CodecChimp, "chimp", func() Packer[int64] { return NewChimp[int64]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[uint64] { return NewChimp[uint64]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[float64] { return NewChimp[float64]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[int32] { return NewChimp[int32]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[uint32] { return NewChimp[uint32]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[float32] { return NewChimp[float32]() })
+	mustRegister(CodecChimp, "chimp", func() Packer[Decimal] { return NewChimp[Decimal]() })
+}
+
 func NewChimp[T Number]() *Chimp[T] {
 	return &Chimp[T]{
-		storedLeadingZeros:  math.MaxInt32,
-		storedTrailingZeros: 0,
-		storedVal:           0,
-		size:                0,
-		numElements:         0,
-		smallInts:           true,
-		first:               true,
+		smallInts:       true,
+		restartInterval: 0,
 	}
 }
 
-// Packs the float64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the data in the src slice to the dst buffer and returns nil if packing
+// was completed successfuly. Otherwise, returns the error. The packed data is
+// preceded by a block header, making dst decodable by any Chimp instance.
 func (chimp *Chimp[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if chimp.restartInterval > 0 {
+		app := newXorAppender[T](CodecChimp, newChimpState(), chimp.restartInterval, op, opParam)
+		start := dst.Len()
+		if err := packXorSegments(app, src, dst); err != nil {
+			dst.Truncate(start)
+			return err
+		}
+		return nil
+	}
+
+	start := reserveHeader(dst)
+	state := newChimpState()
+	state.setWordBits(xorWordBits(elemTypeOf[T](), chimp.smallInts))
+
+	var err error
 	switch any(opParam).(type) {
-	case int64:
-		return chimp.packInt(src, dst, op, opParam)
-	case uint64:
-		return chimp.packUInt(src, dst, op, opParam)
+	case int64, int32, Decimal:
+		err = chimp.packInt(state, src, dst, op, opParam)
+	case uint64, uint32, float32:
+		err = chimp.packUInt(state, src, dst, op, opParam)
 	case float64:
-		return chimp.packFloat(src, dst, op, opParam)
+		err = chimp.packFloat(state, src, dst, op, opParam)
+	default:
+		err = errors.New("unsupported type in pack")
 	}
 
-	return errors.New("unsupported type in pack")
+	if err != nil {
+		dst.Truncate(start)
+		return err
+	}
+
+	var flags uint8 = 0
+	if chimp.smallInts {
+		flags |= FlagSmallInts
+	}
+	writeHeader(dst, start, &Header{
+		Codec:       CodecChimp,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		Flags:       flags,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     state.size,
+	})
+
+	return nil
 }
 
-// Unpacks the float64 data in the src buffer to the dst slice and returns
-// number of elements unpacked along with nil error. Otherwise, returns (0,
-// error).
+// Unpacks the data in the src buffer to the dst slice and returns number of
+// elements unpacked along with nil error. Otherwise, returns (0, error). The
+// number of elements, op and opParam are read from the block header, which
+// takes precedence over the specified op and opParam. The src buffer is not
+// consumed.
 func (chimp *Chimp[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecChimp)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	smallInts := hdr.HasFlag(FlagSmallInts)
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+	in := bytes.NewBuffer(payload)
+
+	if hdr.HasFlag(FlagIndex) {
+		return unpackXorSegments[T](newChimpState(), &hdr, payload, dst)
+	}
+
+	state := newChimpState()
+	state.setWordBits(xorWordBits(hdr.ElemType, smallInts))
+
+	var numElements uint64 = 0
 	switch any(opParam).(type) {
-	case int64:
-		return chimp.unpackInt(src, dst, op, opParam)
-	case uint64:
-		return chimp.unpackUInt(src, dst, op, opParam)
+	case int64, int32, Decimal:
+		numElements, err = chimp.unpackInt(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
+	case uint64, uint32, float32:
+		numElements, err = chimp.unpackUInt(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
 	case float64:
-		return chimp.unpackFloat(src, dst, op, opParam)
+		numElements, err = chimp.unpackFloat(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
+	default:
+		err = errors.New("unsupported type in unpack")
 	}
 
-	return 0, errors.New("unsupported type in unpack")
+	return numElements, err
+}
+
+// Create an appender encoding values with the Chimp codec
+func (chimp *Chimp[T]) NewAppender(op PackOp, opParam T) Appender[T] {
+	return newXorAppender[T](CodecChimp, newChimpState(), chimp.restartInterval, op, opParam)
+}
+
+// Return a copy of the packer writing restart points every interval values,
+// in Pack and in the appenders it creates. Zero disables restart points.
+func (chimp *Chimp[T]) WithRestartInterval(interval uint64) Seeker[T] {
+	p := *chimp
+	p.restartInterval = interval
+	return &p
+}
+
+// Unpacks the segment of the block in src holding the value at the specified
+// index into dst, and returns the index of the first value of the segment and
+// the number of unpacked values along with nil error. Otherwise, returns (0,
+// 0, error).
+func (chimp *Chimp[T]) UnpackSegment(src *bytes.Buffer, index uint64, dst []T) (uint64, uint64, error) {
+	return unpackXorSegmentAt[T](newChimpState(), CodecChimp, src, index, dst,
+		func(src *bytes.Buffer, dst []T) (uint64, error) {
+			return chimp.Unpack(src, dst, NOP, 0)
+		})
 }
 
-// Return the size of the packed data
-func (chimp *Chimp[T]) PackedSize() uint64 {
-	return (chimp.size + 7) / 8
+// Create a decoder of the block at the start of the src buffer, decoding the
+// values incrementally (see Decoder).
+func (chimp *Chimp[T]) NewDecoder(src *bytes.Buffer) (Decoder[T], error) {
+	return newXorDecoder[T](newChimpState(), CodecChimp, src)
 }
 
-// Reutrn the umber of elements in the frame
-func (chimp *Chimp[T]) NumElements() uint64 {
-	return chimp.numElements
+// Return the id of the codec
+func (chimp *Chimp[T]) Codec() CodecID {
+	return CodecChimp
 }
 
 //-----------------------------------------------------------------------------
@@ -115,43 +215,31 @@ func (chimp *Chimp[T]) NumElements() uint64 {
 // Packs the float64 data in the src slice to the dst buffer and returns the buffer
 // Otherwise, returns (nil, error).
 func (chimp *Chimp[T]) packFloat(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
-	chimp.numElements = 0
+	state *chimpState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 
 	bitStream := bitstream.NewWriter(dst)
 	for ndx := range src {
 		val := T(src[ndx])
 		switch op {
 		case NOP:
-			chimp.addUIntValue(bitStream, math.Float64bits(float64(val)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val)))
 		case Offset:
-			chimp.addUIntValue(bitStream, math.Float64bits(float64(val+opParam)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val+opParam)))
 		case Delta:
-			chimp.addUIntValue(bitStream, math.Float64bits(float64(val-opParam)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val-opParam)))
 			opParam = val
 		}
-		chimp.numElements++
 	}
 	bitStream.Flush(false)
 
 	return nil
 }
 
-// Packs the uint64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the int64 or int32 data in the src slice to the dst buffer and
+// returns nil if packing was completed successfuly. Otherwise, returns the
+// error.
 func (chimp *Chimp[T]) packInt(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
-	chimp.numElements = 0
+	state *chimpState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 
 	bitStream := bitstream.NewWriter(dst)
 
@@ -163,7 +251,7 @@ func (chimp *Chimp[T]) packInt(
 			bitStream.WriteBit(false)
 		}
 	}
-	chimp.size += uint64(len(src))
+	state.size += uint64(len(src))
 
 	for ndx := range src {
 		val := src[ndx]
@@ -172,68 +260,61 @@ func (chimp *Chimp[T]) packInt(
 		}
 		switch op {
 		case NOP:
-			uVal := uint64(val)
+			uVal := toWord(val)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Offset:
-			uVal := uint64(val + opParam)
+			uVal := toWord(val + opParam)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Delta:
-			uVal := uint64(val - opParam)
+			uVal := toWord(val - opParam)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 			opParam = val
 		}
-		chimp.numElements++
 	}
 	bitStream.Flush(false)
 
 	return nil
 }
 
-// Packs the uint64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the uint64, uint32 or float32 data in the src slice to the dst buffer
+// and returns nil if packing was completed successfuly. Otherwise, returns the
+// error. Values are packed as words of their raw bits (see toWord).
 func (chimp *Chimp[T]) packUInt(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
-	chimp.numElements = 0
+	state *chimpState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 
 	bitStream := bitstream.NewWriter(dst)
 	for ndx := range src {
 		val := src[ndx]
 		switch op {
 		case NOP:
-			uVal := uint64(val)
+			uVal := toWord(val)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Offset:
-			uVal := uint64(val + opParam)
+			uVal := toWord(val + opParam)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Delta:
-			uVal := uint64(val - opParam)
+			uVal := toWord(val - opParam)
 			if chimp.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			chimp.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 			opParam = val
 		}
-		chimp.numElements++
 	}
 	bitStream.Flush(false)
 
@@ -244,26 +325,23 @@ func (chimp *Chimp[T]) packUInt(
 // number of float64 elements that was unpacked. Otherwise, returns (0,
 // error).
 func (chimp *Chimp[T]) unpackFloat(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedTrailingZeros = 0
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
+	state *chimpState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
 	var readElements uint64 = 0
-	for readElements < chimp.numElements {
-		chimp.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			dst[readElements] = T(math.Float64frombits(chimp.storedVal))
+			dst[readElements] = T(math.Float64frombits(state.storedVal))
 		case Offset:
-			dst[readElements] = T(math.Float64frombits(chimp.storedVal)) - opParam
+			dst[readElements] = T(math.Float64frombits(state.storedVal)) - opParam
 		case Delta:
-			dst[readElements] = T(math.Float64frombits(chimp.storedVal)) + opParam
+			dst[readElements] = T(math.Float64frombits(state.storedVal)) + opParam
 			opParam = dst[readElements]
 		}
 		readElements++
@@ -272,24 +350,22 @@ func (chimp *Chimp[T]) unpackFloat(
 	return readElements, nil
 }
 
-// Unpacks the int64 data in the src buffer to the dst slice and returns the
-// number of int64 elements that was unpacked. Otherwise, returns (0,
+// Unpacks the int64 or int32 data in the src buffer to the dst slice and
+// returns the number of elements that was unpacked. Otherwise, returns (0,
 // error).
 func (chimp *Chimp[T]) unpackInt(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedTrailingZeros = 0
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
+	state *chimpState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
-	negInd := make([]int64, chimp.NumElements())
+	negInd := make([]int64, numElements)
 	var ndx uint64 = 0
-	for ndx < chimp.NumElements() {
-		var bits, _ = bitStream.ReadBits(1)
+	for ndx < numElements {
+		var bits, err = bitStream.ReadBits(1)
+		if err != nil {
+			return 0, streamError(err)
+		}
 		v := int64(bits)
 		if v == 1 {
 			negInd[ndx] = -1
@@ -300,28 +376,30 @@ func (chimp *Chimp[T]) unpackInt(
 	}
 
 	var readElements uint64 = 0
-	for readElements < chimp.numElements {
-		chimp.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal)
+			dst[readElements] = fromBits[T](uVal)
 		case Offset:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) - opParam
+			dst[readElements] = fromBits[T](uVal) - opParam
 
 		case Delta:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) + opParam
+			dst[readElements] = fromBits[T](uVal) + opParam
 			opParam = dst[readElements]
 		}
 		dst[readElements] *= T(negInd[readElements])
@@ -331,42 +409,39 @@ func (chimp *Chimp[T]) unpackInt(
 	return readElements, nil
 }
 
-// Unpacks the float64 data in the src buffer to the dst slice and returns the
-// number of float64 elements that was unpacked. Otherwise, returns (0,
-// error).
+// Unpacks the uint64, uint32 or float32 data in the src buffer to the dst
+// slice and returns the number of elements that was unpacked. Otherwise,
+// returns (0, error).
 func (chimp *Chimp[T]) unpackUInt(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	chimp.storedLeadingZeros = math.MaxInt64
-	chimp.storedTrailingZeros = 0
-	chimp.storedVal = 0
-	chimp.first = true
-	chimp.size = 0
+	state *chimpState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
 	var readElements uint64 = 0
-	for readElements < chimp.numElements {
-		chimp.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal)
+			dst[readElements] = fromBits[T](uVal)
 		case Offset:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) - opParam
+			dst[readElements] = fromBits[T](uVal) - opParam
 		case Delta:
-			uVal := chimp.storedVal
-			if chimp.smallInts {
+			uVal := state.storedVal
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) + opParam
+			dst[readElements] = fromBits[T](uVal) + opParam
 			opParam = dst[readElements]
 		}
 		readElements++
@@ -375,28 +450,54 @@ func (chimp *Chimp[T]) unpackUInt(
 	return readElements, nil
 }
 
-func (chimp *Chimp[T]) addUIntValue(bitStream *bitstream.BitWriter, value uint64) {
-	if chimp.first {
-		chimp.writeFirst(bitStream, value)
+// Create the state of a Chimp encoder and decoder
+func newChimpState() *chimpState {
+	state := &chimpState{wordBits: 64}
+	state.reset()
+	return state
+}
+
+// Reset the state of the encoder and decoder
+func (state *chimpState) reset() {
+	state.storedLeadingZeros = math.MaxInt64
+	state.storedTrailingZeros = 0
+	state.storedVal = 0
+	state.first = true
+}
+
+// Return the last encoded or decoded value
+func (state *chimpState) lastValue() uint64 {
+	return state.storedVal
+}
+
+// Set the width of the encoded words. Words of 32 bits are held in the high
+// half of the values.
+func (state *chimpState) setWordBits(wordBits uint64) {
+	state.wordBits = wordBits
+}
+
+func (state *chimpState) addUIntValue(bitStream bitSink, value uint64) {
+	if state.first {
+		state.writeFirst(bitStream, value)
 	} else {
-		chimp.compressValue(bitStream, value)
+		state.compressValue(bitStream, value)
 	}
 }
 
-func (chimp *Chimp[T]) writeFirst(bitStream *bitstream.BitWriter, value uint64) {
-	chimp.first = false
-	chimp.storedVal = value
-	bitStream.WriteBits(value, 64)
-	chimp.size += 64
+func (state *chimpState) writeFirst(bitStream bitSink, value uint64) {
+	state.first = false
+	state.storedVal = value
+	bitStream.WriteBits(value>>(64-state.wordBits), int(state.wordBits))
+	state.size += state.wordBits
 }
 
-func (chimp *Chimp[T]) compressValue(bitStream *bitstream.BitWriter, value uint64) {
-	var xor uint64 = chimp.storedVal ^ value
+func (state *chimpState) compressValue(bitStream bitSink, value uint64) {
+	var xor uint64 = state.storedVal ^ value
 	if xor == 0 {
 		// Write 0
 		bitStream.WriteBits(uint64(0), 2)
-		chimp.size += 2
-		chimp.storedLeadingZeros = 65
+		state.size += 2
+		state.storedLeadingZeros = 65
 	} else {
 		var leadingZeros uint64 = uint64(leadingRound[bits.LeadingZeros64(xor)])
 		var trailingZeros uint64 = uint64(bits.TrailingZeros64(xor))
@@ -405,41 +506,41 @@ func (chimp *Chimp[T]) compressValue(bitStream *bitstream.BitWriter, value uint6
 			var significantBits uint64 = 64 - leadingZeros - trailingZeros
 			bitStream.WriteBits(uint64(1), 2)
 			bitStream.WriteBits(leadingRepresentation[leadingZeros], 3)
-			bitStream.WriteBits(significantBits, 6)
+			bitStream.WriteBits(significantBits%state.wordBits, state.lengthBits())
 			bitStream.WriteBits(xor>>trailingZeros, int(significantBits))
-			chimp.size += 11 + significantBits
-			chimp.storedLeadingZeros = 65 //leadingRepresentation[leadingZeros]
-		} else if leadingZeros == chimp.storedLeadingZeros {
+			state.size += 5 + uint64(state.lengthBits()) + significantBits
+			state.storedLeadingZeros = 65 //leadingRepresentation[leadingZeros]
+		} else if leadingZeros == state.storedLeadingZeros {
 			bitStream.WriteBits(uint64(2), 2)
 			var significantBits uint64 = 64 - leadingZeros
 			bitStream.WriteBits(xor, int(significantBits))
-			chimp.size += 2 + significantBits
+			state.size += 2 + significantBits
 		} else {
-			chimp.storedLeadingZeros = leadingZeros
+			state.storedLeadingZeros = leadingZeros
 			var significantBits uint64 = 64 - leadingZeros
 			bitStream.WriteBits(uint64(3), 2)
 			bitStream.WriteBits(leadingRepresentation[leadingZeros], 3)
 			bitStream.WriteBits(xor, int(significantBits))
-			chimp.size += 5 + significantBits
+			state.size += 5 + significantBits
 		}
 	}
-	chimp.storedVal = value
+	state.storedVal = value
 }
 
-func (chimp *Chimp[T]) next(bitStream *bitstream.BitReader) error {
-	if chimp.first {
-		chimp.first = false
-		var val, err = bitStream.ReadBits(64)
+func (state *chimpState) next(bitStream *bitstream.BitReader) error {
+	if state.first {
+		state.first = false
+		var val, err = bitStream.ReadBits(int(state.wordBits))
 		if err != nil {
 			return err
 		}
-		chimp.storedVal = val
+		state.storedVal = val << (64 - state.wordBits)
 		return nil
 	}
-	return chimp.nextValue(bitStream)
+	return state.nextValue(bitStream)
 }
 
-func (chimp *Chimp[T]) nextValue(bitStream *bitstream.BitReader) error {
+func (state *chimpState) nextValue(bitStream *bitstream.BitReader) error {
 
 	var significantBits uint64 = 0
 	var value uint64 = 0
@@ -453,36 +554,60 @@ func (chimp *Chimp[T]) nextValue(bitStream *bitstream.BitReader) error {
 	switch flag {
 	case 3:
 		// New leading zeros
-		var bits, _ = bitStream.ReadBits(3)
-		chimp.storedLeadingZeros = leadingRepresentationUnpack[bits]
-		significantBits = 64 - chimp.storedLeadingZeros
-		if significantBits == 0 {
-			significantBits = 64
+		bits, err := bitStream.ReadBits(3)
+		if err != nil {
+			return err
 		}
-		value, _ = bitStream.ReadBits(64 - int(chimp.storedLeadingZeros))
-		value = chimp.storedVal ^ value
-		chimp.storedVal = value
+		state.storedLeadingZeros = leadingRepresentationUnpack[bits]
+		value, err = bitStream.ReadBits(64 - int(state.storedLeadingZeros))
+		if err != nil {
+			return err
+		}
+		value = state.storedVal ^ value
+		state.storedVal = value
 	case 2:
-		significantBits = 64 - chimp.storedLeadingZeros
-		if significantBits == 0 {
-			significantBits = 64
+		if state.storedLeadingZeros > 64 {
+			return ErrCorrupt
+		}
+		value, err = bitStream.ReadBits(64 - int(state.storedLeadingZeros))
+		if err != nil {
+			return err
 		}
-		value, _ = bitStream.ReadBits(64 - int(chimp.storedLeadingZeros))
-		value = chimp.storedVal ^ value
-		chimp.storedVal = value
+		value = state.storedVal ^ value
+		state.storedVal = value
 	case 1:
-		var bits, _ = bitStream.ReadBits(3)
-		chimp.storedLeadingZeros = leadingRepresentationUnpack[bits]
-		significantBits, _ = bitStream.ReadBits(6)
+		bits, err := bitStream.ReadBits(3)
+		if err != nil {
+			return err
+		}
+		state.storedLeadingZeros = leadingRepresentationUnpack[bits]
+		significantBits, err = bitStream.ReadBits(state.lengthBits())
+		if err != nil {
+			return err
+		}
 		if significantBits == 0 {
-			significantBits = 64
+			significantBits = state.wordBits
+		}
+		if significantBits+state.storedLeadingZeros > 64 {
+			return ErrCorrupt
+		}
+		state.storedTrailingZeros = 64 - significantBits - state.storedLeadingZeros
+		value, err = bitStream.ReadBits(64 - int(state.storedLeadingZeros+state.storedTrailingZeros))
+		if err != nil {
+			return err
 		}
-		chimp.storedTrailingZeros = 64 - significantBits - chimp.storedLeadingZeros
-		value, _ = bitStream.ReadBits(64 - int(chimp.storedLeadingZeros+chimp.storedTrailingZeros))
-		value <<= chimp.storedTrailingZeros
-		value = chimp.storedVal ^ value
-		chimp.storedVal = value
+		value <<= state.storedTrailingZeros
+		value = state.storedVal ^ value
+		state.storedVal = value
 	}
 
 	return nil
 }
+
+// Return the width of the length of the significant bits
+func (state *chimpState) lengthBits() int {
+	if state.wordBits == 32 {
+		return 5
+	}
+	return 6
+}
@@ -0,0 +1,308 @@
+package packer
+
+// Chimp128 variant of the Chimp compression algo presented in the VLDB 2022
+// paper. Instead of XORing every value with the single previous value, the
+// encoder keeps a ring buffer of the last 128 values and XORs with the one
+// that shares the most trailing bits with the value. Candidates are found
+// through an index on the 14 least significant bits of the values, so
+// repeated values and values of repetitive patterns are encoded as a flag and
+// a 7-bit reference into the ring buffer.
+//
+// Integers are encoded like in Chimp with their 32-bit halves swapped, so that
+// small integers end up with trailing zeros.
+//
+// Ref: Panagiotis Liakos, Katia Papakonstantinopoulou, and Yannis Kotidis.
+//      Chimp: Efficient Lossless Floating Point Compression for Time Series
+//      Databases. PVLDB, 15(11): 3058 - 3070, 2022
+// Paper Link: https://www.vldb.org/pvldb/vol15/p3058-liakos.pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type Chimp128[T Number] struct{}
+
+const (
+	chimp128Previous  = 128
+	chimp128IndexBits = 7
+	chimp128KeyBits   = 14
+	chimp128KeyMask   = (1 << chimp128KeyBits) - 1
+	chimp128Threshold = 6 + chimp128IndexBits
+)
+
+// Encoder and decoder state of a single pack or unpack call
+type chimp128State struct {
+	storedValues       [chimp128Previous]uint64
+	indices            []uint64
+	storedLeadingZeros uint64
+	index              uint64
+	size               uint64
+}
+
+func init() {
+	mustRegister(CodecChimp128, "chimp128", func() Packer[int64] { return NewChimp128[int64]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[uint64] { return NewChimp128[uint64]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[float64] { return NewChimp128[float64]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[int32] { return NewChimp128[int32]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[uint32] { return NewChimp128[uint32]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[float32] { return NewChimp128[float32]() })
+	mustRegister(CodecChimp128, "chimp128", func() Packer[Decimal] { return NewChimp128[Decimal]() })
+}
+
+func NewChimp128[T Number]() *Chimp128[T] {
+	return &Chimp128[T]{}
+}
+
+// Packs the data in the src slice to the dst buffer and returns nil if packing
+// was completed successfuly. Otherwise, returns the error.
+func (chimp *Chimp128[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	elemType := elemTypeOf[T]()
+	if elemType == ElemUnknown {
+		return errors.New("unsupported type in pack")
+	}
+	smallInts := elemType != ElemFloat64
+
+	start := reserveHeader(dst)
+
+	state := newChimp128State(true)
+	prev := opParam
+	bitStream := bitstream.NewWriter(dst)
+	for ndx := range src {
+		val := src[ndx]
+		switch op {
+		case NOP:
+		case Offset:
+			val += opParam
+		case Delta:
+			val, prev = val-prev, val
+		}
+
+		uVal := toWord(val)
+		if smallInts {
+			uVal = (uVal << 32) | (uVal >> 32)
+		}
+		state.write(bitStream, uVal)
+	}
+	bitStream.Flush(false)
+
+	var flags uint8 = 0
+	if smallInts {
+		flags |= FlagSmallInts
+	}
+	writeHeader(dst, start, &Header{
+		Codec:       CodecChimp128,
+		ElemType:    elemType,
+		Op:          op,
+		Flags:       flags,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     state.size,
+	})
+
+	return nil
+}
+
+// Unpacks the data in the src buffer to the dst slice and returns number of
+// elements unpacked along with nil error. Otherwise, returns (0, error). The
+// block header in src takes precedence over op and opParam.
+func (chimp *Chimp128[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecChimp128)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+	smallInts := hdr.HasFlag(FlagSmallInts)
+
+	state := newChimp128State(false)
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+
+	var readElements uint64 = 0
+	for readElements < hdr.NumElements {
+		uVal, err := state.read(bitStream)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		if smallInts {
+			uVal = (uVal << 32) | (uVal >> 32)
+		}
+
+		switch op {
+		case NOP:
+			dst[readElements] = fromBits[T](uVal)
+		case Offset:
+			dst[readElements] = fromBits[T](uVal) - opParam
+		case Delta:
+			dst[readElements] = fromBits[T](uVal) + opParam
+			opParam = dst[readElements]
+		}
+		readElements++
+	}
+
+	return readElements, nil
+}
+
+// Return the id of the codec
+func (chimp *Chimp128[T]) Codec() CodecID {
+	return CodecChimp128
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func newChimp128State(encoding bool) *chimp128State {
+	state := &chimp128State{storedLeadingZeros: 65}
+	if encoding {
+		state.indices = make([]uint64, 1<<chimp128KeyBits)
+	}
+	return state
+}
+
+func (state *chimp128State) write(bitStream *bitstream.BitWriter, value uint64) {
+	if state.index == 0 {
+		bitStream.WriteBits(value, 64)
+		state.size += 64
+		state.store(value)
+		return
+	}
+
+	// XOR with the previous value, unless a value within the ring buffer
+	// shares enough trailing bits with the value.
+	key := value & chimp128KeyMask
+	refIndex := (state.index - 1) % chimp128Previous
+	xor := state.storedValues[refIndex] ^ value
+	var trailingZeros uint64 = 0
+	if candidate := state.indices[key]; candidate != 0 && state.index-(candidate-1) <= chimp128Previous {
+		candidateIndex := (candidate - 1) % chimp128Previous
+		candidateXor := state.storedValues[candidateIndex] ^ value
+		candidateTrailingZeros := uint64(bits.TrailingZeros64(candidateXor))
+		if candidateTrailingZeros > chimp128Threshold {
+			refIndex = candidateIndex
+			xor = candidateXor
+			trailingZeros = candidateTrailingZeros
+		}
+	}
+
+	if xor == 0 {
+		// Write 00 followed by the index
+		bitStream.WriteBits(refIndex, 2+chimp128IndexBits)
+		state.size += 2 + chimp128IndexBits
+		state.storedLeadingZeros = 65
+	} else {
+		var leadingZeros uint64 = leadingRound[bits.LeadingZeros64(xor)]
+
+		if trailingZeros > chimp128Threshold {
+			var significantBits uint64 = 64 - leadingZeros - trailingZeros
+			bitStream.WriteBits(uint64(1), 2)
+			bitStream.WriteBits(refIndex, chimp128IndexBits)
+			bitStream.WriteBits(leadingRepresentation[leadingZeros], 3)
+			bitStream.WriteBits(significantBits, 6)
+			bitStream.WriteBits(xor>>trailingZeros, int(significantBits))
+			state.size += 2 + chimp128IndexBits + 9 + significantBits
+			state.storedLeadingZeros = 65
+		} else if leadingZeros == state.storedLeadingZeros {
+			var significantBits uint64 = 64 - leadingZeros
+			bitStream.WriteBits(uint64(2), 2)
+			bitStream.WriteBits(xor, int(significantBits))
+			state.size += 2 + significantBits
+		} else {
+			state.storedLeadingZeros = leadingZeros
+			var significantBits uint64 = 64 - leadingZeros
+			bitStream.WriteBits(uint64(3), 2)
+			bitStream.WriteBits(leadingRepresentation[leadingZeros], 3)
+			bitStream.WriteBits(xor, int(significantBits))
+			state.size += 5 + significantBits
+		}
+	}
+
+	state.store(value)
+}
+
+func (state *chimp128State) read(bitStream *bitstream.BitReader) (uint64, error) {
+	if state.index == 0 {
+		value, err := bitStream.ReadBits(64)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		state.store(value)
+		return value, nil
+	}
+
+	flag, err := bitStream.ReadBits(2)
+	if err != nil {
+		return 0, streamError(err)
+	}
+
+	var value uint64 = 0
+	switch flag {
+	case 0:
+		refIndex, err := bitStream.ReadBits(chimp128IndexBits)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		value = state.storedValues[refIndex]
+		state.storedLeadingZeros = 65
+	case 1:
+		refIndex, err := bitStream.ReadBits(chimp128IndexBits)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		lead, err := bitStream.ReadBits(3)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		significantBits, err := bitStream.ReadBits(6)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		leadingZeros := leadingRepresentationUnpack[lead]
+		if leadingZeros+significantBits > 64 {
+			return 0, fmt.Errorf("%w: invalid significant bits in unpack", ErrCorrupt)
+		}
+		trailingZeros := 64 - leadingZeros - significantBits
+		xor, err := bitStream.ReadBits(int(significantBits))
+		if err != nil {
+			return 0, streamError(err)
+		}
+		value = state.storedValues[refIndex] ^ (xor << trailingZeros)
+		state.storedLeadingZeros = 65
+	case 2, 3:
+		if flag == 3 {
+			lead, err := bitStream.ReadBits(3)
+			if err != nil {
+				return 0, streamError(err)
+			}
+			state.storedLeadingZeros = leadingRepresentationUnpack[lead]
+		}
+		if state.storedLeadingZeros > 64 {
+			return 0, fmt.Errorf("%w: invalid leading zeros in unpack", ErrCorrupt)
+		}
+		xor, err := bitStream.ReadBits(int(64 - state.storedLeadingZeros))
+		if err != nil {
+			return 0, streamError(err)
+		}
+		value = state.storedValues[(state.index-1)%chimp128Previous] ^ xor
+	}
+
+	state.store(value)
+	return value, nil
+}
+
+// Stores the value in the ring buffer of previous values
+func (state *chimp128State) store(value uint64) {
+	state.storedValues[state.index%chimp128Previous] = value
+	if state.indices != nil {
+		state.indices[value&chimp128KeyMask] = state.index + 1
+	}
+	state.index++
+}
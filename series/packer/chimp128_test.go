@@ -0,0 +1,207 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChimp128_Float64_ValueCheck(t *testing.T) {
+
+	a := make([]float64, 1000)
+	for i := range a {
+		a[i] = math.Sin(float64(i%50)/10) * 100
+	}
+	a[10] = math.NaN()
+	a[11] = math.Inf(-1)
+
+	chimp := NewChimp128[float64]()
+	buffer := &bytes.Buffer{}
+
+	err := chimp.Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	res := make([]float64, len(a))
+	numElements, err := NewChimp128[float64]().Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	for i := range a {
+		assert.Equal(t, math.Float64bits(a[i]), math.Float64bits(res[i]))
+	}
+}
+
+func TestChimp128_Int64_ValueCheck(t *testing.T) {
+
+	a := []int64{-5, 100, -100000, math.MinInt64, math.MaxInt64, 0, 7, 7, 7, 100}
+	res := make([]int64, len(a))
+
+	chimp := NewChimp128[int64]()
+	buffer := &bytes.Buffer{}
+
+	err := chimp.Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	_, err = chimp.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, res)
+}
+
+func TestChimp128_UInt64_PackOps(t *testing.T) {
+
+	a := make([]uint64, 1000)
+	for i := range a {
+		a[i] = 1000 + uint64((i*i)%317)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+
+		err := NewChimp128[uint64]().Pack(a, buffer, op, 5)
+		assert.Nil(t, err)
+
+		res := make([]uint64, len(a))
+		_, err = NewChimp128[uint64]().Unpack(buffer, res, NOP, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, a, res, op.String())
+	}
+}
+
+// Tests the memory impact of storing a const (value of 1.0) series of size 10
+func TestChimp128_Float64_CompressionCheckForConst(t *testing.T) {
+
+	a := make([]float64, 10)
+	for i := range a {
+		a[i] = 1.0
+	}
+
+	chimp := NewChimp128[float64]()
+	buffer := &bytes.Buffer{}
+
+	chimp.Pack(a, buffer, NOP, 0.0)
+	assert.Equal(t, HeaderSize+19+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(64+9*9), packedBits(buffer))       // Num bits
+}
+
+// Values repeating with a period shorter than the ring buffer are encoded as
+// a reference to the previous occurence and should pack far better than with
+// Chimp
+func TestChimp128_Float64_CompressionCheckForRepetitiveSequence(t *testing.T) {
+
+	a := make([]float64, 10000)
+	for i := range a {
+		a[i] = math.Sin(float64(i%100)/10) * 100
+	}
+
+	chimp128 := NewChimp128[float64]()
+	buffer128 := &bytes.Buffer{}
+	chimp128.Pack(a, buffer128, NOP, 0.0)
+
+	chimp := NewChimp[float64]()
+	buffer := &bytes.Buffer{}
+	chimp.Pack(a, buffer, NOP, 0.0)
+
+	assert.Less(t, 4*buffer128.Len(), buffer.Len())
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// constant float64 (1999.9999). So a single op in the "ns/op" refers to
+// handling 1 million float64s
+func BenchmarkChimp128For_Float64_PackingConst(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	for i := range a {
+		a[i] = 1999.9999
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		chimp := NewChimp128[float64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		chimp.Pack(a, buffer, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will pack 1 million
+// constant float64 (1999.9999). So a single op in the "ns/op" refers to
+// handling 1 million float64s
+func BenchmarkChimp128For_Float64_UnpackingConst(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	res := make([]float64, 1000000)
+	for i := range a {
+		a[i] = 1999.9999
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		chimp := NewChimp128[float64]()
+		buffer := &bytes.Buffer{}
+		chimp.Pack(a, buffer, NOP, 0.0)
+
+		t.StartTimer()
+		chimp.Unpack(buffer, res, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// floats that are monotonically increasing by 1.0. So a single op in the
+// "ns/op" refers to handling 1 million float64s
+func BenchmarkChimp128For_Float64_PackingSequence(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	for i := range a {
+		a[i] = float64(i) + 10000
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		chimp := NewChimp128[float64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		chimp.Pack(a, buffer, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will unpack 1 million
+// floats that are monotonically increasing by 1.0. So a single op in the
+// "ns/op" refers to handling 1 million float64s
+func BenchmarkChimp128For_Float64_UnpackingSequence(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	res := make([]float64, 1000000)
+	for i := range a {
+		a[i] = float64(i) + 10000
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		chimp := NewChimp128[float64]()
+		buffer := &bytes.Buffer{}
+		chimp.Pack(a, buffer, NOP, 0.0)
+
+		t.StartTimer()
+		chimp.Unpack(buffer, res, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+func BenchmarkChimp128For_StockPrice(t *testing.B) {
+
+	prices := stockPrices()
+
+	chimp := NewChimp128[float64]()
+	buffer := &bytes.Buffer{}
+	buffer.Grow(50000000)
+	t.StartTimer()
+	chimp.Pack(prices, buffer, NOP, 0.0)
+	t.StopTimer()
+	println("Num bytes: ", buffer.Len())
+}
@@ -71,8 +71,8 @@ func TestChimp_Float64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 11, buffer.Len())       // Num bytes
-	assert.Equal(t, uint64(82), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+11+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(82), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -88,8 +88,8 @@ func TestChimp_Float64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 26, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(208), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+26+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(208), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -105,8 +105,8 @@ func TestChimp_Float64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 13, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(103), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+13+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(103), packedBits(buffer))          // Num bits
 
 	res := make([]float64, 10)
 	chimp.Unpack(buffer, res, Delta, 0.0)
@@ -129,8 +129,8 @@ func TestChimp_Float64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Offset, -9.0)
-	assert.Equal(t, 26, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(208), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+26+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(208), packedBits(buffer))          // Num bits
 
 	res := make([]float64, 10)
 	chimp.Unpack(buffer, res, Offset, -9.0)
@@ -153,8 +153,8 @@ func TestChimp_Float64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 34, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(271), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+34+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(271), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -325,8 +325,8 @@ func TestChimp_Int64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 12, buffer.Len())       // Num bytes
-	assert.Equal(t, uint64(92), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+12+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(92), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -342,8 +342,8 @@ func TestChimp_Int64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 31, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(245), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+31+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(245), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -359,8 +359,8 @@ func TestChimp_Int64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 14, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(109), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+14+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(109), packedBits(buffer))          // Num bits
 
 	res := make([]int64, 10)
 	chimp.Unpack(buffer, res, Delta, 0.0)
@@ -383,8 +383,8 @@ func TestChimp_Int64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Offset, -9.0)
-	assert.Equal(t, 31, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(245), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+31+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(245), packedBits(buffer))          // Num bits
 
 	res := make([]int64, 10)
 	chimp.Unpack(buffer, res, Offset, -9.0)
@@ -407,8 +407,8 @@ func TestChimp_Int64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 31, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(245), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+31+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(245), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -579,8 +579,8 @@ func TestChimp_Unt64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 11, buffer.Len())       // Num bytes
-	assert.Equal(t, uint64(82), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+11+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(82), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -596,8 +596,8 @@ func TestChimp_UInt64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 30, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(235), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+30+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(235), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -613,8 +613,8 @@ func TestChimp_UInt64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 13, buffer.Len())       // Num bytes
-	assert.Equal(t, uint64(99), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+13+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(99), packedBits(buffer))           // Num bits
 
 	res := make([]uint64, 10)
 	chimp.Unpack(buffer, res, Delta, 0.0)
@@ -637,8 +637,8 @@ func TestChimp_UInt64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, Offset, 9)
-	assert.Equal(t, 30, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(235), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+30+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(235), packedBits(buffer))          // Num bits
 
 	res := make([]uint64, 10)
 	chimp.Unpack(buffer, res, Offset, 9.0)
@@ -661,8 +661,8 @@ func TestChimp_UInt64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	chimp.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 30, buffer.Len())        // Num bytes
-	assert.Equal(t, uint64(235), chimp.size) // Num bits
+	assert.Equal(t, HeaderSize+30+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(235), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -771,10 +771,7 @@ func BenchmarkChimpFor_Unt64_UnpackingSequence(t *testing.B) {
 
 func BenchmarkChimpFor_StockPrice(t *testing.B) {
 
-	prices := ReadStockPriceFile()
-	if prices == nil {
-		assert.FailNow(t, "Failed to read stock price data")
-	}
+	prices := stockPrices()
 
 	chimp := NewChimp[float64]()
 	buffer := &bytes.Buffer{}
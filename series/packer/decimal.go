@@ -0,0 +1,189 @@
+package packer
+
+// Fixed-point decimal numbers. A Decimal holds the integer mantissa of a
+// number whose value is the mantissa divided by 10^scale. The scale is held by
+// the series or the packed block of the values (see DecimalPacker) rather than
+// by every value, so values sharing a scale add, subtract and compare exactly
+// as integers. Products are rescaled to the scale of their operands and
+// rounded half away from zero.
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Mantissa of a fixed-point decimal number (see ReadDecimalScale)
+type Decimal int64
+
+// Largest supported scale, the number of digits after the decimal point
+const MaxDecimalScale = 18
+
+// The result of a decimal operation does not fit in the mantissa
+var ErrDecimalOverflow = errors.New("decimal overflow")
+
+// Powers of ten up to 10^MaxDecimalScale
+var pow10 = [MaxDecimalScale + 1]uint64{
+	1, 10, 100, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12, 1e13,
+	1e14, 1e15, 1e16, 1e17, 1e18,
+}
+
+// Parse the decimal number in s (e.g. "-12.345") as a decimal of the
+// specified scale. Returns the decimal along with nil error, otherwise returns
+// (0, error) if s is not a number, has more significant fractional digits than
+// the scale or does not fit in the mantissa.
+func ParseDecimal(s string, scale uint8) (Decimal, error) {
+	if scale > MaxDecimalScale {
+		return 0, errors.New("unsupported decimal scale")
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	if negative || strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, errors.New("invalid decimal")
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > int(scale) {
+		return 0, errors.New("decimal has more fractional digits than its scale")
+	}
+	digits := intPart + fracPart + strings.Repeat("0", int(scale)-len(fracPart))
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return 0, errors.New("invalid decimal")
+	}
+
+	m, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, ErrDecimalOverflow
+	}
+	return decimalFromMagnitude(m, negative)
+}
+
+// Return the decimal of the specified scale nearest to v, rounded half away
+// from zero. Returns the decimal along with nil error, otherwise returns (0,
+// error) if v is not finite or does not fit in the mantissa.
+func DecimalFromFloat(v float64, scale uint8) (Decimal, error) {
+	if scale > MaxDecimalScale {
+		return 0, errors.New("unsupported decimal scale")
+	}
+
+	m := math.Round(v * float64(pow10[scale]))
+	if !(math.Abs(m) < math.MaxInt64) {
+		return 0, ErrDecimalOverflow
+	}
+	return Decimal(m), nil
+}
+
+// Return the value of the decimal of the specified scale as a float
+func (d Decimal) Float64(scale uint8) float64 {
+	return float64(d) / math.Pow10(int(scale))
+}
+
+// Format the decimal of the specified scale with scale fractional digits
+func (d Decimal) Format(scale uint8) string {
+	m := uint64(d)
+	sign := ""
+	if d < 0 {
+		m = -m
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(m, 10)
+	if scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(scale) {
+		digits = strings.Repeat("0", int(scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Return the decimal of scale from converted to scale to, rounded half away
+// from zero when digits are dropped. Returns the decimal along with nil error,
+// otherwise returns (0, error).
+func (d Decimal) Rescale(from, to uint8) (Decimal, error) {
+	if from > MaxDecimalScale || to > MaxDecimalScale {
+		return 0, errors.New("unsupported decimal scale")
+	}
+
+	m, negative := d.magnitude()
+	if to >= from {
+		hi, lo := bits.Mul64(m, pow10[to-from])
+		if hi != 0 {
+			return 0, ErrDecimalOverflow
+		}
+		return decimalFromMagnitude(lo, negative)
+	}
+	return decimalFromMagnitude(divRound(0, m, pow10[from-to]), negative)
+}
+
+// Return the sum of two decimals of the same scale. Returns the sum along with
+// nil error, otherwise returns (0, ErrDecimalOverflow).
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	sum := d + other
+	if (d >= 0) == (other >= 0) && (sum >= 0) != (d >= 0) {
+		return 0, ErrDecimalOverflow
+	}
+	return sum, nil
+}
+
+// Return the product of two decimals of the specified scale, at the same
+// scale and rounded half away from zero. The product is computed on 128 bits,
+// so it is exact before rounding. Returns the product along with nil error,
+// otherwise returns (0, error).
+func (d Decimal) Mul(other Decimal, scale uint8) (Decimal, error) {
+	if scale > MaxDecimalScale {
+		return 0, errors.New("unsupported decimal scale")
+	}
+
+	a, negA := d.magnitude()
+	b, negB := other.magnitude()
+	hi, lo := bits.Mul64(a, b)
+	if hi >= pow10[scale] {
+		return 0, ErrDecimalOverflow
+	}
+	return decimalFromMagnitude(divRound(hi, lo, pow10[scale]), negA != negB)
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the magnitude and the sign of the mantissa
+func (d Decimal) magnitude() (uint64, bool) {
+	if d < 0 {
+		return -uint64(d), true
+	}
+	return uint64(d), false
+}
+
+// Return the decimal of the specified magnitude and sign
+func decimalFromMagnitude(m uint64, negative bool) (Decimal, error) {
+	if negative {
+		if m > 1<<63 {
+			return 0, ErrDecimalOverflow
+		}
+		return Decimal(-m), nil
+	}
+	if m > math.MaxInt64 {
+		return 0, ErrDecimalOverflow
+	}
+	return Decimal(m), nil
+}
+
+// Return the 128-bit value hi:lo divided by p, rounded half away from zero.
+// The quotient must fit in 64 bits (hi < p); a quotient of 2^64-1 is not
+// rounded up, it is too large for a mantissa anyway.
+func divRound(hi, lo, p uint64) uint64 {
+	q, r := bits.Div64(hi, lo, p)
+	if r >= p-r && q < math.MaxUint64 {
+		q++
+	}
+	return q
+}
@@ -0,0 +1,94 @@
+package packer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal_ParseFormat(t *testing.T) {
+
+	d, err := ParseDecimal("-12.345", 4)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(-123450), d)
+	assert.Equal(t, "-12.3450", d.Format(4))
+
+	d, err = ParseDecimal("0.05", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.05", d.Format(2))
+	assert.Equal(t, "5", Decimal(5).Format(0))
+	assert.InDelta(t, 0.05, d.Float64(2), 1e-15)
+
+	// Trailing zeros beyond the scale are not significant
+	d, err = ParseDecimal("+7.500", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(75), d)
+
+	for _, s := range []string{"", "-", ".", "1.2.3", "abc", "1e3"} {
+		_, err := ParseDecimal(s, 2)
+		assert.NotNil(t, err, s)
+	}
+	_, err = ParseDecimal("1.234", 2)
+	assert.NotNil(t, err)
+	_, err = ParseDecimal("92233720368547758.08", 2)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+	_, err = ParseDecimal("1", MaxDecimalScale+1)
+	assert.NotNil(t, err)
+}
+
+func TestDecimal_FromFloat(t *testing.T) {
+
+	d, err := DecimalFromFloat(0.1+0.2, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(30), d)
+
+	d, err = DecimalFromFloat(-2.5, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(-3), d)
+
+	_, err = DecimalFromFloat(math.NaN(), 2)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+	_, err = DecimalFromFloat(1e300, 2)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+
+	// 0.1 + 0.2 is exactly 0.3
+	sum, err := Decimal(10).Add(20)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(30), sum)
+	_, err = Decimal(math.MaxInt64).Add(1)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+	_, err = Decimal(math.MinInt64).Add(-1)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	// 1.25 * 1.25 = 1.5625 rounded half away from zero to 1.56, 1.05 * 1.05 =
+	// 1.1025 to 1.10 and -1.15 * 1.1 = -1.265 to -1.27
+	p, err := Decimal(125).Mul(125, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(156), p)
+	p, err = Decimal(105).Mul(105, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(110), p)
+	p, err = Decimal(-115).Mul(110, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(-127), p)
+
+	// Products overflowing 64 bits before rescaling are exact
+	p, err = Decimal(3e15).Mul(4e15, 15)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(12e15), p)
+	_, err = Decimal(math.MaxInt64).Mul(2, 0)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	r, err := Decimal(12345).Rescale(2, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(1234500), r)
+	r, err = Decimal(-12345).Rescale(2, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal(-1235), r)
+	_, err = Decimal(math.MaxInt64).Rescale(0, 1)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+}
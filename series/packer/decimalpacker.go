@@ -0,0 +1,174 @@
+package packer
+
+// Compaction of fixed-point decimals. The int64 mantissas of the values are
+// divided by the largest power of ten dividing all of them, which removes the
+// trailing zeros of values quoted with fewer digits than the scale (e.g.
+// prices in cents stored with 4 fractional digits), and are stored with frame
+// of reference bit packing (see writeIntBlock).
+//
+// Layout of the payload:
+//
+//	8 bits    scale of the values (see ReadDecimalScale)
+//	8 bits    exponent k, the mantissas being multiples of 10^k
+//	          integer block of the mantissas divided by 10^k
+//
+// Offset and Delta are applied on the mantissas before they are divided, so
+// packing is exact for every op.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type DecimalPacker[T Number] struct {
+	scale uint8
+}
+
+func init() {
+	// The registered packer records a scale of 0, packers recording the scale
+	// of their series are created with NewDecimalPacker.
+	mustRegister(CodecDecimal, "decimal", func() Packer[Decimal] { return NewDecimalPacker[Decimal](0) })
+}
+
+// Create a new decimal packer recording the specified scale in the blocks it
+// packs.
+func NewDecimalPacker[T Number](scale uint8) *DecimalPacker[T] {
+	return &DecimalPacker[T]{scale: scale}
+}
+
+// Packs the decimal data in the src slice to the dst buffer and returns nil if
+// packing was completed successfuly. Otherwise, returns the error.
+func (dp *DecimalPacker[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if elemTypeOf[T]() != ElemDecimal {
+		return errors.New("unsupported type in pack")
+	}
+	if dp.scale > MaxDecimalScale {
+		return errors.New("unsupported decimal scale")
+	}
+
+	mantissas := make([]int64, len(src))
+	prev := opParam
+	for ndx := range src {
+		val := src[ndx]
+		switch op {
+		case Offset:
+			val += opParam
+		case Delta:
+			val, prev = val-prev, val
+		}
+		mantissas[ndx] = int64(val)
+	}
+
+	exp := decimalExponent(mantissas)
+	values := make([]uint64, len(mantissas))
+	for ndx, m := range mantissas {
+		values[ndx] = uint64(m/int64(pow10[exp])) ^ (1 << 63)
+	}
+
+	start := reserveHeader(dst)
+
+	bitStream := bitstream.NewWriter(dst)
+	bitStream.WriteBits(uint64(dp.scale), 8)
+	bitStream.WriteBits(uint64(exp), 8)
+	size := 16 + writeIntBlock(bitStream, values)
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecDecimal,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     size,
+	})
+
+	return nil
+}
+
+// Unpacks the decimal data in the src buffer to the dst slice and returns
+// number of elements unpacked along with nil error. Otherwise, returns (0,
+// error). The block header in src takes precedence over op and opParam.
+func (dp *DecimalPacker[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecDecimal)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+	if _, err := bitStream.ReadBits(8); err != nil {
+		return 0, streamError(err)
+	}
+	exp, err := bitStream.ReadBits(8)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if exp > MaxDecimalScale {
+		return 0, fmt.Errorf("%w: invalid decimal exponent", ErrCorrupt)
+	}
+
+	values := make([]uint64, hdr.NumElements)
+	if err := readIntBlock(bitStream, values); err != nil {
+		return 0, streamError(err)
+	}
+
+	for ndx, u := range values {
+		val := T(int64(u^(1<<63)) * int64(pow10[exp]))
+		switch op {
+		case NOP:
+			dst[ndx] = val
+		case Offset:
+			dst[ndx] = val - opParam
+		case Delta:
+			dst[ndx] = val + opParam
+			opParam = dst[ndx]
+		}
+	}
+
+	return hdr.NumElements, nil
+}
+
+// Return the id of the codec
+func (dp *DecimalPacker[T]) Codec() CodecID {
+	return CodecDecimal
+}
+
+// Return the scale recorded in the block at the start of the src buffer, which
+// must have been packed by a DecimalPacker. The buffer is not consumed.
+func ReadDecimalScale(src *bytes.Buffer) (uint8, error) {
+	hdr, payload, err := peekBlock[Decimal](src, CodecDecimal)
+	if err != nil {
+		return 0, err
+	}
+	if hdr.NumBits < 8 {
+		return 0, fmt.Errorf("%w: missing decimal scale", ErrCorrupt)
+	}
+	return payload[0], nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the largest exponent k such that every mantissa is a multiple of
+// 10^k. Zeros are multiples of any power of ten.
+func decimalExponent(mantissas []int64) uint8 {
+	var exp uint8 = MaxDecimalScale
+	for _, m := range mantissas {
+		for exp > 0 && m%int64(pow10[exp]) != 0 {
+			exp--
+		}
+		if exp == 0 {
+			break
+		}
+	}
+	return exp
+}
@@ -0,0 +1,72 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalPacker_RoundTrip(t *testing.T) {
+
+	a := decimalPrices(5000)
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+		err := NewDecimalPacker[Decimal](4).Pack(a, buffer, op, a[0])
+		assert.Nil(t, err)
+
+		scale, err := ReadDecimalScale(buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, uint8(4), scale)
+
+		res := make([]Decimal, len(a))
+		numElements, err := NewDecimalPacker[Decimal](0).Unpack(buffer, res, NOP, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(len(a)), numElements)
+		assert.Equal(t, a, res, op.String())
+	}
+
+	checkAllCodecs[Decimal](t, a, []PackOp{NOP, Offset, Delta})
+	checkAllCodecs[Decimal](t, []Decimal{math.MinInt64, 0, math.MaxInt64, -1, 1}, []PackOp{NOP})
+}
+
+// Prices quoted in cents and stored with 4 fractional digits pack to the bits
+// of the cents
+func TestDecimalPacker_CompressionCheckForPrices(t *testing.T) {
+
+	a := decimalPrices(10000)
+
+	decimalBuffer := &bytes.Buffer{}
+	assert.Nil(t, NewDecimalPacker[Decimal](4).Pack(a, decimalBuffer, NOP, 0))
+
+	simple8bBuffer := &bytes.Buffer{}
+	assert.Nil(t, NewSimple8b[Decimal]().Pack(a, simple8bBuffer, NOP, 0))
+
+	assert.Less(t, decimalBuffer.Len(), simple8bBuffer.Len())
+	assert.Less(t, 4*decimalBuffer.Len(), 8*len(a))
+}
+
+func TestDecimalPacker_Errors(t *testing.T) {
+
+	assert.NotNil(t, NewDecimalPacker[int64](2).Pack([]int64{1}, &bytes.Buffer{}, NOP, 0))
+	assert.NotNil(t, NewDecimalPacker[Decimal](MaxDecimalScale+1).Pack([]Decimal{1}, &bytes.Buffer{}, NOP, 0))
+	assert.False(t, Supports[int64](CodecDecimal))
+	assert.True(t, Supports[Decimal](CodecDecimal))
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, NewChimp[Decimal]().Pack([]Decimal{1, 2}, buffer, NOP, 0))
+	_, err := ReadDecimalScale(buffer)
+	assert.NotNil(t, err)
+}
+
+// Prices in cents around 100.00 with a scale of 4
+func decimalPrices(n int) []Decimal {
+	a := make([]Decimal, n)
+	for i := range a {
+		cents := 10000 + int64(500*math.Sin(float64(i)/50.0)) + int64(i%7)
+		a[i] = Decimal(cents * 100)
+	}
+	return a
+}
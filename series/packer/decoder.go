@@ -0,0 +1,196 @@
+package packer
+
+// Chunked decoding. A Decoder streams through the values of a packed block in
+// chunks written into a caller provided slice, so a block can be scanned with
+// a small reusable buffer instead of being unpacked into a slice holding all
+// of its values. The XOR based codecs (Chimp, Gorilla) decode incrementally;
+// blocks of other codecs are unpacked once by the decoder and handed out in
+// chunks.
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+// Decodes the values of a packed block in chunks.
+type Decoder[T Number] interface {
+
+	// Decodes the next values of the block into dst and returns the number of
+	// decoded values along with nil error. Returns (0, io.EOF) once all the
+	// values of the block were decoded, otherwise returns the number of values
+	// decoded before the error along with the error.
+	DecodeNext(dst []T) (int, error)
+}
+
+// Implemented by packers able to decode a block incrementally.
+type StreamingPacker[T Number] interface {
+	Packer[T]
+
+	// Create a decoder of the block at the start of the src buffer, which must
+	// not be modified while the decoder is in use. Returns the decoder along
+	// with nil error, otherwise returns (nil, error).
+	NewDecoder(src *bytes.Buffer) (Decoder[T], error)
+}
+
+// Create a decoder of the block at the start of the src buffer using the
+// specified packer. Blocks of packers that cannot decode incrementally are
+// unpacked once by the decoder. Returns the decoder along with nil error,
+// otherwise returns (nil, error).
+func NewDecoder[T Number](p Packer[T], src *bytes.Buffer) (Decoder[T], error) {
+	if sp, ok := p.(StreamingPacker[T]); ok {
+		return sp.NewDecoder(src)
+	}
+
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return nil, streamError(err)
+	}
+	values := make([]T, hdr.NumElements)
+	if _, err := p.Unpack(src, values, NOP, 0); err != nil {
+		return nil, err
+	}
+	return NewSliceDecoder(values), nil
+}
+
+// Create a decoder handing out the specified unpacked values in chunks
+func NewSliceDecoder[T Number](values []T) Decoder[T] {
+	return &sliceDecoder[T]{values: values}
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+type sliceDecoder[T Number] struct {
+	values []T
+	pos    int
+}
+
+func (dec *sliceDecoder[T]) DecodeNext(dst []T) (int, error) {
+	if dec.pos >= len(dec.values) {
+		return 0, io.EOF
+	}
+	n := copy(dst, dec.values[dec.pos:])
+	dec.pos += n
+	return n, nil
+}
+
+// Incremental decoder of the blocks of the XOR based codecs, with or without
+// restart points
+type xorDecoder[T Number] struct {
+	codec    xorCodec
+	hdr      Header
+	payload  []byte
+	idx      *restartIndex
+	signs    *bitstream.BitReader
+	values   *bitstream.BitReader
+	opParam  T
+	prev     T
+	minusOne T
+	pos      uint64
+}
+
+// Create a decoder of the block of an XOR based codec
+func newXorDecoder[T Number](
+	codec xorCodec, id CodecID, src *bytes.Buffer) (Decoder[T], error) {
+
+	hdr, payload, err := openBlock[T](src, id)
+	if err != nil {
+		return nil, streamError(err)
+	}
+
+	dec := &xorDecoder[T]{
+		codec:    codec,
+		hdr:      hdr,
+		payload:  payload,
+		opParam:  fromBits[T](hdr.OpParam),
+		minusOne: fromBits[T](toBits(int64(-1))),
+	}
+	dec.prev = dec.opParam
+	codec.setWordBits(xorWordBits(hdr.ElemType, hdr.HasFlag(FlagSmallInts)))
+
+	if hdr.HasFlag(FlagIndex) {
+		idx, err := readRestartIndex(&hdr, payload)
+		if err != nil {
+			return nil, streamError(err)
+		}
+		dec.idx = &idx
+	}
+
+	// Signs of signed integer values are packed ahead of the values
+	var offset uint64 = 0
+	if hdr.ElemType.isSignedInt() {
+		if dec.signs, err = bitReaderAt(payload, 0); err != nil {
+			return nil, err
+		}
+		offset = hdr.NumElements
+	}
+	if dec.idx == nil {
+		if dec.values, err = bitReaderAt(payload, offset); err != nil {
+			return nil, err
+		}
+	}
+
+	return dec, nil
+}
+
+func (dec *xorDecoder[T]) DecodeNext(dst []T) (int, error) {
+	if dec.pos >= dec.hdr.NumElements {
+		return 0, io.EOF
+	}
+
+	n := uint64(len(dst))
+	if remaining := dec.hdr.NumElements - dec.pos; n > remaining {
+		n = remaining
+	}
+
+	smallInts := dec.hdr.HasFlag(FlagSmallInts)
+	for i := uint64(0); i < n; i++ {
+
+		// Restart the decoder at every restart point
+		if dec.idx != nil && dec.pos%dec.idx.interval == 0 {
+			br, err := bitReaderAt(dec.payload, dec.idx.offset(dec.pos/dec.idx.interval))
+			if err != nil {
+				return int(i), err
+			}
+			dec.values = br
+			dec.codec.reset()
+			dec.prev = dec.opParam
+		}
+
+		if err := dec.codec.next(dec.values); err != nil {
+			return int(i), streamError(err)
+		}
+
+		uVal := dec.codec.lastValue()
+		if dec.hdr.ElemType != ElemFloat64 && smallInts {
+			uVal = (uVal << 32) | (uVal >> 32)
+		}
+
+		val := fromBits[T](uVal)
+		switch dec.hdr.Op {
+		case Offset:
+			val -= dec.opParam
+		case Delta:
+			val += dec.prev
+			dec.prev = val
+		}
+
+		if dec.signs != nil {
+			bit, err := dec.signs.ReadBit()
+			if err != nil {
+				return int(i), streamError(err)
+			}
+			if bit {
+				val *= dec.minusOne
+			}
+		}
+
+		dst[i] = val
+		dec.pos++
+	}
+
+	return int(n), nil
+}
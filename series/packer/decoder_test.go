@@ -0,0 +1,121 @@
+package packer
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder_XorCodecs(t *testing.T) {
+
+	prices := make([]float64, 1000)
+	ints := make([]int64, 1000)
+	uints := make([]uint64, 1000)
+	for i := range prices {
+		prices[i] = 100.0 + math.Sin(float64(i)/10.0)*3.25
+		ints[i] = int64(i*i%97) - 40
+		uints[i] = uint64(1700000000 + i*15 + i%3)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		for _, interval := range []uint64{0, 7, DefaultRestartInterval} {
+			checkDecoder[float64](t, NewChimp[float64]().WithRestartInterval(interval), prices, op, 1.5)
+			checkDecoder[float64](t, NewGorilla[float64]().WithRestartInterval(interval), prices, op, 1.5)
+			checkDecoder[int64](t, NewChimp[int64]().WithRestartInterval(interval), ints, op, 7)
+			checkDecoder[int64](t, NewGorilla[int64]().WithRestartInterval(interval), ints, op, 7)
+			checkDecoder[uint64](t, NewChimp[uint64]().WithRestartInterval(interval), uints, op, 3)
+			checkDecoder[uint64](t, NewGorilla[uint64]().WithRestartInterval(interval), uints, op, 3)
+		}
+	}
+}
+
+// Blocks of codecs that cannot decode incrementally are unpacked once
+func TestDecoder_OtherCodecs(t *testing.T) {
+
+	a := make([]int64, 1000)
+	for i := range a {
+		a[i] = int64(i%50) * 3
+	}
+
+	checkDecoder[int64](t, NewSimple8b[int64](), a, Delta, 0)
+	checkDecoder[int64](t, NewDeltaOfDelta[int64](), a, NOP, 0)
+	checkDecoder[int64](t, NewRLE[int64](), a, NOP, 0)
+}
+
+func TestDecoder_CorruptBlock(t *testing.T) {
+
+	buffer := packTestBlock(t, CodecChimp)
+	buffer.Bytes()[HeaderSize+1] ^= 0x10
+
+	_, err := NewDecoder[float64](NewChimp[float64](), buffer)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Payload shorter than announced by the header
+	truncated := stripChecksum(packTestBlock(t, CodecChimp), 2)
+	dec, err := NewDecoder[float64](NewChimp[float64](), truncated)
+	assert.Nil(t, err)
+
+	dst := make([]float64, 64)
+	for err == nil {
+		_, err = dec.DecodeNext(dst)
+	}
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func checkDecoder[T Number](
+	t *testing.T, p Packer[T], values []T, op PackOp, opParam T) {
+
+	buffer := &bytes.Buffer{}
+	err := p.Pack(values, buffer, op, opParam)
+	assert.Nil(t, err)
+
+	for _, chunkSize := range []int{1, 13, 64, len(values) + 5} {
+		dec, err := NewDecoder[T](p, buffer)
+		assert.Nil(t, err)
+
+		res := make([]T, 0, len(values))
+		chunk := make([]T, chunkSize)
+		for {
+			n, err := dec.DecodeNext(chunk)
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			assert.Greater(t, n, 0)
+			res = append(res, chunk[:n]...)
+		}
+		assert.Equal(t, values, res)
+
+		n, err := dec.DecodeNext(chunk)
+		assert.Equal(t, 0, n)
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+// Benchmark testing for scans. A single iteration decodes 1 million floats in
+// chunks of 256 values into a reused buffer.
+func BenchmarkChimpFor_Float64_DecodingChunks(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	chunk := make([]float64, 256)
+	for i := range a {
+		a[i] = float64(i) + 10000
+	}
+
+	chimp := NewChimp[float64]()
+	buffer := &bytes.Buffer{}
+	chimp.Pack(a, buffer, NOP, 0.0)
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		dec, _ := chimp.NewDecoder(buffer)
+		for {
+			if _, err := dec.DecodeNext(chunk); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package packer
+
+// Delta-of-delta compaction for integer time series, based on the timestamp
+// compression described in the VLDB 2015 paper "Gorilla: A Fast, Scalable,
+// In-Memory Time Series Database" by Teller, et al. Regularly spaced values
+// (such as timestamps of a fixed scrape interval) cost a single bit each.
+//
+// The first value is written as is. For every subsequent value the difference
+// between its delta and the previous delta (the delta-of-delta) is zigzag
+// encoded and written in the smallest of the following buckets:
+//
+//	'0'                       delta-of-delta is 0
+//	'10'    + 7 bits
+//	'110'   + 9 bits
+//	'1110'  + 12 bits
+//	'11110' + 32 bits
+//	'11111' + 64 bits
+//
+// Paper Link: http://www.vldb.org/pvldb/vol8/p1816-teller.pdf
+//
+// Values can also be encoded one at a time (see NewAppender), so the head
+// time frame of a series stays packed while it is being filled.
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type DeltaOfDelta[T Number] struct{}
+
+type dodBucket struct {
+	prefix     uint64
+	prefixBits int
+	valueBits  int
+}
+
+var dodBuckets = [...]dodBucket{
+	{prefix: 0b10, prefixBits: 2, valueBits: 7},
+	{prefix: 0b110, prefixBits: 3, valueBits: 9},
+	{prefix: 0b1110, prefixBits: 4, valueBits: 12},
+	{prefix: 0b11110, prefixBits: 5, valueBits: 32},
+	{prefix: 0b11111, prefixBits: 5, valueBits: 64},
+}
+
+// Encoder and decoder state of a single pack or unpack call
+type dodState struct {
+	prevValue uint64
+	prevDelta uint64
+	size      uint64
+	first     bool
+}
+
+func init() {
+	mustRegister(CodecDeltaOfDelta, "delta-of-delta", func() Packer[int64] { return NewDeltaOfDelta[int64]() })
+	mustRegister(CodecDeltaOfDelta, "delta-of-delta", func() Packer[uint64] { return NewDeltaOfDelta[uint64]() })
+	mustRegister(CodecDeltaOfDelta, "delta-of-delta", func() Packer[int32] { return NewDeltaOfDelta[int32]() })
+	mustRegister(CodecDeltaOfDelta, "delta-of-delta", func() Packer[uint32] { return NewDeltaOfDelta[uint32]() })
+	mustRegister(CodecDeltaOfDelta, "delta-of-delta", func() Packer[Decimal] { return NewDeltaOfDelta[Decimal]() })
+}
+
+func NewDeltaOfDelta[T Number]() *DeltaOfDelta[T] {
+	return &DeltaOfDelta[T]{}
+}
+
+// Packs the integer data in the src slice to the dst buffer and returns nil if
+// packing was completed successfuly. Otherwise, returns the error.
+func (dod *DeltaOfDelta[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	switch any(opParam).(type) {
+	case int64, uint64, int32, uint32, Decimal:
+	default:
+		return errors.New("unsupported type in pack")
+	}
+
+	start := reserveHeader(dst)
+
+	state := dodState{first: true}
+	prev := opParam
+	bitStream := bitstream.NewWriter(dst)
+	for ndx := range src {
+		val := src[ndx]
+		switch op {
+		case NOP:
+			state.write(bitStream, toBits(val))
+		case Offset:
+			state.write(bitStream, toBits(val+opParam))
+		case Delta:
+			state.write(bitStream, toBits(val-prev))
+			prev = val
+		}
+	}
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecDeltaOfDelta,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     state.size,
+	})
+
+	return nil
+}
+
+// Unpacks the integer data in the src buffer to the dst slice and returns
+// number of elements unpacked along with nil error. Otherwise, returns (0,
+// error). The block header in src takes precedence over op and opParam.
+func (dod *DeltaOfDelta[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecDeltaOfDelta)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+
+	state := dodState{first: true}
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+
+	var readElements uint64 = 0
+	for readElements < hdr.NumElements {
+		uVal, err := state.read(bitStream)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		switch op {
+		case NOP:
+			dst[readElements] = fromBits[T](uVal)
+		case Offset:
+			dst[readElements] = fromBits[T](uVal) - opParam
+		case Delta:
+			dst[readElements] = fromBits[T](uVal) + opParam
+			opParam = dst[readElements]
+		}
+		readElements++
+	}
+
+	return readElements, nil
+}
+
+// Return the id of the codec
+func (dod *DeltaOfDelta[T]) Codec() CodecID {
+	return CodecDeltaOfDelta
+}
+
+// Create an appender encoding values with the specified pre-compact operation
+// and parameter. Snapshots of the appender are identical to the blocks packed
+// by Pack.
+func (dod *DeltaOfDelta[T]) NewAppender(op PackOp, opParam T) Appender[T] {
+	return &dodAppender[T]{
+		state:       dodState{first: true},
+		op:          op,
+		opParam:     opParam,
+		prev:        opParam,
+		numElements: 0,
+	}
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func (state *dodState) write(bitStream bitSink, value uint64) {
+	if state.first {
+		state.first = false
+		state.prevValue = value
+		bitStream.WriteBits(value, 64)
+		state.size += 64
+		return
+	}
+
+	delta := value - state.prevValue
+	zz := zigzag(int64(delta - state.prevDelta))
+	state.prevValue = value
+	state.prevDelta = delta
+
+	if zz == 0 {
+		bitStream.WriteBit(false)
+		state.size++
+		return
+	}
+
+	for _, bucket := range dodBuckets {
+		if bucket.valueBits == 64 || zz < (uint64(1)<<bucket.valueBits) {
+			bitStream.WriteBits(bucket.prefix, bucket.prefixBits)
+			bitStream.WriteBits(zz, bucket.valueBits)
+			state.size += uint64(bucket.prefixBits + bucket.valueBits)
+			return
+		}
+	}
+}
+
+func (state *dodState) read(bitStream *bitstream.BitReader) (uint64, error) {
+	if state.first {
+		state.first = false
+		value, err := bitStream.ReadBits(64)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		state.prevValue = value
+		return value, nil
+	}
+
+	// Count the leading ones of the bucket prefix
+	numOnes := 0
+	for numOnes < len(dodBuckets) {
+		bit, err := bitStream.ReadBit()
+		if err != nil {
+			return 0, streamError(err)
+		}
+		if !bit {
+			break
+		}
+		numOnes++
+	}
+
+	var dod uint64 = 0
+	if numOnes > 0 {
+		zz, err := bitStream.ReadBits(dodBuckets[numOnes-1].valueBits)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		dod = uint64(unzigzag(zz))
+	}
+
+	state.prevDelta += dod
+	state.prevValue += state.prevDelta
+
+	return state.prevValue, nil
+}
+
+// Maps signed integers to unsigned integers so that values of small magnitude
+// have small encodings.
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func unzigzag(u uint64) int64 {
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// Appender of the DeltaOfDelta codec, holding the encoder state between
+// appended values
+type dodAppender[T Number] struct {
+	state       dodState
+	op          PackOp
+	opParam     T
+	prev        T
+	values      bitWriter
+	numElements uint64
+}
+
+func (app *dodAppender[T]) Append(value T) error {
+	switch any(value).(type) {
+	case int64, uint64, int32, uint32, Decimal:
+	default:
+		return errors.New("unsupported type in append")
+	}
+
+	switch app.op {
+	case NOP:
+		app.state.write(&app.values, toBits(value))
+	case Offset:
+		app.state.write(&app.values, toBits(value+app.opParam))
+	case Delta:
+		app.state.write(&app.values, toBits(value-app.prev))
+		app.prev = value
+	}
+	app.numElements++
+
+	return nil
+}
+
+func (app *dodAppender[T]) Snapshot(dst *bytes.Buffer) error {
+	start := reserveHeader(dst)
+	dst.Write(app.values.bytes())
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecDeltaOfDelta,
+		ElemType:    elemTypeOf[T](),
+		Op:          app.op,
+		NumElements: app.numElements,
+		OpParam:     toBits(app.opParam),
+		NumBits:     app.state.size,
+	})
+
+	return nil
+}
+
+func (app *dodAppender[T]) PackedSize() uint64 {
+	return packedSize(app.state.size)
+}
+
+func (app *dodAppender[T]) NumElements() uint64 {
+	return app.numElements
+}
@@ -0,0 +1,137 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeltaOfDelta_UInt64_ValueCheck(t *testing.T) {
+
+	a := []uint64{
+		1700000000, 1700000010, 1700000020, 1700000031, 1700000040,
+		1700000040, 1700001000, 1600000000, 0, math.MaxUint64}
+	res := make([]uint64, len(a))
+
+	dod := NewDeltaOfDelta[uint64]()
+	buffer := &bytes.Buffer{}
+
+	err := dod.Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	numElements, err := dod.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	assert.Equal(t, a, res)
+}
+
+func TestDeltaOfDelta_Int64_ValueCheck(t *testing.T) {
+
+	a := []int64{-5, 100, -100000, math.MinInt64, math.MaxInt64, 0, 7, 7, 7}
+	res := make([]int64, len(a))
+
+	dod := NewDeltaOfDelta[int64]()
+	buffer := &bytes.Buffer{}
+
+	err := dod.Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	_, err = dod.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, res)
+}
+
+func TestDeltaOfDelta_UInt64_PackOps(t *testing.T) {
+
+	a := make([]uint64, 100)
+	for i := range a {
+		a[i] = 1000 + uint64(i*i)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		dod := NewDeltaOfDelta[uint64]()
+		buffer := &bytes.Buffer{}
+
+		err := dod.Pack(a, buffer, op, 5)
+		assert.Nil(t, err)
+
+		res := make([]uint64, len(a))
+		_, err = NewDeltaOfDelta[uint64]().Unpack(buffer, res, NOP, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, a, res, op.String())
+	}
+}
+
+// Regularly spaced timestamps must cost about a single bit per value
+func TestDeltaOfDelta_UInt64_CompressionCheckForRegularInterval(t *testing.T) {
+
+	a := make([]uint64, 1000)
+	for i := range a {
+		a[i] = 1700000000000 + uint64(i)*10000
+	}
+
+	dod := NewDeltaOfDelta[uint64]()
+	buffer := &bytes.Buffer{}
+
+	dod.Pack(a, buffer, NOP, 0)
+
+	// First value (64 bits), first delta (32 bits bucket) and 998 zero bits
+	assert.Equal(t, uint64(64+5+32+998), packedBits(buffer))
+	assert.Equal(t, HeaderSize+138+ChecksumSize, buffer.Len())
+}
+
+func TestDeltaOfDelta_UnsupportedType(t *testing.T) {
+
+	dod := NewDeltaOfDelta[float64]()
+	buffer := &bytes.Buffer{}
+
+	err := dod.Pack([]float64{1.0}, buffer, NOP, 0.0)
+	assert.NotNil(t, err)
+	assert.Zero(t, buffer.Len())
+
+	assert.False(t, Supports[float64](CodecDeltaOfDelta))
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// timestamps at a 10 second interval with occasional jitter.
+func BenchmarkDeltaOfDeltaFor_UInt64_PackingTimestamps(t *testing.B) {
+
+	a := make([]uint64, 1000000)
+	for i := range a {
+		a[i] = 1700000000000 + uint64(i)*10000 + uint64(i%97)
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		dod := NewDeltaOfDelta[uint64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		dod.Pack(a, buffer, NOP, 0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will unpack 1 million
+// timestamps at a 10 second interval with occasional jitter.
+func BenchmarkDeltaOfDeltaFor_UInt64_UnpackingTimestamps(t *testing.B) {
+
+	a := make([]uint64, 1000000)
+	res := make([]uint64, 1000000)
+	for i := range a {
+		a[i] = 1700000000000 + uint64(i)*10000 + uint64(i%97)
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		dod := NewDeltaOfDelta[uint64]()
+		buffer := &bytes.Buffer{}
+		dod.Pack(a, buffer, NOP, 0)
+
+		t.StartTimer()
+		dod.Unpack(buffer, res, NOP, 0)
+		t.StopTimer()
+	}
+}
@@ -0,0 +1,35 @@
+package packer
+
+import (
+	"errors"
+	"io"
+)
+
+// Errors reported when decoding packed blocks. Errors returned by the packers
+// wrap one of these when the block is invalid, use errors.Is to test for them.
+var (
+	// The block header is invalid or unsupported
+	ErrBadHeader = errors.New("bad block header")
+
+	// The block or its payload ends prematurely
+	ErrTruncated = errors.New("truncated block")
+
+	// The checksum of the block does not match its content
+	ErrChecksumMismatch = errors.New("block checksum mismatch")
+
+	// The payload holds data that cannot be decoded
+	ErrCorrupt = errors.New("corrupt block payload")
+)
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the error of a read from a packed payload, reading past the end of
+// the payload being reported as ErrTruncated.
+func streamError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrTruncated
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package packer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors_ChecksumMismatch(t *testing.T) {
+
+	for _, id := range Codecs() {
+		if !Supports[float64](id) {
+			continue
+		}
+		buffer := packTestBlock(t, id)
+
+		// Flip a bit of the payload
+		buffer.Bytes()[HeaderSize+1] ^= 0x10
+
+		p, _ := ForBuffer[float64](buffer)
+		_, err := p.Unpack(buffer, make([]float64, 1000), NOP, 0)
+		assert.True(t, errors.Is(err, ErrChecksumMismatch), id.String())
+		assert.True(t, errors.Is(VerifyBlock(buffer), ErrChecksumMismatch), id.String())
+	}
+}
+
+func TestErrors_TruncatedAndBadHeader(t *testing.T) {
+
+	buffer := packTestBlock(t, CodecChimp)
+	dst := make([]float64, 1000)
+
+	truncated := bytes.NewBuffer(append([]byte{}, buffer.Bytes()[:buffer.Len()-10]...))
+	_, err := NewChimp[float64]().Unpack(truncated, dst, NOP, 0)
+	assert.True(t, errors.Is(err, ErrTruncated))
+
+	_, err = NewChimp[float64]().Unpack(bytes.NewBuffer(buffer.Bytes()[:10]), dst, NOP, 0)
+	assert.True(t, errors.Is(err, ErrTruncated))
+
+	badMagic := bytes.NewBuffer(append([]byte{}, buffer.Bytes()...))
+	badMagic.Bytes()[0] = 'X'
+	_, err = NewChimp[float64]().Unpack(badMagic, dst, NOP, 0)
+	assert.True(t, errors.Is(err, ErrBadHeader))
+
+	badVersion := bytes.NewBuffer(append([]byte{}, buffer.Bytes()...))
+	badVersion.Bytes()[3] = HeaderVersion + 1
+	_, err = NewChimp[float64]().Unpack(badVersion, dst, NOP, 0)
+	assert.True(t, errors.Is(err, ErrBadHeader))
+}
+
+// Blocks without checksum whose payload is shorter than announced by the
+// header must report a truncated stream instead of decoding garbage.
+func TestErrors_TruncatedPayload(t *testing.T) {
+
+	for _, id := range Codecs() {
+		if !Supports[float64](id) || id == CodecAdaptive {
+			continue
+		}
+		buffer := stripChecksum(packTestBlock(t, id), 2)
+
+		p, _ := ForBuffer[float64](buffer)
+		n, err := p.Unpack(buffer, make([]float64, 1000), NOP, 0)
+		assert.True(t, errors.Is(err, ErrTruncated), id.String())
+		assert.Equal(t, uint64(0), n, id.String())
+	}
+
+	// Integer codecs report no element either
+	a := make([]int64, 1000)
+	for i := range a {
+		a[i] = int64(1700000000 + i*15 + i%4)
+	}
+	for _, id := range []CodecID{CodecDeltaOfDelta, CodecRLE, CodecChimp, CodecGorilla} {
+		p, err := New[int64](id)
+		assert.Nil(t, err)
+		buffer := &bytes.Buffer{}
+		assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+
+		n, err := p.Unpack(stripChecksum(buffer, 2), make([]int64, 1000), NOP, 0)
+		assert.True(t, errors.Is(err, ErrTruncated), id.String())
+		assert.Equal(t, uint64(0), n, id.String())
+	}
+}
+
+// Corrupt payloads of blocks without checksum must not make unpack panic.
+func TestErrors_CorruptPayloadDoesNotPanic(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(42))
+
+	for _, id := range Codecs() {
+		if !Supports[float64](id) {
+			continue
+		}
+		block := stripChecksum(packTestBlock(t, id), 1).Bytes()
+
+		for i := 0; i < 200; i++ {
+			corrupt := append([]byte{}, block...)
+			pos := HeaderSize + rnd.Intn(len(corrupt)-HeaderSize)
+			corrupt[pos] ^= byte(1 << uint(rnd.Intn(8)))
+
+			p, _ := ForBuffer[float64](bytes.NewBuffer(block))
+			assert.NotPanics(t, func() {
+				p.Unpack(bytes.NewBuffer(corrupt), make([]float64, 1000), NOP, 0)
+			}, id.String())
+		}
+	}
+}
+
+// Blocks whose header announces more elements than their payload can hold
+// must be rejected before buffers are sized from the element count, even when
+// their checksum is valid.
+func TestErrors_ForgedElementCount(t *testing.T) {
+
+	a := make([]int64, 1000)
+	for i := range a {
+		a[i] = int64(1700000000 + i*15 + i%4)
+	}
+	for _, id := range []CodecID{CodecChimp, CodecChimp128, CodecGorilla, CodecDeltaOfDelta} {
+		p, err := New[int64](id)
+		assert.Nil(t, err)
+		buffer := &bytes.Buffer{}
+		assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+		hdr, err := ReadHeader(buffer)
+		assert.Nil(t, err)
+
+		// Forge the count and seal the block again
+		hdr.NumElements = math.MaxUint64 / 8
+		hdr.encode(buffer.Bytes()[:HeaderSize])
+		buffer.Truncate(buffer.Len() - ChecksumSize)
+		writeChecksum(buffer, 0)
+
+		_, err = ReadHeader(buffer)
+		assert.True(t, errors.Is(err, ErrCorrupt), id.String())
+		assert.True(t, errors.Is(VerifyBlock(buffer), ErrCorrupt), id.String())
+
+		n, err := p.Unpack(buffer, make([]int64, 1000), NOP, 0)
+		assert.True(t, errors.Is(err, ErrCorrupt), id.String())
+		assert.Equal(t, uint64(0), n, id.String())
+
+		assert.NotPanics(t, func() {
+			_, err = NewDecoder(p, buffer)
+		}, id.String())
+		assert.True(t, errors.Is(err, ErrCorrupt), id.String())
+	}
+
+	// Segments never exceed the block, whatever the restart interval
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, NewChimp[int64]().WithRestartInterval(7).Pack(a[:5], buffer, NOP, 0))
+	hdr, _ := ReadHeader(buffer)
+	binary.LittleEndian.PutUint32(buffer.Bytes()[hdr.payloadEnd()-8:], math.MaxUint32)
+	buffer.Truncate(buffer.Len() - ChecksumSize)
+	writeChecksum(buffer, 0)
+	length, err := SegmentLength(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), length)
+
+	// Counts the payload can hold are accepted
+	buffer = packTestBlock(t, CodecGorilla)
+	hdr, _ = ReadHeader(buffer)
+	hdr.NumElements = hdr.NumBits
+	hdr.encode(buffer.Bytes()[:HeaderSize])
+	_, err = ReadHeader(buffer)
+	assert.Nil(t, err)
+}
+
+func packTestBlock(t *testing.T, id CodecID) *bytes.Buffer {
+	a := make([]float64, 1000)
+	for i := range a {
+		a[i] = math.Round((100.0+math.Sin(float64(i)/7.0)*10.0)*100) / 100
+	}
+
+	var p Packer[float64] = NewQuantized[float64](AbsoluteError, 0.01)
+	if id != CodecQuantized {
+		var err error
+		p, err = New[float64](id)
+		assert.Nil(t, err)
+	}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+
+	return buffer
+}
+
+// Remove the checksum of the block and divide the size of its payload by the
+// specified divisor.
+func stripChecksum(buffer *bytes.Buffer, divisor uint64) *bytes.Buffer {
+	hdr, _ := ReadHeader(buffer)
+	payload := buffer.Bytes()[HeaderSize:hdr.payloadEnd()]
+
+	hdr.Flags &^= FlagChecksum
+	hdr.NumBits = 8 * (uint64(len(payload)) / divisor)
+
+	b := make([]byte, HeaderSize)
+	hdr.encode(b)
+	b = append(b, payload[:hdr.NumBits/8]...)
+
+	return bytes.NewBuffer(b)
+}
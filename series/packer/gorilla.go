@@ -15,67 +15,166 @@ import (
 )
 
 type Gorilla[T Number] struct {
+	smallInts       bool
+	restartInterval uint64
+}
+
+// Encoding and decoding state of a single Gorilla block
+type gorillaState struct {
 	storedLeadingZeros  uint64
 	storedTrailingZeros uint64
 	storedValue         uint64
 	size                uint64
-	numElements         uint64
-	smallInts           bool
 	first               bool
+	wordBits            uint64
+}
+
+func init() {
+	mustRegister(CodecGorilla, "gorilla", func() Packer[int64] { return NewGorilla[int64]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[uint64] { return NewGorilla[uint64]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[float64] { return NewGorilla[float64]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[int32] { return NewGorilla[int32]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[uint32] { return NewGorilla[uint32]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[float32] { return NewGorilla[float32]() })
+	mustRegister(CodecGorilla, "gorilla", func() Packer[Decimal] { return NewGorilla[Decimal]() })
 }
 
 func NewGorilla[T Number]() *Gorilla[T] {
 	return &Gorilla[T]{
-		storedLeadingZeros:  math.MaxInt32,
-		storedTrailingZeros: 0,
-		storedValue:         0,
-		size:                0,
-		numElements:         0,
-		smallInts:           true,
-		first:               true,
+		smallInts:       true,
+		restartInterval: 0,
 	}
 }
 
-// Packs the float64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the data in the src slice to the dst buffer and returns nil if packing
+// was completed successfuly. Otherwise, returns the error. The packed data is
+// preceded by a block header, making dst decodable by any Gorilla instance.
 func (gor *Gorilla[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if gor.restartInterval > 0 {
+		app := newXorAppender[T](CodecGorilla, newGorillaState(), gor.restartInterval, op, opParam)
+		start := dst.Len()
+		if err := packXorSegments(app, src, dst); err != nil {
+			dst.Truncate(start)
+			return err
+		}
+		return nil
+	}
+
+	start := reserveHeader(dst)
+	state := newGorillaState()
+	state.setWordBits(xorWordBits(elemTypeOf[T](), gor.smallInts))
+
+	var err error
 	switch any(opParam).(type) {
-	case int64:
-		return gor.packInt(src, dst, op, opParam)
-	case uint64:
-		return gor.packUInt(src, dst, op, opParam)
+	case int64, int32, Decimal:
+		err = gor.packInt(state, src, dst, op, opParam)
+	case uint64, uint32, float32:
+		err = gor.packUInt(state, src, dst, op, opParam)
 	case float64:
-		return gor.packFloat(src, dst, op, opParam)
+		err = gor.packFloat(state, src, dst, op, opParam)
+	default:
+		err = errors.New("unsupported type in pack")
 	}
 
-	return errors.New("unsupported type in pack")
+	if err != nil {
+		dst.Truncate(start)
+		return err
+	}
+
+	var flags uint8 = 0
+	if gor.smallInts {
+		flags |= FlagSmallInts
+	}
+	writeHeader(dst, start, &Header{
+		Codec:       CodecGorilla,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		Flags:       flags,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     state.size,
+	})
+
+	return nil
 }
 
-// Unpacks the float64 data in the src buffer to the dst slice and returns
-// number of elements unpacked along with nil error. Otherwise, returns (0,
-// error).
+// Unpacks the data in the src buffer to the dst slice and returns number of
+// elements unpacked along with nil error. Otherwise, returns (0, error). The
+// number of elements, op and opParam are read from the block header, which
+// takes precedence over the specified op and opParam. The src buffer is not
+// consumed.
 func (gor *Gorilla[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecGorilla)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	smallInts := hdr.HasFlag(FlagSmallInts)
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+	in := bytes.NewBuffer(payload)
+
+	if hdr.HasFlag(FlagIndex) {
+		return unpackXorSegments[T](newGorillaState(), &hdr, payload, dst)
+	}
 
+	state := newGorillaState()
+	state.setWordBits(xorWordBits(hdr.ElemType, smallInts))
+
+	var numElements uint64 = 0
 	switch any(opParam).(type) {
-	case int64:
-		return gor.unpackInt(src, dst, op, opParam)
-	case uint64:
-		return gor.unpackUInt(src, dst, op, opParam)
+	case int64, int32, Decimal:
+		numElements, err = gor.unpackInt(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
+	case uint64, uint32, float32:
+		numElements, err = gor.unpackUInt(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
 	case float64:
-		return gor.unpackFloat(src, dst, op, opParam)
+		numElements, err = gor.unpackFloat(
+			state, in, dst, hdr.NumElements, smallInts, op, opParam)
+	default:
+		err = errors.New("unsupported type in unpack")
 	}
 
-	return 0, errors.New("unsupported type in unpack")
+	return numElements, err
+}
+
+// Create an appender encoding values with the Gorilla codec
+func (gor *Gorilla[T]) NewAppender(op PackOp, opParam T) Appender[T] {
+	return newXorAppender[T](CodecGorilla, newGorillaState(), gor.restartInterval, op, opParam)
+}
+
+// Return a copy of the packer writing restart points every interval values,
+// in Pack and in the appenders it creates. Zero disables restart points.
+func (gor *Gorilla[T]) WithRestartInterval(interval uint64) Seeker[T] {
+	p := *gor
+	p.restartInterval = interval
+	return &p
 }
 
-// Return the size of the packed data
-func (gor *Gorilla[T]) PackedSize() uint64 {
-	return (gor.size + 7) / 8
+// Unpacks the segment of the block in src holding the value at the specified
+// index into dst, and returns the index of the first value of the segment and
+// the number of unpacked values along with nil error. Otherwise, returns (0,
+// 0, error).
+func (gor *Gorilla[T]) UnpackSegment(src *bytes.Buffer, index uint64, dst []T) (uint64, uint64, error) {
+	return unpackXorSegmentAt[T](newGorillaState(), CodecGorilla, src, index, dst,
+		func(src *bytes.Buffer, dst []T) (uint64, error) {
+			return gor.Unpack(src, dst, NOP, 0)
+		})
 }
 
-// Reutrn the umber of elements in the frame
-func (gor *Gorilla[T]) NumElements() uint64 {
-	return gor.numElements
+// Create a decoder of the block at the start of the src buffer, decoding the
+// values incrementally (see Decoder).
+func (gor *Gorilla[T]) NewDecoder(src *bytes.Buffer) (Decoder[T], error) {
+	return newXorDecoder[T](newGorillaState(), CodecGorilla, src)
+}
+
+// Return the id of the codec
+func (gor *Gorilla[T]) Codec() CodecID {
+	return CodecGorilla
 }
 
 // -----------------------------------------------------------------------------
@@ -87,42 +186,30 @@ func (gor *Gorilla[T]) NumElements() uint64 {
 // Packs the float64 data in the src slice to the dst buffer and returns the buffer
 // Otherwise, returns (nil, error).
 func (gor *Gorilla[T]) packFloat(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
-	gor.numElements = 0
-
+	state *gorillaState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 	bitStream := bitstream.NewWriter(dst)
 	for ndx := range src {
 		val := T(src[ndx])
 		switch op {
 		case NOP:
-			gor.addUIntValue(bitStream, math.Float64bits(float64(val)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val)))
 		case Offset:
-			gor.addUIntValue(bitStream, math.Float64bits(float64(val+opParam)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val+opParam)))
 		case Delta:
-			gor.addUIntValue(bitStream, math.Float64bits(float64(val-opParam)))
+			state.addUIntValue(bitStream, math.Float64bits(float64(val-opParam)))
 			opParam = val
 		}
-		gor.numElements++
 	}
 	bitStream.Flush(false)
 
 	return nil
 }
 
-// Packs the uint64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the int64 or int32 data in the src slice to the dst buffer and
+// returns nil if packing was completed successfuly. Otherwise, returns the
+// error.
 func (gor *Gorilla[T]) packInt(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
-	gor.numElements = 0
-
+	state *gorillaState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 	bitStream := bitstream.NewWriter(dst)
 
 	// First pack the sign bits
@@ -133,7 +220,7 @@ func (gor *Gorilla[T]) packInt(
 			bitStream.WriteBit(false)
 		}
 	}
-	gor.size += uint64(len(src))
+	state.size += uint64(len(src))
 
 	for ndx := range src {
 		val := src[ndx]
@@ -142,67 +229,60 @@ func (gor *Gorilla[T]) packInt(
 		}
 		switch op {
 		case NOP:
-			uVal := uint64(val)
+			uVal := toWord(val)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Offset:
-			uVal := uint64(val + opParam)
+			uVal := toWord(val + opParam)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Delta:
-			uVal := uint64(val - opParam)
+			uVal := toWord(val - opParam)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 			opParam = val
 		}
-		gor.numElements++
 	}
 	bitStream.Flush(false)
 
 	return nil
 }
 
-// Packs the uint64 data in the src slice to the dst buffer and returns
-// nil if packing was completed successfuly. Otherwise, returns the error.
+// Packs the uint64, uint32 or float32 data in the src slice to the dst buffer
+// and returns nil if packing was completed successfuly. Otherwise, returns the
+// error. Values are packed as words of their raw bits (see toWord).
 func (gor *Gorilla[T]) packUInt(
-	src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
-	gor.numElements = 0
-
+	state *gorillaState, src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
 	bitStream := bitstream.NewWriter(dst)
 	for ndx := range src {
 		val := src[ndx]
 		switch op {
 		case NOP:
-			uVal := uint64(val)
+			uVal := toWord(val)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Offset:
-			uVal := uint64(val + opParam)
+			uVal := toWord(val + opParam)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 		case Delta:
-			uVal := uint64(val - opParam)
+			uVal := toWord(val - opParam)
 			if gor.smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			gor.addUIntValue(bitStream, uVal)
+			state.addUIntValue(bitStream, uVal)
 			opParam = val
 		}
-		gor.numElements++
 	}
 	bitStream.Flush(false)
 
@@ -213,26 +293,23 @@ func (gor *Gorilla[T]) packUInt(
 // number of float64 elements that was unpacked. Otherwise, returns (0,
 // error).
 func (gor *Gorilla[T]) unpackFloat(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedTrailingZeros = 0
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
+	state *gorillaState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
 	var readElements uint64 = 0
-	for readElements < gor.numElements {
-		gor.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			dst[readElements] = T(math.Float64frombits(gor.storedValue))
+			dst[readElements] = T(math.Float64frombits(state.storedValue))
 		case Offset:
-			dst[readElements] = T(math.Float64frombits(gor.storedValue)) - opParam
+			dst[readElements] = T(math.Float64frombits(state.storedValue)) - opParam
 		case Delta:
-			dst[readElements] = T(math.Float64frombits(gor.storedValue)) + opParam
+			dst[readElements] = T(math.Float64frombits(state.storedValue)) + opParam
 			opParam = dst[readElements]
 		}
 		readElements++
@@ -242,24 +319,22 @@ func (gor *Gorilla[T]) unpackFloat(
 
 }
 
-// Unpacks the int64 data in the src buffer to the dst slice and returns the
-// number of int64 elements that was unpacked. Otherwise, returns (0,
+// Unpacks the int64 or int32 data in the src buffer to the dst slice and
+// returns the number of elements that was unpacked. Otherwise, returns (0,
 // error).
 func (gor *Gorilla[T]) unpackInt(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedTrailingZeros = 0
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
+	state *gorillaState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
-	negInd := make([]int64, gor.NumElements())
+	negInd := make([]int64, numElements)
 	var ndx uint64 = 0
-	for ndx < gor.NumElements() {
-		var bits, _ = bitStream.ReadBits(1)
+	for ndx < numElements {
+		var bits, err = bitStream.ReadBits(1)
+		if err != nil {
+			return 0, streamError(err)
+		}
 		v := int64(bits)
 		if v == 1 {
 			negInd[ndx] = -1
@@ -270,28 +345,30 @@ func (gor *Gorilla[T]) unpackInt(
 	}
 
 	var readElements uint64 = 0
-	for readElements < gor.numElements {
-		gor.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal)
+			dst[readElements] = fromBits[T](uVal)
 		case Offset:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) - opParam
+			dst[readElements] = fromBits[T](uVal) - opParam
 
 		case Delta:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) + opParam
+			dst[readElements] = fromBits[T](uVal) + opParam
 			opParam = dst[readElements]
 		}
 		dst[readElements] *= T(negInd[readElements])
@@ -302,42 +379,39 @@ func (gor *Gorilla[T]) unpackInt(
 
 }
 
-// Unpacks the float64 data in the src buffer to the dst slice and returns the
-// number of float64 elements that was unpacked. Otherwise, returns (0,
-// error).
+// Unpacks the uint64, uint32 or float32 data in the src buffer to the dst
+// slice and returns the number of elements that was unpacked. Otherwise,
+// returns (0, error).
 func (gor *Gorilla[T]) unpackUInt(
-	src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
-
-	gor.storedLeadingZeros = math.MaxInt64
-	gor.storedTrailingZeros = 0
-	gor.storedValue = 0
-	gor.first = true
-	gor.size = 0
+	state *gorillaState, src *bytes.Buffer, dst []T, numElements uint64,
+	smallInts bool, op PackOp, opParam T) (uint64, error) {
 
 	bitStream := bitstream.NewReader(src)
 
 	var readElements uint64 = 0
-	for readElements < gor.numElements {
-		gor.next(bitStream)
+	for readElements < numElements {
+		if err := state.next(bitStream); err != nil {
+			return 0, streamError(err)
+		}
 		switch op {
 		case NOP:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal)
+			dst[readElements] = fromBits[T](uVal)
 		case Offset:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) - opParam
+			dst[readElements] = fromBits[T](uVal) - opParam
 		case Delta:
-			uVal := gor.storedValue
-			if gor.smallInts {
+			uVal := state.storedValue
+			if smallInts {
 				uVal = (uVal << 32) | (uVal >> 32)
 			}
-			dst[readElements] = T(uVal) + opParam
+			dst[readElements] = fromBits[T](uVal) + opParam
 			opParam = dst[readElements]
 		}
 		readElements++
@@ -346,26 +420,52 @@ func (gor *Gorilla[T]) unpackUInt(
 	return readElements, nil
 }
 
-func (gor *Gorilla[T]) addUIntValue(bitStream *bitstream.BitWriter, value uint64) {
-	if gor.first {
-		gor.writeFirst(bitStream, value)
+// Create the state of a Gorilla encoder and decoder
+func newGorillaState() *gorillaState {
+	state := &gorillaState{wordBits: 64}
+	state.reset()
+	return state
+}
+
+// Reset the state of the encoder and decoder
+func (state *gorillaState) reset() {
+	state.storedLeadingZeros = math.MaxInt64
+	state.storedTrailingZeros = 0
+	state.storedValue = 0
+	state.first = true
+}
+
+// Return the last encoded or decoded value
+func (state *gorillaState) lastValue() uint64 {
+	return state.storedValue
+}
+
+// Set the width of the encoded words. Words of 32 bits are held in the high
+// half of the values.
+func (state *gorillaState) setWordBits(wordBits uint64) {
+	state.wordBits = wordBits
+}
+
+func (state *gorillaState) addUIntValue(bitStream bitSink, value uint64) {
+	if state.first {
+		state.writeFirst(bitStream, value)
 	} else {
-		gor.compressValue(bitStream, value)
+		state.compressValue(bitStream, value)
 	}
 }
 
-func (gor *Gorilla[T]) writeFirst(bitStream *bitstream.BitWriter, value uint64) {
-	gor.first = false
-	gor.storedValue = value
-	bitStream.WriteBits(gor.storedValue, 64)
-	gor.size += 64
+func (state *gorillaState) writeFirst(bitStream bitSink, value uint64) {
+	state.first = false
+	state.storedValue = value
+	bitStream.WriteBits(value>>(64-state.wordBits), int(state.wordBits))
+	state.size += state.wordBits
 }
 
-func (gor *Gorilla[T]) compressValue(bitStream *bitstream.BitWriter, value uint64) {
-	var xor uint64 = gor.storedValue ^ value
+func (state *gorillaState) compressValue(bitStream bitSink, value uint64) {
+	var xor uint64 = state.storedValue ^ value
 	if xor == 0 {
 		bitStream.WriteBits(uint64(0), 1)
-		gor.size += 1
+		state.size += 1
 	} else {
 		var leadingZeros uint64 = uint64(bits.LeadingZeros64(xor))
 		var trailingZeros uint64 = uint64(bits.TrailingZeros64(xor))
@@ -376,47 +476,47 @@ func (gor *Gorilla[T]) compressValue(bitStream *bitstream.BitWriter, value uint6
 
 		bitStream.WriteBits(uint64(1), 1)
 
-		if leadingZeros >= gor.storedLeadingZeros && trailingZeros >= gor.storedTrailingZeros {
+		if leadingZeros >= state.storedLeadingZeros && trailingZeros >= state.storedTrailingZeros {
 			bitStream.WriteBits(uint64(0), 1)
-			var significantBits uint64 = 64 - gor.storedLeadingZeros - gor.storedTrailingZeros
-			bitStream.WriteBits(xor>>gor.storedTrailingZeros, int(significantBits))
-			gor.size += 1 + significantBits
+			var significantBits uint64 = 64 - state.storedLeadingZeros - state.storedTrailingZeros
+			bitStream.WriteBits(xor>>state.storedTrailingZeros, int(significantBits))
+			state.size += 2 + significantBits
 		} else {
 			bitStream.WriteBits(uint64(1), 1)
 			bitStream.WriteBits(leadingZeros, 5)
 			var significantBits uint64 = 64 - leadingZeros - trailingZeros
-			if significantBits == 64 {
-				bitStream.WriteBits(uint64(0), 6)
+			if significantBits == state.wordBits {
+				bitStream.WriteBits(uint64(0), state.lengthBits())
 			} else {
-				bitStream.WriteBits(significantBits, 6)
+				bitStream.WriteBits(significantBits, state.lengthBits())
 			}
 
 			bitStream.WriteBits(xor>>trailingZeros, int(significantBits))
 
-			gor.storedLeadingZeros = leadingZeros
-			gor.storedTrailingZeros = trailingZeros
+			state.storedLeadingZeros = leadingZeros
+			state.storedTrailingZeros = trailingZeros
 
-			gor.size += 1 + 5 + 6 + significantBits
+			state.size += 2 + 5 + uint64(state.lengthBits()) + significantBits
 		}
 	}
 
-	gor.storedValue = value
+	state.storedValue = value
 }
 
-func (gor *Gorilla[T]) next(bitStream *bitstream.BitReader) error {
-	if gor.first {
-		gor.first = false
-		var val, err = bitStream.ReadBits(64)
+func (state *gorillaState) next(bitStream *bitstream.BitReader) error {
+	if state.first {
+		state.first = false
+		var val, err = bitStream.ReadBits(int(state.wordBits))
 		if err != nil {
 			return err
 		}
-		gor.storedValue = val
+		state.storedValue = val << (64 - state.wordBits)
 		return nil
 	}
-	return gor.nextValue(bitStream)
+	return state.nextValue(bitStream)
 }
 
-func (gor *Gorilla[T]) nextValue(bitStream *bitstream.BitReader) error {
+func (state *gorillaState) nextValue(bitStream *bitstream.BitReader) error {
 
 	var significantBits uint64 = 0
 	var value uint64 = 0
@@ -432,21 +532,46 @@ func (gor *Gorilla[T]) nextValue(bitStream *bitstream.BitReader) error {
 		return nil
 	}
 
-	var updatedLeadingZeros, _ = bitStream.ReadBits(1)
+	updatedLeadingZeros, err := bitStream.ReadBits(1)
+	if err != nil {
+		return err
+	}
 	if updatedLeadingZeros != 0 {
-		gor.storedLeadingZeros, _ = bitStream.ReadBits(5)
+		state.storedLeadingZeros, err = bitStream.ReadBits(5)
+		if err != nil {
+			return err
+		}
 
-		significantBits, _ = bitStream.ReadBits(6)
+		significantBits, err = bitStream.ReadBits(state.lengthBits())
+		if err != nil {
+			return err
+		}
 		if significantBits == 0 {
-			significantBits = 64
+			significantBits = state.wordBits
+		}
+		if significantBits+state.storedLeadingZeros > 64 {
+			return ErrCorrupt
 		}
-		gor.storedTrailingZeros = 64 - significantBits - gor.storedLeadingZeros
+		state.storedTrailingZeros = 64 - significantBits - state.storedLeadingZeros
+	} else if state.storedLeadingZeros > 64 {
+		return ErrCorrupt
 	}
 
-	value, _ = bitStream.ReadBits(64 - int(gor.storedLeadingZeros+gor.storedTrailingZeros))
-	value <<= gor.storedTrailingZeros
-	value = gor.storedValue ^ value
-	gor.storedValue = value
+	value, err = bitStream.ReadBits(64 - int(state.storedLeadingZeros+state.storedTrailingZeros))
+	if err != nil {
+		return err
+	}
+	value <<= state.storedTrailingZeros
+	value = state.storedValue ^ value
+	state.storedValue = value
 
 	return nil
 }
+
+// Return the width of the length of the significant bits
+func (state *gorillaState) lengthBits() int {
+	if state.wordBits == 32 {
+		return 5
+	}
+	return 6
+}
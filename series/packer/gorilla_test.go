@@ -71,8 +71,8 @@ func TestGorilla_Float64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 10, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(73), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+10+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(73), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -88,8 +88,8 @@ func TestGorilla_Float64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 26, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(195), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+26+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(204), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -105,8 +105,8 @@ func TestGorilla_Float64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 12, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(94), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+12+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(95), packedBits(buffer))           // Num bits
 
 	res := make([]float64, 10)
 	gor.Unpack(buffer, res, Delta, 0.0)
@@ -129,8 +129,8 @@ func TestGorilla_Float64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Offset, -9.0)
-	assert.Equal(t, 26, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(195), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+26+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(204), packedBits(buffer))          // Num bits
 
 	res := make([]float64, 10)
 	gor.Unpack(buffer, res, Offset, -9.0)
@@ -153,8 +153,8 @@ func TestGorilla_Float64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 23, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(174), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+23+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(183), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -325,8 +325,8 @@ func TestGorilla_Int64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 11, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(83), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+11+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(83), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -342,8 +342,8 @@ func TestGorilla_Int64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 21, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(152), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+21+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(161), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -359,8 +359,8 @@ func TestGorilla_Int64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 12, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(95), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+12+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(96), packedBits(buffer))           // Num bits
 
 	res := make([]int64, 10)
 	gor.Unpack(buffer, res, Delta, 0.0)
@@ -383,8 +383,8 @@ func TestGorilla_Int64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Offset, -9.0)
-	assert.Equal(t, 21, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(152), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+21+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(161), packedBits(buffer))          // Num bits
 
 	res := make([]int64, 10)
 	gor.Unpack(buffer, res, Offset, -9.0)
@@ -407,8 +407,8 @@ func TestGorilla_Int64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 14, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(103), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+14+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(112), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -579,8 +579,8 @@ func TestGorilla_Unt64_CompressionCheckForConst(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 10, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(73), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+10+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(73), packedBits(buffer))           // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -596,8 +596,8 @@ func TestGorilla_UInt64_CompressionCheckForSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 19, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(142), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+19+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(151), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing a monotonically increasing sequence of
@@ -613,8 +613,8 @@ func TestGorilla_UInt64_CompressionCheckForDeltaSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Delta, 0.0)
-	assert.Equal(t, 11, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(85), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+11+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(86), packedBits(buffer))           // Num bits
 
 	res := make([]uint64, 10)
 	gor.Unpack(buffer, res, Delta, 0.0)
@@ -637,8 +637,8 @@ func TestGorilla_UInt64_CompressionCheckForOffsetSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, Offset, 9)
-	assert.Equal(t, 18, buffer.Len())      // Num bytes
-	assert.Equal(t, uint64(135), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+18+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(144), packedBits(buffer))          // Num bits
 
 	res := make([]uint64, 10)
 	gor.Unpack(buffer, res, Offset, 9.0)
@@ -661,8 +661,8 @@ func TestGorilla_UInt64_CompressionCheckForPingPongSequence(t *testing.T) {
 	buffer := &bytes.Buffer{}
 
 	gor.Pack(a, buffer, NOP, 0.0)
-	assert.Equal(t, 13, buffer.Len())     // Num bytes
-	assert.Equal(t, uint64(93), gor.size) // Num bits
+	assert.Equal(t, HeaderSize+13+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(102), packedBits(buffer))          // Num bits
 }
 
 // Tests the memory impact of storing 1 million large value sequence.
@@ -771,10 +771,7 @@ func BenchmarkGorillaFor_Unt64_UnpackingSequence(t *testing.B) {
 
 func BenchmarkGorillaFor_StockPrice(t *testing.B) {
 
-	prices := ReadStockPriceFile()
-	if prices == nil {
-		assert.FailNow(t, "Failed to read stock price data")
-	}
+	prices := stockPrices()
 
 	gor := NewGorilla[float64]()
 	buffer := &bytes.Buffer{}
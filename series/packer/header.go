@@ -0,0 +1,576 @@
+package packer
+
+// Every buffer produced by a packer starts with a fixed size block header that
+// makes the packed data self-describing. The header records everything that
+// is needed to decode the block (codec, element type, number of elements and
+// the pre-compact operation), so a packed buffer can be persisted or shipped
+// to another process and decoded by a fresh packer instance.
+//
+// Layout (little endian, HeaderSize bytes):
+//
+//	offset  size  field
+//	0       3     magic ("ATS")
+//	3       1     version
+//	4       1     codec id
+//	5       1     element type
+//	6       1     pack operation
+//	7       1     flags
+//	8       8     number of elements
+//	16      8     pack operation parameter (raw bits of the element type)
+//	24      8     number of bits in the packed payload
+//
+// The payload follows the header. When FlagStats is set, the payload is
+// followed by a statistics section of StatsSize bytes (see Stats). When
+// FlagValidity is set, a validity section marking the missing values follows
+// (see AppendValidity). When FlagLabels is set, a labels section holding the
+// dictionary of the codes of the block follows (see AppendLabels). When
+// FlagChecksum is set, the block ends with the
+// CRC32C (Castagnoli) checksum of all the preceding bytes of the block, stored
+// in ChecksumSize bytes.
+//
+// When FlagLossy is set, the payload starts with a section of LossySize bytes
+// recording the error bound of the lossy codec that produced the block. The
+// section is decoded into the ErrorMode and ErrorBound fields of the header.
+//
+//	offset  size  field
+//	0       8     error bound (float64)
+//	8       1     error mode
+//	9       7     reserved
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// Size of the block header in bytes
+const HeaderSize = 32
+
+// Size of the checksum at the end of a block in bytes
+const ChecksumSize = 4
+
+// Size of the error bound section at the start of the payload of lossy blocks
+const LossySize = 16
+
+var checksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Current version of the block header
+const HeaderVersion uint8 = 1
+
+var headerMagic = [3]byte{'A', 'T', 'S'}
+
+// Identifies the compaction algorithm used to produce a packed block.
+type CodecID uint8
+
+const (
+	CodecUnknown CodecID = iota
+	CodecChimp
+	CodecGorilla
+	CodecDeltaOfDelta
+	CodecSimple8b
+	CodecALP
+	CodecChimp128
+	CodecRLE
+	CodecAdaptive
+	CodecQuantized
+	CodecDecimal
+)
+
+func (c CodecID) String() string {
+	switch c {
+	case CodecUnknown:
+		return "Unknown"
+	case CodecChimp:
+		return "Chimp"
+	case CodecGorilla:
+		return "Gorilla"
+	case CodecDeltaOfDelta:
+		return "DeltaOfDelta"
+	case CodecSimple8b:
+		return "Simple8b"
+	case CodecALP:
+		return "ALP"
+	case CodecChimp128:
+		return "Chimp128"
+	case CodecRLE:
+		return "RLE"
+	case CodecAdaptive:
+		return "Adaptive"
+	case CodecQuantized:
+		return "Quantized"
+	case CodecDecimal:
+		return "Decimal"
+	}
+	if name := CodecName(c); name != "" {
+		return name
+	}
+	return "Invalid"
+}
+
+// Identifies the type of the elements stored in a packed block.
+type ElemType uint8
+
+const (
+	ElemUnknown ElemType = iota
+	ElemInt64
+	ElemUInt64
+	ElemFloat64
+	ElemInt32
+	ElemUInt32
+	ElemFloat32
+	ElemDecimal
+)
+
+func (e ElemType) String() string {
+	switch e {
+	case ElemUnknown:
+		return "Unknown"
+	case ElemInt64:
+		return "Int64"
+	case ElemUInt64:
+		return "UInt64"
+	case ElemFloat64:
+		return "Float64"
+	case ElemInt32:
+		return "Int32"
+	case ElemUInt32:
+		return "UInt32"
+	case ElemFloat32:
+		return "Float32"
+	case ElemDecimal:
+		return "Decimal"
+	}
+	return "Invalid"
+}
+
+// Return the size in bytes of an element of the type, or 0 for unknown types.
+func (e ElemType) Size() uint64 {
+	switch e {
+	case ElemInt64, ElemUInt64, ElemFloat64, ElemDecimal:
+		return 8
+	case ElemInt32, ElemUInt32, ElemFloat32:
+		return 4
+	}
+	return 0
+}
+
+// Header flags
+const (
+	// Integer and float32 values had their 32-bit halves swapped before
+	// compaction
+	FlagSmallInts uint8 = 1 << iota
+
+	// Block holds a statistics section after the payload
+	FlagStats
+
+	// Payload ends with an index of restart points (see Seeker)
+	FlagIndex
+
+	// Block ends with a checksum
+	FlagChecksum
+
+	// Values of the block are approximations within the error bound recorded
+	// in the header (see ErrorBound)
+	FlagLossy
+
+	// Block holds a validity section marking its missing values
+	FlagValidity
+
+	// Block holds a labels section, the values being codes into its labels
+	FlagLabels
+)
+
+// Identifies how the error bound of a lossy block applies to its values.
+type ErrorMode uint8
+
+const (
+	// Every value is within the error bound of the original value
+	AbsoluteError ErrorMode = iota
+
+	// Every value is within the error bound times the magnitude of the
+	// original value
+	RelativeError
+)
+
+func (m ErrorMode) String() string {
+	switch m {
+	case AbsoluteError:
+		return "Absolute"
+	case RelativeError:
+		return "Relative"
+	}
+	return "Invalid"
+}
+
+// Block header of a packed buffer.
+type Header struct {
+	Version     uint8
+	Codec       CodecID
+	ElemType    ElemType
+	Op          PackOp
+	Flags       uint8
+	NumElements uint64
+	OpParam     uint64
+	NumBits     uint64
+
+	// Error bound of a lossy block (see FlagLossy), read from the start of
+	// its payload
+	ErrorMode  ErrorMode
+	ErrorBound float64
+
+	// Size of the validity section (see FlagValidity) in bytes, read from the
+	// start of the section
+	ValiditySize uint64
+
+	// Size of the labels section (see FlagLabels) in bytes, read from the
+	// start of the section
+	LabelsSize uint64
+}
+
+// Read the block header at the start of the src buffer. The buffer is not
+// consumed. Blocks announcing more elements than their payload can hold are
+// rejected as corrupt (see ErrCorrupt). Returns the header along with nil
+// error, otherwise returns the error.
+func ReadHeader(src *bytes.Buffer) (Header, error) {
+	if src == nil {
+		return Header{}, errors.New("nil buffer")
+	}
+	return decodeHeader(src.Bytes())
+}
+
+// Return true if the specified flag is set in the header
+func (hdr *Header) HasFlag(flag uint8) bool {
+	return hdr.Flags&flag != 0
+}
+
+// Return the size of the packed block (header, payload and optional sections)
+// in bytes
+func (hdr *Header) BlockSize() uint64 {
+	size := hdr.payloadEnd()
+	if hdr.HasFlag(FlagStats) {
+		size += StatsSize
+	}
+	if hdr.HasFlag(FlagValidity) {
+		size += hdr.ValiditySize
+	}
+	if hdr.HasFlag(FlagLabels) {
+		size += hdr.LabelsSize
+	}
+	if hdr.HasFlag(FlagChecksum) {
+		size += ChecksumSize
+	}
+	return size
+}
+
+// Verify the checksum of the block at the start of the src buffer. Returns nil
+// if the block is valid or has no checksum, otherwise returns the error.
+func VerifyBlock(src *bytes.Buffer) error {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return err
+	}
+	return verifyChecksum(&hdr, src.Bytes())
+}
+
+// Return the element type identifier of T
+func ElemTypeOf[T Number]() ElemType {
+	return elemTypeOf[T]()
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func (hdr *Header) encode(b []byte) {
+	copy(b[0:3], headerMagic[:])
+	b[3] = hdr.Version
+	b[4] = uint8(hdr.Codec)
+	b[5] = uint8(hdr.ElemType)
+	b[6] = uint8(hdr.Op)
+	b[7] = hdr.Flags
+	binary.LittleEndian.PutUint64(b[8:], hdr.NumElements)
+	binary.LittleEndian.PutUint64(b[16:], hdr.OpParam)
+	binary.LittleEndian.PutUint64(b[24:], hdr.NumBits)
+}
+
+// Return the offset of the end of the payload within the block
+func (hdr *Header) payloadEnd() uint64 {
+	return HeaderSize + (hdr.NumBits+7)/8
+}
+
+// Return the offset of the validity section within the block
+func (hdr *Header) validityStart() uint64 {
+	start := hdr.payloadEnd()
+	if hdr.HasFlag(FlagStats) {
+		start += StatsSize
+	}
+	return start
+}
+
+// Return the offset of the labels section within the block
+func (hdr *Header) labelsStart() uint64 {
+	start := hdr.validityStart()
+	if hdr.HasFlag(FlagValidity) {
+		start += hdr.ValiditySize
+	}
+	return start
+}
+
+// Minimum number of payload bits of an element of the codecs whose payload
+// bounds the number of elements of a block, so that a forged element count is
+// rejected before buffers are sized from it. Other codecs store runs of equal
+// values in a constant number of bits (the runs of RLE and the zero width
+// integer blocks of Simple8b, ALP, Quantized and Decimal), so their blocks may
+// legitimately hold any number of elements.
+var minElementBits = map[CodecID]uint64{
+	CodecChimp:        2,
+	CodecChimp128:     2 + chimp128IndexBits,
+	CodecGorilla:      1,
+	CodecDeltaOfDelta: 1,
+}
+
+func decodeHeader(b []byte) (Header, error) {
+	if len(b) < HeaderSize {
+		return Header{}, fmt.Errorf("%w: buffer too small for block header", ErrTruncated)
+	}
+	if !bytes.Equal(b[0:3], headerMagic[:]) {
+		return Header{}, fmt.Errorf("%w: invalid magic", ErrBadHeader)
+	}
+
+	hdr := Header{
+		Version:     b[3],
+		Codec:       CodecID(b[4]),
+		ElemType:    ElemType(b[5]),
+		Op:          PackOp(b[6]),
+		Flags:       b[7],
+		NumElements: binary.LittleEndian.Uint64(b[8:]),
+		OpParam:     binary.LittleEndian.Uint64(b[16:]),
+		NumBits:     binary.LittleEndian.Uint64(b[24:]),
+	}
+	if hdr.Version != HeaderVersion {
+		return Header{}, fmt.Errorf("%w: unsupported version %d", ErrBadHeader, hdr.Version)
+	}
+	if hdr.NumBits > math.MaxUint64-7 || uint64(len(b)) < hdr.payloadEnd() {
+		return Header{}, fmt.Errorf("%w: buffer too small for block payload", ErrTruncated)
+	}
+	if minBits, ok := minElementBits[hdr.Codec]; ok && hdr.NumElements > hdr.NumBits/minBits {
+		return Header{}, fmt.Errorf("%w: element count exceeds block payload", ErrCorrupt)
+	}
+	if hdr.HasFlag(FlagValidity) {
+		start := hdr.validityStart()
+		if uint64(len(b)) < start+ValidityPrefixSize {
+			return Header{}, fmt.Errorf("%w: buffer too small for validity section", ErrTruncated)
+		}
+		hdr.ValiditySize = ValidityPrefixSize + uint64(binary.LittleEndian.Uint32(b[start:]))
+	}
+	if hdr.HasFlag(FlagLabels) {
+		start := hdr.labelsStart()
+		if uint64(len(b)) < start+LabelsPrefixSize {
+			return Header{}, fmt.Errorf("%w: buffer too small for labels section", ErrTruncated)
+		}
+		hdr.LabelsSize = LabelsPrefixSize + uint64(binary.LittleEndian.Uint32(b[start:]))
+	}
+	if uint64(len(b)) < hdr.BlockSize() {
+		return Header{}, fmt.Errorf("%w: buffer too small for block payload", ErrTruncated)
+	}
+
+	if hdr.HasFlag(FlagLossy) {
+		if hdr.NumBits < 8*LossySize {
+			return Header{}, fmt.Errorf("%w: payload too small for error bound", ErrCorrupt)
+		}
+		hdr.ErrorBound = math.Float64frombits(binary.LittleEndian.Uint64(b[HeaderSize:]))
+		hdr.ErrorMode = ErrorMode(b[HeaderSize+8])
+	}
+
+	return hdr, nil
+}
+
+// Reserves space for the block header at the end of dst and returns the
+// offset of the header within dst.
+func reserveHeader(dst *bytes.Buffer) int {
+	start := dst.Len()
+	dst.Write(make([]byte, HeaderSize))
+	return start
+}
+
+// Writes the header into the space previously reserved by reserveHeader and
+// seals the block with its checksum. The payload must be the last data in dst.
+func writeHeader(dst *bytes.Buffer, start int, hdr *Header) {
+	hdr.Version = HeaderVersion
+	hdr.Flags |= FlagChecksum
+	hdr.encode(dst.Bytes()[start : start+HeaderSize])
+	writeChecksum(dst, start)
+}
+
+// Appends the error bound section of a lossy block at the end of dst, as the
+// start of its payload
+func writeLossySection(dst *bytes.Buffer, mode ErrorMode, bound float64) {
+	var b [LossySize]byte
+	binary.LittleEndian.PutUint64(b[0:], math.Float64bits(bound))
+	b[8] = uint8(mode)
+	dst.Write(b[:])
+}
+
+// Appends the checksum of the block starting at the specified offset of dst
+func writeChecksum(dst *bytes.Buffer, start int) {
+	var b [ChecksumSize]byte
+	binary.LittleEndian.PutUint32(b[:], crc32.Checksum(dst.Bytes()[start:], checksumTable))
+	dst.Write(b[:])
+}
+
+// Verifies the checksum of the block at the start of b, if it has one
+func verifyChecksum(hdr *Header, b []byte) error {
+	if !hdr.HasFlag(FlagChecksum) {
+		return nil
+	}
+
+	end := hdr.BlockSize() - ChecksumSize
+	expected := binary.LittleEndian.Uint32(b[end:])
+	if crc32.Checksum(b[:end], checksumTable) != expected {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// Return the size of a packed block with a payload of the specified number of
+// bits, sealed with a checksum
+func packedSize(numBits uint64) uint64 {
+	return HeaderSize + (numBits+7)/8 + ChecksumSize
+}
+
+// Decodes the header of the block in src, verifies its checksum and checks
+// that it has been produced by the specified codec for elements of type T.
+// Returns the header and the packed payload.
+func openBlock[T Number](src *bytes.Buffer, codec CodecID) (Header, []byte, error) {
+	hdr, payload, err := peekBlock[T](src, codec)
+	if err != nil {
+		return hdr, nil, err
+	}
+	if err := verifyChecksum(&hdr, src.Bytes()); err != nil {
+		return hdr, nil, err
+	}
+	return hdr, payload, nil
+}
+
+// Same as openBlock without verifying the checksum of the block, for reads
+// touching a small part of the block.
+func peekBlock[T Number](src *bytes.Buffer, codec CodecID) (Header, []byte, error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return hdr, nil, err
+	}
+	if hdr.Codec != codec {
+		return hdr, nil, errors.New("block packed with a different codec")
+	}
+	if hdr.ElemType != elemTypeOf[T]() {
+		return hdr, nil, errors.New("block holds a different element type")
+	}
+
+	return hdr, src.Bytes()[HeaderSize:hdr.payloadEnd()], nil
+}
+
+// Return the element type identifier of T
+func elemTypeOf[T Number]() ElemType {
+	var v T
+	switch any(v).(type) {
+	case int64:
+		return ElemInt64
+	case uint64:
+		return ElemUInt64
+	case float64:
+		return ElemFloat64
+	case int32:
+		return ElemInt32
+	case uint32:
+		return ElemUInt32
+	case float32:
+		return ElemFloat32
+	case Decimal:
+		return ElemDecimal
+	}
+	return ElemUnknown
+}
+
+// Return true if the elements of the type are signed integers
+func (e ElemType) isSignedInt() bool {
+	return e == ElemInt64 || e == ElemInt32 || e == ElemDecimal
+}
+
+// Return true if the elements of the type are floating point values
+func (e ElemType) isFloat() bool {
+	return e == ElemFloat64 || e == ElemFloat32
+}
+
+// Return the raw bits of the value. Signed 32-bit integers are sign extended,
+// so that they keep their value as 64-bit integers.
+func toBits[T Number](v T) uint64 {
+	switch x := any(v).(type) {
+	case int64:
+		return uint64(x)
+	case uint64:
+		return x
+	case float64:
+		return math.Float64bits(x)
+	case int32:
+		return uint64(int64(x))
+	case uint32:
+		return uint64(x)
+	case float32:
+		return uint64(math.Float32bits(x))
+	case Decimal:
+		return uint64(x)
+	}
+	return 0
+}
+
+// Return the raw bits of the value as a word of the XOR based codecs. The
+// words of 32-bit values hold only their 32 bits, which the small integer
+// rotation (see FlagSmallInts) moves to the high half of the word.
+func toWord[T Number](v T) uint64 {
+	switch x := any(v).(type) {
+	case int32:
+		return uint64(uint32(x))
+	case uint32:
+		return uint64(x)
+	case float32:
+		return uint64(math.Float32bits(x))
+	}
+	return toBits(v)
+}
+
+// Return the raw bits of the value as a signed integer, 32-bit values being
+// sign extended. Deltas of unsigned values are negative for decreasing values.
+func toSigned[T Number](v T) int64 {
+	switch x := any(v).(type) {
+	case int32:
+		return int64(x)
+	case uint32:
+		return int64(int32(x))
+	}
+	return int64(toBits(v))
+}
+
+// Return the value represented by the raw bits
+func fromBits[T Number](u uint64) T {
+	var v T
+	switch p := any(&v).(type) {
+	case *int64:
+		*p = int64(u)
+	case *uint64:
+		*p = u
+	case *float64:
+		*p = math.Float64frombits(u)
+	case *int32:
+		*p = int32(u)
+	case *uint32:
+		*p = uint32(u)
+	case *float32:
+		*p = math.Float32frombits(uint32(u))
+	case *Decimal:
+		*p = Decimal(u)
+	}
+	return v
+}
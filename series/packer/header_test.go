@@ -0,0 +1,110 @@
+package packer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader_ReadHeader(t *testing.T) {
+
+	a := make([]float64, 10)
+	for i := range a {
+		a[i] = 9 + float64(i)
+	}
+
+	chimp := NewChimp[float64]()
+	buffer := &bytes.Buffer{}
+
+	chimp.Pack(a, buffer, Offset, -9.0)
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, HeaderVersion, hdr.Version)
+	assert.Equal(t, CodecChimp, hdr.Codec)
+	assert.Equal(t, ElemFloat64, hdr.ElemType)
+	assert.Equal(t, Offset, hdr.Op)
+	assert.Equal(t, uint64(10), hdr.NumElements)
+	assert.Equal(t, -9.0, fromBits[float64](hdr.OpParam))
+	assert.Equal(t, packedBits(buffer), hdr.NumBits)
+	assert.Equal(t, uint64(buffer.Len()), hdr.BlockSize())
+}
+
+func TestHeader_InvalidBuffer(t *testing.T) {
+
+	_, err := ReadHeader(&bytes.Buffer{})
+	assert.NotNil(t, err)
+
+	_, err = ReadHeader(bytes.NewBuffer(make([]byte, HeaderSize)))
+	assert.NotNil(t, err)
+
+	a := []float64{1, 2, 3}
+	buffer := &bytes.Buffer{}
+	NewChimp[float64]().Pack(a, buffer, NOP, 0.0)
+	buffer.Truncate(buffer.Len() - 1)
+
+	_, err = ReadHeader(buffer)
+	assert.NotNil(t, err)
+}
+
+// Unpacking with a packer instance that did not produce the buffer must give
+// back the original values.
+func TestHeader_UnpackWithFreshPacker(t *testing.T) {
+
+	a := make([]int64, 10)
+	for i := range a {
+		a[i] = int64(i) - 5
+	}
+
+	buffer := &bytes.Buffer{}
+	NewChimp[int64]().Pack(a, buffer, Delta, 0)
+
+	res := make([]int64, 10)
+	numElements, err := NewChimp[int64]().Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), numElements)
+	assert.Equal(t, a, res)
+
+	// Buffer is not consumed by unpack
+	res = make([]int64, 10)
+	_, err = NewChimp[int64]().Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, res)
+
+	buffer = &bytes.Buffer{}
+	NewGorilla[int64]().Pack(a, buffer, Delta, 0)
+
+	res = make([]int64, 10)
+	numElements, err = NewGorilla[int64]().Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), numElements)
+	assert.Equal(t, a, res)
+}
+
+func TestHeader_UnpackMismatch(t *testing.T) {
+
+	a := []float64{1, 2, 3}
+	buffer := &bytes.Buffer{}
+	NewChimp[float64]().Pack(a, buffer, NOP, 0.0)
+
+	// Different codec
+	res := make([]float64, 3)
+	_, err := NewGorilla[float64]().Unpack(buffer, res, NOP, 0.0)
+	assert.NotNil(t, err)
+
+	// Different element type
+	resU := make([]uint64, 3)
+	_, err = NewChimp[uint64]().Unpack(buffer, resU, NOP, 0)
+	assert.NotNil(t, err)
+
+	// Destination too small
+	_, err = NewChimp[float64]().Unpack(buffer, res[:2], NOP, 0.0)
+	assert.NotNil(t, err)
+}
+
+// Return the number of bits of the payload of the block in the buffer
+func packedBits(buffer *bytes.Buffer) uint64 {
+	hdr, _ := ReadHeader(buffer)
+	return hdr.NumBits
+}
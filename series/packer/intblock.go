@@ -0,0 +1,270 @@
+package packer
+
+// Integer block encoding shared by the integer oriented codecs. A block of
+// unsigned integers is stored relative to its minimum value (frame of
+// reference) and the residuals are packed with Simple-8b when all of them fit
+// in 60 bits and that is smaller than packing them with a fixed bit width.
+//
+// Layout:
+//
+//	64 bits   reference (minimum) value
+//	1 bit     mode (0: Simple-8b, 1: fixed width)
+//	Simple-8b:   sequence of 64-bit words
+//	fixed width: 7 bits width, followed by width bits per value
+//
+// Every Simple-8b word holds a 4-bit selector followed by 60 bits of payload
+// that packs selector dependent number of values of equal width. Selectors 0
+// and 1 encode runs of zero residuals without any payload. Values past the
+// end of the block are treated as zeros, so the last word can always use the
+// densest selector that fits the remaining values.
+//
+// Ref: Vo Ngoc Anh, Alistair Moffat. Index compression using 64-bit words.
+//      Software: Practice and Experience, 40(2): 131 - 147, 2010
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type simple8bSelector struct {
+	numValues int
+	numBits   int
+}
+
+var simple8bSelectors = [16]simple8bSelector{
+	{240, 0}, {120, 0}, {60, 1}, {30, 2}, {20, 3}, {15, 4}, {12, 5}, {10, 6},
+	{8, 7}, {7, 8}, {6, 10}, {5, 12}, {4, 15}, {3, 20}, {2, 30}, {1, 60},
+}
+
+const simple8bMaxValue uint64 = (1 << 60) - 1
+
+// Writes the values as an integer block and returns the number of bits that
+// were written. The values are modified in place to hold the residuals.
+func writeIntBlock(bitStream *bitstream.BitWriter, values []uint64) uint64 {
+	var ref uint64 = 0
+	var maxVal uint64 = 0
+	if len(values) > 0 {
+		ref = values[0]
+		for _, v := range values {
+			if v < ref {
+				ref = v
+			}
+		}
+		for ndx := range values {
+			values[ndx] -= ref
+			if values[ndx] > maxVal {
+				maxVal = values[ndx]
+			}
+		}
+	}
+
+	bitStream.WriteBits(ref, 64)
+	var size uint64 = 64 + 1
+
+	width := bits.Len64(maxVal)
+	fixedSize := 7 + uint64(len(values)*width)
+
+	if maxVal <= simple8bMaxValue {
+		words := simple8bEncode(values)
+		if uint64(64*len(words)) <= fixedSize {
+			bitStream.WriteBit(false)
+			for _, word := range words {
+				bitStream.WriteBits(word, 64)
+			}
+			return size + uint64(64*len(words))
+		}
+	}
+
+	bitStream.WriteBit(true)
+	bitStream.WriteBits(uint64(width), 7)
+	if width > 0 {
+		for _, v := range values {
+			bitStream.WriteBits(v, width)
+		}
+	}
+
+	return size + fixedSize
+}
+
+// Reads an integer block of len(dst) values into dst.
+func readIntBlock(bitStream *bitstream.BitReader, dst []uint64) error {
+	ref, err := bitStream.ReadBits(64)
+	if err != nil {
+		return streamError(err)
+	}
+	mode, err := bitStream.ReadBit()
+	if err != nil {
+		return streamError(err)
+	}
+
+	if mode {
+		width, err := bitStream.ReadBits(7)
+		if err != nil {
+			return streamError(err)
+		}
+		if width > 64 {
+			return fmt.Errorf("%w: invalid integer block width", ErrCorrupt)
+		}
+		for ndx := range dst {
+			var v uint64 = 0
+			if width > 0 {
+				if v, err = bitStream.ReadBits(int(width)); err != nil {
+					return streamError(err)
+				}
+			}
+			dst[ndx] = v + ref
+		}
+		return nil
+	}
+
+	for ndx := 0; ndx < len(dst); {
+		word, err := bitStream.ReadBits(64)
+		if err != nil {
+			return streamError(err)
+		}
+		ndx += simple8bDecode(word, dst[ndx:], ref)
+	}
+
+	return nil
+}
+
+// Packs the values (each at most simple8bMaxValue) into Simple-8b words.
+func simple8bEncode(values []uint64) []uint64 {
+	words := make([]uint64, 0, len(values)/8+1)
+
+	for ndx := 0; ndx < len(values); {
+		for sel, s := range simple8bSelectors {
+			if !simple8bFits(values[ndx:], s) {
+				continue
+			}
+
+			word := uint64(sel) << 60
+			if s.numBits > 0 {
+				for jdx := 0; jdx < s.numValues && ndx+jdx < len(values); jdx++ {
+					word |= values[ndx+jdx] << (jdx * s.numBits)
+				}
+			}
+			words = append(words, word)
+			ndx += s.numValues
+			break
+		}
+	}
+
+	return words
+}
+
+// Return true if the first numValues values (zeros past the end) fit in the
+// bit width of the selector.
+func simple8bFits(values []uint64, s simple8bSelector) bool {
+	n := s.numValues
+	if n > len(values) {
+		n = len(values)
+	}
+
+	if s.numBits == 0 {
+		for _, v := range values[:n] {
+			if v != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	limit := uint64(1)<<s.numBits - 1
+	for _, v := range values[:n] {
+		if v > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// Unpacks the values of the word, offset by ref, into dst and returns the
+// number of values that were unpacked.
+func simple8bDecode(word uint64, dst []uint64, ref uint64) int {
+	s := simple8bSelectors[word>>60]
+
+	n := s.numValues
+	if n > len(dst) {
+		n = len(dst)
+	}
+
+	if s.numBits == 0 {
+		for ndx := 0; ndx < n; ndx++ {
+			dst[ndx] = ref
+		}
+		return n
+	}
+
+	mask := uint64(1)<<s.numBits - 1
+	for ndx := 0; ndx < n; ndx++ {
+		dst[ndx] = ((word >> (ndx * s.numBits)) & mask) + ref
+	}
+	return n
+}
+
+// Reads an integer block of n values and calls visit for every run of equal
+// values, without storing the values. Runs of zero residuals (Simple-8b
+// selectors 0 and 1, or a fixed width of 0) are visited at once, other values
+// are visited one at a time.
+func walkIntBlock(bitStream *bitstream.BitReader, n uint64, visit func(v, count uint64)) error {
+	ref, err := bitStream.ReadBits(64)
+	if err != nil {
+		return streamError(err)
+	}
+	mode, err := bitStream.ReadBit()
+	if err != nil {
+		return streamError(err)
+	}
+
+	if mode {
+		width, err := bitStream.ReadBits(7)
+		if err != nil {
+			return streamError(err)
+		}
+		if width > 64 {
+			return fmt.Errorf("%w: invalid integer block width", ErrCorrupt)
+		}
+		if width == 0 {
+			if n > 0 {
+				visit(ref, n)
+			}
+			return nil
+		}
+		for ndx := uint64(0); ndx < n; ndx++ {
+			v, err := bitStream.ReadBits(int(width))
+			if err != nil {
+				return streamError(err)
+			}
+			visit(v+ref, 1)
+		}
+		return nil
+	}
+
+	for ndx := uint64(0); ndx < n; {
+		word, err := bitStream.ReadBits(64)
+		if err != nil {
+			return streamError(err)
+		}
+		s := simple8bSelectors[word>>60]
+
+		count := uint64(s.numValues)
+		if count > n-ndx {
+			count = n - ndx
+		}
+		ndx += count
+
+		if s.numBits == 0 {
+			visit(ref, count)
+			continue
+		}
+		mask := uint64(1)<<s.numBits - 1
+		for i := 0; i < int(count); i++ {
+			visit(((word>>(i*s.numBits))&mask)+ref, 1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package packer
+
+// Labels of a packed block of codes. Blocks holding the codes of categorical
+// values (see series.CategoricalSeries) carry the dictionary of their codes in
+// a labels section after the validity section, so the block can be decoded
+// into labels on its own. The code of a label is its position in the section.
+//
+// Layout (little endian):
+//
+//	offset  size  field
+//	0       4     size n of the labels in bytes
+//	4       n     number of labels as an unsigned varint, followed for every
+//	              label by its length in bytes as an unsigned varint and its
+//	              bytes
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Size of the prefix of the labels section holding the size of the labels
+const LabelsPrefixSize = 4
+
+// Append the labels section holding the labels of the codes of the packed
+// block at the start of the dst buffer and flag it in the block header. The
+// block must be the last data in the buffer and must not already hold a labels
+// section. Returns nil if the section was appended, otherwise returns the
+// error.
+func AppendLabels(dst *bytes.Buffer, labels []string) error {
+	hdr, err := ReadHeader(dst)
+	if err != nil {
+		return err
+	}
+	if hdr.HasFlag(FlagLabels) {
+		return errors.New("block already holds a labels section")
+	}
+	if uint64(dst.Len()) != hdr.BlockSize() {
+		return errors.New("block is not the last data in the buffer")
+	}
+
+	data := binary.AppendUvarint(nil, uint64(len(labels)))
+	for _, label := range labels {
+		data = binary.AppendUvarint(data, uint64(len(label)))
+		data = append(data, label...)
+	}
+	if uint64(len(data)) > math.MaxUint32 {
+		return errors.New("labels section too large")
+	}
+
+	// The labels section goes before the checksum, which is recomputed
+	sealed := hdr.HasFlag(FlagChecksum)
+	if sealed {
+		dst.Truncate(dst.Len() - ChecksumSize)
+	}
+
+	var prefix [LabelsPrefixSize]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(data)))
+	dst.Write(prefix[:])
+	dst.Write(data)
+
+	hdr.Flags |= FlagLabels
+	hdr.encode(dst.Bytes()[:HeaderSize])
+	if sealed {
+		writeChecksum(dst, 0)
+	}
+
+	return nil
+}
+
+// Read the labels of the codes of the packed block in the src buffer, ordered
+// by code. The buffer is not consumed. Returns the labels along with nil
+// error, or (nil, nil) if the block has no labels section. Otherwise returns
+// (nil, error).
+func ReadLabels(src *bytes.Buffer) ([]string, error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return nil, err
+	}
+	if !hdr.HasFlag(FlagLabels) {
+		return nil, nil
+	}
+	if err := verifyChecksum(&hdr, src.Bytes()); err != nil {
+		return nil, err
+	}
+
+	data := src.Bytes()[hdr.labelsStart()+LabelsPrefixSize : hdr.labelsStart()+hdr.LabelsSize]
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: invalid labels section", ErrCorrupt)
+	}
+	data = data[n:]
+
+	labels := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || size > uint64(len(data)-n) {
+			return nil, fmt.Errorf("%w: invalid labels section", ErrCorrupt)
+		}
+		labels = append(labels, string(data[n:n+int(size)]))
+		data = data[n+int(size):]
+	}
+
+	return labels, nil
+}
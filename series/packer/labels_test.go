@@ -0,0 +1,62 @@
+package packer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels_AppendRead(t *testing.T) {
+
+	codes := []uint32{0, 0, 1, 1, 1, 2, 0, 0}
+	labels := []string{"idle", "running", ""}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, NewRLE[uint32]().Pack(codes, buffer, NOP, 0))
+	res, err := ReadLabels(buffer)
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+
+	// Sections are appended in order
+	assert.Nil(t, AppendStats(buffer, ComputeStats(codes)))
+	assert.Nil(t, AppendValidity(buffer, bitmap.FromBools(
+		[]bool{true, true, true, true, true, true, false, true})))
+	assert.Nil(t, AppendLabels(buffer, labels))
+	assert.NotNil(t, AppendLabels(buffer, labels))
+	assert.NotNil(t, AppendValidity(buffer, bitmap.New()))
+	assert.NotNil(t, AppendStats(buffer, ComputeStats(codes)))
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.True(t, hdr.HasFlag(FlagLabels))
+	assert.Equal(t, uint64(buffer.Len()), hdr.BlockSize())
+	assert.Nil(t, VerifyBlock(buffer))
+
+	res, err = ReadLabels(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, labels, res)
+
+	// Other sections and the codes are still readable
+	valid, err := ReadValidity(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), valid.Count())
+	stats, err := ReadStats[uint32](buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), stats.Max)
+	dst := make([]uint32, len(codes))
+	_, err = NewRLE[uint32]().Unpack(buffer, dst, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, codes, dst)
+
+	// Corrupt and truncated sections are rejected
+	corrupt := bytes.NewBuffer(append([]byte{}, buffer.Bytes()...))
+	corrupt.Bytes()[int(hdr.labelsStart())+LabelsPrefixSize+1] ^= 0x40
+	_, err = ReadLabels(corrupt)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	_, err = ReadHeader(bytes.NewBuffer(buffer.Bytes()[:buffer.Len()-6]))
+	assert.True(t, errors.Is(err, ErrTruncated))
+}
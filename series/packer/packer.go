@@ -4,25 +4,31 @@ import (
 	"bytes"
 )
 
+// Element types of the packed values. 32-bit values are packed as 32-bit
+// words: the XOR based codecs keep them in the high half of their 64-bit words
+// and the integer codecs pack them at the width of their values. Decimal
+// values are packed as their int64 mantissas.
 type Number interface {
-	int64 | uint64 | float64
+	int64 | uint64 | float64 | int32 | uint32 | float32 | Decimal
 }
 
-// Packer interface specification
+// Packer interface specification. Packers hold no encoding or decoding state:
+// the state of every call lives in per-call encoder and decoder objects, so a
+// single packer can be shared by many frames and used from several goroutines
+// at once. The number of elements and the size of a packed block are read
+// from its header (see ReadHeader).
 type Packer[T Number] interface {
 
-	// Packs the float64 data in the src slice to the dst buffer and returns
-	// nil if packing was completed successfuly. Otherwise, returns the error.
+	// Packs the data in the src slice to the dst buffer and returns nil if
+	// packing was completed successfuly. Otherwise, returns the error. The
+	// packed data is preceded by a block header (see Header).
 	Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error
 
-	// Unpacks the float64 data in the src buffer to the dst slice and returns
-	// number of elements unpacked along with nil error. Otherwise, returns (0,
-	// error).
+	// Unpacks the data in the src buffer to the dst slice and returns number
+	// of elements unpacked along with nil error. Otherwise, returns (0,
+	// error). The block header in src takes precedence over op and opParam.
 	Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error)
 
-	// Return the size of the packed data
-	PackedSize() uint64
-
-	// Return the number of elements in the frame
-	NumElements() uint64
+	// Return the id of the codec
+	Codec() CodecID
 }
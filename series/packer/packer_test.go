@@ -0,0 +1,155 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/rmravindran/ats/series/gen"
+	"github.com/stretchr/testify/assert"
+)
+
+// A single packer must pack and unpack many blocks from several goroutines at
+// once.
+func TestPacker_ConcurrentUse(t *testing.T) {
+
+	series := make([][]float64, 8)
+	for s := range series {
+		series[s] = make([]float64, 1000)
+		for i := range series[s] {
+			series[s][i] = math.Round((float64(s)*10+math.Sin(float64(i)/7.0))*100) / 100
+		}
+	}
+
+	for _, id := range Codecs() {
+		if !Supports[float64](id) || !CanPack(id) {
+			continue
+		}
+		p, err := New[float64](id)
+		assert.Nil(t, err)
+		assert.Equal(t, id, p.Codec())
+
+		var wg sync.WaitGroup
+		buffers := make([]*bytes.Buffer, len(series))
+		for s := range series {
+			wg.Add(1)
+			go func(s int) {
+				defer wg.Done()
+				buffers[s] = &bytes.Buffer{}
+				assert.Nil(t, p.Pack(series[s], buffers[s], NOP, 0))
+			}(s)
+		}
+		wg.Wait()
+
+		for s := range series {
+			for w := 0; w < 4; w++ {
+				wg.Add(1)
+				go func(s int) {
+					defer wg.Done()
+					res := make([]float64, len(series[s]))
+					numElements, err := p.Unpack(buffers[s], res, NOP, 0)
+					assert.Nil(t, err, id.String())
+					assert.Equal(t, uint64(len(res)), numElements, id.String())
+					assert.Equal(t, series[s], res, id.String())
+				}(s)
+			}
+		}
+		wg.Wait()
+	}
+}
+
+// Return the prices of the stock price file, or a random walk of prices with
+// two decimal digits when the file is not available.
+func stockPrices() []float64 {
+	if prices := ReadStockPriceFile(); prices != nil {
+		return prices
+	}
+
+	prices := gen.RandomWalk[float64](42, 1000000, 100, 0.05)
+	for i := range prices {
+		prices[i] = math.Round(prices[i]*100) / 100
+	}
+	return prices
+}
+
+// Every codec supporting a 32-bit type must round trip its values, including
+// the extremes of the type, with every op.
+func TestPacker_32BitTypes(t *testing.T) {
+
+	floats := gen.Sinusoid[float32](1, 1000, 20, 5, 100, 0.5)
+	for i := range floats {
+		floats[i] = float32(math.Round(float64(floats[i])*4) / 4)
+	}
+	floats = append(floats, math.MaxFloat32, -math.MaxFloat32, float32(math.Inf(1)))
+
+	ints := gen.Steps[int32](1, 1000, -500, 500, 10)
+	ints = append(ints, math.MinInt32, math.MaxInt32, -1, 0)
+
+	uints := gen.Counter[uint32](1, 1000, 100, 0.01)
+	uints = append(uints, math.MaxUint32, 0, 1)
+
+	checkAllCodecs[float32](t, floats[:1000], []PackOp{NOP, Offset, Delta})
+	checkAllCodecs[float32](t, floats, []PackOp{NOP})
+	checkAllCodecs[int32](t, ints, []PackOp{NOP, Offset, Delta})
+	checkAllCodecs[uint32](t, uints, []PackOp{NOP, Offset, Delta})
+
+	for _, interval := range []uint64{7, DefaultRestartInterval} {
+		checkDecoder[float32](t, NewChimp[float32]().WithRestartInterval(interval), floats, NOP, 0)
+		checkDecoder[int32](t, NewGorilla[int32]().WithRestartInterval(interval), ints, Delta, 3)
+		checkDecoder[uint32](t, NewChimp[uint32]().WithRestartInterval(interval), uints, Offset, 5)
+	}
+}
+
+// 32-bit values are packed as 32-bit words, which is more compact than
+// packing the same values widened to 64 bits.
+func TestPacker_32BitWords(t *testing.T) {
+
+	readings := gen.RandomWalk[float32](3, 10000, 21.5, 0.25)
+	widened := make([]float64, len(readings))
+	for i, v := range readings {
+		widened[i] = float64(v)
+	}
+
+	for _, id := range []CodecID{CodecChimp, CodecGorilla} {
+		p32, err := New[float32](id)
+		assert.Nil(t, err)
+		p64, err := New[float64](id)
+		assert.Nil(t, err)
+
+		b32 := &bytes.Buffer{}
+		b64 := &bytes.Buffer{}
+		assert.Nil(t, p32.Pack(readings, b32, NOP, 0))
+		assert.Nil(t, p64.Pack(widened, b64, NOP, 0))
+		assert.Less(t, packedBits(b32), packedBits(b64), id.String())
+	}
+
+	assert.Equal(t, uint64(4), ElemTypeOf[float32]().Size())
+	assert.Equal(t, uint64(8), ElemTypeOf[int64]().Size())
+}
+
+func checkAllCodecs[T Number](t *testing.T, values []T, ops []PackOp) {
+	for _, id := range Codecs() {
+		if !Supports[T](id) || !CanPack(id) {
+			continue
+		}
+		p, err := New[T](id)
+		assert.Nil(t, err)
+
+		for _, op := range ops {
+			buffer := &bytes.Buffer{}
+			err := p.Pack(values, buffer, op, values[0])
+			assert.Nil(t, err, id.String())
+
+			hdr, err := ReadHeader(buffer)
+			assert.Nil(t, err)
+			assert.Equal(t, ElemTypeOf[T](), hdr.ElemType)
+
+			res := make([]T, len(values))
+			numElements, err := p.Unpack(buffer, res, NOP, 0)
+			assert.Nil(t, err, id.String())
+			assert.Equal(t, uint64(len(values)), numElements)
+			assert.Equal(t, values, res, "%s %s", id.String(), ElemTypeOf[T]().String())
+		}
+	}
+}
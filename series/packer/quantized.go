@@ -0,0 +1,243 @@
+package packer
+
+// Lossy quantization of floating point values. Every value is replaced by the
+// nearest multiple k*q of the quantization step q, and the integers k are
+// stored with frame of reference bit packing (see writeIntBlock) as in ALP.
+//
+// With an absolute error bound e the step is 2e, so every unpacked value is
+// within e of the packed value. With a relative error bound r the step is
+// 2rm, m being the smallest non-zero magnitude of the frame, so every unpacked
+// value is within r|v| of the packed value v. Values that would not be
+// unpacked within the bound (NaN, infinities, magnitudes too large for the
+// step, zeros with Offset) are stored exactly as exceptions.
+//
+// The block is flagged with FlagLossy and the error bound is recorded at the
+// start of its payload (see Header.ErrorBound).
+//
+// Offset is applied on the float values before quantization, as in the other
+// packers. Delta is applied on the quantized integers, starting from zero,
+// which keeps the deltas exact.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type Quantized[T Number] struct {
+	mode  ErrorMode
+	bound float64
+}
+
+// Largest magnitude of a quantized value
+const quantizedMaxCode = float64(1 << 62)
+
+func init() {
+	// Packing requires an error bound, the registered packer only unpacks
+	// quantized blocks. Packers are created with NewQuantized.
+	mustRegisterDecoder(CodecQuantized, "quantized", func() Packer[float64] {
+		return NewQuantized[float64](AbsoluteError, 0)
+	})
+}
+
+// Create a new quantized packer keeping every value within the specified
+// absolute or relative error bound.
+func NewQuantized[T Number](mode ErrorMode, bound float64) *Quantized[T] {
+	return &Quantized[T]{
+		mode:  mode,
+		bound: bound,
+	}
+}
+
+// Packs the float data in the src slice to the dst buffer and returns nil if
+// packing was completed successfuly. Otherwise, returns the error.
+func (q *Quantized[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if elemTypeOf[T]() != ElemFloat64 {
+		return errors.New("unsupported type in pack")
+	}
+	if !(q.bound > 0) || math.IsInf(q.bound, 1) || q.mode > RelativeError {
+		return errors.New("quantized packer requires a positive error bound")
+	}
+
+	var offset float64 = 0
+	if op == Offset {
+		offset = float64(opParam)
+	}
+
+	step := q.step(src)
+	codes := make([]int64, len(src))
+	exceptions := make([]int, 0)
+	var last int64 = 0
+	for ndx := range src {
+		v := float64(src[ndx])
+		if op == Offset {
+			v = float64(src[ndx] + opParam)
+		}
+
+		code, ok := q.quantize(v, float64(src[ndx]), offset, step)
+		if !ok {
+			exceptions = append(exceptions, ndx)
+			code = last
+		}
+		codes[ndx] = code
+		last = code
+	}
+
+	values := make([]uint64, len(codes))
+	var prev int64 = 0
+	for ndx, code := range codes {
+		if op == Delta {
+			code, prev = code-prev, code
+		}
+		values[ndx] = uint64(code) ^ (1 << 63)
+	}
+
+	start := reserveHeader(dst)
+	writeLossySection(dst, q.mode, q.bound)
+
+	bitStream := bitstream.NewWriter(dst)
+	bitStream.WriteBits(math.Float64bits(step), 64)
+	size := 8*LossySize + 64 + writeIntBlock(bitStream, values)
+
+	posBits := bits.Len64(uint64(len(src)))
+	bitStream.WriteBits(uint64(len(exceptions)), posBits)
+	size += uint64(posBits)
+	for _, ndx := range exceptions {
+		bitStream.WriteBits(uint64(ndx), posBits)
+		bitStream.WriteBits(math.Float64bits(float64(src[ndx])), 64)
+		size += uint64(posBits + 64)
+	}
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecQuantized,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		Flags:       FlagLossy,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     size,
+	})
+
+	return nil
+}
+
+// Unpacks the float data in the src buffer to the dst slice and returns
+// number of elements unpacked along with nil error. Otherwise, returns (0,
+// error). The block header in src takes precedence over op and opParam.
+func (q *Quantized[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecQuantized)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if !hdr.HasFlag(FlagLossy) {
+		return 0, fmt.Errorf("%w: missing error bound in unpack", ErrCorrupt)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+
+	bitStream := bitstream.NewReader(bytes.NewReader(payload[LossySize:]))
+	u, err := bitStream.ReadBits(64)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	step := math.Float64frombits(u)
+
+	values := make([]uint64, hdr.NumElements)
+	if err := readIntBlock(bitStream, values); err != nil {
+		return 0, streamError(err)
+	}
+
+	var prev int64 = 0
+	for ndx, u := range values {
+		code := int64(u ^ (1 << 63))
+		if op == Delta {
+			code += prev
+			prev = code
+		}
+		dst[ndx] = T(float64(code) * step)
+		if op == Offset {
+			dst[ndx] -= opParam
+		}
+	}
+
+	posBits := bits.Len64(hdr.NumElements)
+	numExceptions, err := bitStream.ReadBits(posBits)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	for ndx := uint64(0); ndx < numExceptions; ndx++ {
+		pos, err := bitStream.ReadBits(posBits)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		v, err := bitStream.ReadBits(64)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		if pos >= hdr.NumElements {
+			return 0, fmt.Errorf("%w: invalid exception position in unpack", ErrCorrupt)
+		}
+		dst[pos] = T(math.Float64frombits(v))
+	}
+
+	return hdr.NumElements, nil
+}
+
+// Return the id of the codec
+func (q *Quantized[T]) Codec() CodecID {
+	return CodecQuantized
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the quantization step of the values
+func (q *Quantized[T]) step(values []T) float64 {
+	if q.mode == AbsoluteError {
+		return 2 * q.bound
+	}
+
+	minAbs := math.Inf(1)
+	for _, v := range values {
+		if a := math.Abs(float64(v)); a > 0 && a < minAbs {
+			minAbs = a
+		}
+	}
+
+	step := 2 * q.bound * minAbs
+	if step > 0 && !math.IsInf(step, 1) {
+		return step
+	}
+	return 1
+}
+
+// Quantizes the value v, the original value shifted by offset. Returns false
+// if the unpacked value would not be within the error bound of the original
+// value.
+func (q *Quantized[T]) quantize(v, original, offset, step float64) (int64, bool) {
+	scaled := math.Round(v / step)
+	if !(math.Abs(scaled) < quantizedMaxCode) {
+		return 0, false
+	}
+
+	code := int64(scaled)
+	allowed := q.bound
+	if q.mode == RelativeError {
+		allowed = q.bound * math.Abs(original)
+	}
+	if !(math.Abs(float64(code)*step-offset-original) <= allowed) {
+		return 0, false
+	}
+
+	return code, true
+}
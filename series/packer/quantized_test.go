@@ -0,0 +1,154 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantized_Float64_AbsoluteError(t *testing.T) {
+
+	a := sensorReadings(5000)
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+		err := NewQuantized[float64](AbsoluteError, 0.01).Pack(a, buffer, op, -20.0)
+		assert.Nil(t, err)
+
+		res := make([]float64, len(a))
+		numElements, err := NewQuantized[float64](AbsoluteError, 0.5).Unpack(buffer, res, NOP, 0.0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(len(a)), numElements)
+		for i := range a {
+			assert.LessOrEqual(t, math.Abs(res[i]-a[i]), 0.01, op.String())
+		}
+	}
+}
+
+func TestQuantized_Float64_RelativeError(t *testing.T) {
+
+	a := []float64{1e-3, -2.5, 0, 1234.5678, 7.25e6, -0.0421, 0, 3.14159}
+	res := make([]float64, len(a))
+
+	buffer := &bytes.Buffer{}
+	err := NewQuantized[float64](RelativeError, 0.001).Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	_, err = NewQuantized[float64](RelativeError, 0.001).Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	for i := range a {
+		assert.LessOrEqual(t, math.Abs(res[i]-a[i]), 0.001*math.Abs(a[i]))
+	}
+}
+
+// Values that cannot be quantized within the bound are stored exactly
+func TestQuantized_Float64_Exceptions(t *testing.T) {
+
+	a := []float64{21.5, math.NaN(), 21.52, math.Inf(1), 1e300, math.Inf(-1), 21.49}
+	res := make([]float64, len(a))
+
+	buffer := &bytes.Buffer{}
+	err := NewQuantized[float64](AbsoluteError, 0.01).Pack(a, buffer, Delta, 0.0)
+	assert.Nil(t, err)
+
+	_, err = NewQuantized[float64](AbsoluteError, 0.01).Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(res[1]))
+	for _, i := range []int{3, 4, 5} {
+		assert.Equal(t, a[i], res[i])
+	}
+	for _, i := range []int{0, 2, 6} {
+		assert.LessOrEqual(t, math.Abs(res[i]-a[i]), 0.01)
+	}
+}
+
+// The block is flagged as lossy and records the error bound in its header
+func TestQuantized_Header(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	err := NewQuantized[float64](RelativeError, 0.05).Pack(sensorReadings(100), buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, CodecQuantized, hdr.Codec)
+	assert.True(t, hdr.HasFlag(FlagLossy))
+	assert.Equal(t, RelativeError, hdr.ErrorMode)
+	assert.Equal(t, 0.05, hdr.ErrorBound)
+	assert.Equal(t, uint64(buffer.Len()), hdr.BlockSize())
+
+	// Lossless blocks have no error bound
+	buffer.Reset()
+	NewChimp[float64]().Pack(sensorReadings(100), buffer, NOP, 0.0)
+	hdr, err = ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.False(t, hdr.HasFlag(FlagLossy))
+	assert.Equal(t, 0.0, hdr.ErrorBound)
+}
+
+// The registered packer unpacks any quantized block, and is not created for
+// packing
+func TestQuantized_RegisteredPacker(t *testing.T) {
+
+	a := sensorReadings(100)
+	buffer := &bytes.Buffer{}
+	err := NewQuantized[float64](AbsoluteError, 0.1).Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	p, err := ForBuffer[float64](buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, CodecQuantized, p.Codec())
+
+	res := make([]float64, len(a))
+	_, err = p.Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	assert.InDelta(t, a[42], res[42], 0.1)
+
+	assert.NotNil(t, p.Pack(a, &bytes.Buffer{}, NOP, 0.0))
+	assert.NotNil(t, NewQuantized[float64](AbsoluteError, math.NaN()).Pack(a, &bytes.Buffer{}, NOP, 0.0))
+	assert.NotNil(t, NewQuantized[int64](AbsoluteError, 1).Pack([]int64{1}, &bytes.Buffer{}, NOP, 0))
+	assert.False(t, Supports[int64](CodecQuantized))
+
+	assert.False(t, CanPack(CodecQuantized))
+	assert.True(t, CanPack(CodecChimp))
+	_, err = New[float64](CodecQuantized)
+	assert.NotNil(t, err)
+	_, err = NewByName[float64]("quantized")
+	assert.NotNil(t, err)
+}
+
+// Noisy sensor readings should pack far better than with Chimp when a small
+// error is tolerated
+func TestQuantized_Float64_CompressionCheckForSensor(t *testing.T) {
+
+	a := sensorReadings(10000)
+
+	quantizedBuffer := &bytes.Buffer{}
+	NewQuantized[float64](AbsoluteError, 0.01).Pack(a, quantizedBuffer, Delta, 0.0)
+
+	chimpBuffer := &bytes.Buffer{}
+	NewChimp[float64]().Pack(a, chimpBuffer, NOP, 0.0)
+
+	assert.Less(t, 4*quantizedBuffer.Len(), chimpBuffer.Len())
+}
+
+// Adaptive packing never selects a lossy codec
+func TestQuantized_NotSelectedByAdaptive(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	config := AdaptiveConfig{Codecs: []CodecID{CodecQuantized}}
+	err := NewAdaptive[float64](config).Pack(sensorReadings(100), buffer, NOP, 0.0)
+	assert.NotNil(t, err)
+}
+
+// Return temperature readings of a noisy sensor
+func sensorReadings(n int) []float64 {
+	a := make([]float64, n)
+	for i := range a {
+		noise := math.Sin(float64(i)*12.9898) * 43758.5453
+		a[i] = 21.0 + 3.0*math.Sin(float64(i)/500.0) + 0.05*(noise-math.Floor(noise))
+	}
+	return a
+}
@@ -0,0 +1,224 @@
+package packer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// First codec id available for codecs registered outside of this package.
+const CodecUser CodecID = 128
+
+// Creates a new packer for elements of type T
+type Factory[T Number] func() Packer[T]
+
+type codecEntry struct {
+	id        CodecID
+	name      string
+	factories map[ElemType]any
+
+	// Packers of the codec only unpack blocks (see RegisterDecoder)
+	decodeOnly bool
+}
+
+var registry = struct {
+	sync.RWMutex
+	byID   map[CodecID]*codecEntry
+	byName map[string]CodecID
+}{
+	byID:   make(map[CodecID]*codecEntry),
+	byName: make(map[string]CodecID),
+}
+
+// Register the factory of the codec with the specified id and name for
+// elements of type T. A codec supporting several element types registers one
+// factory per type under the same id and name. Returns nil if the factory was
+// registered, otherwise returns the error.
+func Register[T Number](id CodecID, name string, factory Factory[T]) error {
+	return register(id, name, factory, false)
+}
+
+// Register the factory of a codec that cannot pack without parameters, such
+// as the error bound of a lossy codec (see Quantized). The packers created by
+// the factory only unpack blocks of the codec (see ForBuffer); New and
+// NewByName reject the codec, its packers being created with the constructor
+// of the codec instead. Returns nil if the factory was registered, otherwise
+// returns the error.
+func RegisterDecoder[T Number](id CodecID, name string, factory Factory[T]) error {
+	return register(id, name, factory, true)
+}
+
+// Create a new packer for elements of type T using the codec with the
+// specified id. Returns the packer along with nil error, otherwise returns
+// (nil, error), including for codecs registered with RegisterDecoder.
+func New[T Number](id CodecID) (Packer[T], error) {
+	factory, err := lookupFactory[T](id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory(), nil
+}
+
+// Create a new packer for elements of type T using the codec registered under
+// the specified name.
+func NewByName[T Number](name string) (Packer[T], error) {
+	id, ok := LookupCodec(name)
+	if !ok {
+		return nil, errors.New("unknown codec")
+	}
+	return New[T](id)
+}
+
+// Create a new packer that is able to unpack the block in the src buffer,
+// based on the codec recorded in the block header.
+func ForBuffer[T Number](src *bytes.Buffer) (Packer[T], error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return nil, err
+	}
+	factory, err := lookupFactory[T](hdr.Codec, false)
+	if err != nil {
+		return nil, err
+	}
+	return factory(), nil
+}
+
+// Return the id of the codec registered under the specified name.
+func LookupCodec(name string) (CodecID, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	id, ok := registry.byName[name]
+	return id, ok
+}
+
+// Return the name of the codec registered under the specified id, otherwise
+// returns an empty string.
+func CodecName(id CodecID) string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	if entry, ok := registry.byID[id]; ok {
+		return entry.name
+	}
+	return ""
+}
+
+// Return the ids of all registered codecs in ascending order.
+func Codecs() []CodecID {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	ids := make([]CodecID, 0, len(registry.byID))
+	for id := range registry.byID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// Return true if the codec with the specified id supports elements of type T.
+func Supports[T Number](id CodecID) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entry, ok := registry.byID[id]
+	if !ok {
+		return false
+	}
+	_, ok = entry.factories[elemTypeOf[T]()]
+	return ok
+}
+
+// Return true if packers of the codec with the specified id are created by
+// New, false if the codec is unknown or only registered for decoding (see
+// RegisterDecoder).
+func CanPack(id CodecID) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entry, ok := registry.byID[id]
+	return ok && !entry.decodeOnly
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func register[T Number](id CodecID, name string, factory Factory[T], decodeOnly bool) error {
+	if id == CodecUnknown {
+		return errors.New("invalid codec id")
+	}
+	if name == "" || factory == nil {
+		return errors.New("codec requires a name and a factory")
+	}
+
+	elemType := elemTypeOf[T]()
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	entry, ok := registry.byID[id]
+	if !ok {
+		if _, taken := registry.byName[name]; taken {
+			return errors.New("codec name already registered")
+		}
+		entry = &codecEntry{
+			id: id, name: name, factories: make(map[ElemType]any), decodeOnly: decodeOnly}
+		registry.byID[id] = entry
+		registry.byName[name] = id
+	} else if entry.name != name {
+		return errors.New("codec id already registered under a different name")
+	} else if entry.decodeOnly != decodeOnly {
+		return errors.New("codec already registered with a different packing support")
+	}
+
+	if _, ok := entry.factories[elemType]; ok {
+		return errors.New("codec already registered for the element type")
+	}
+	entry.factories[elemType] = factory
+
+	return nil
+}
+
+func mustRegister[T Number](id CodecID, name string, factory Factory[T]) {
+	if err := Register(id, name, factory); err != nil {
+		panic(err)
+	}
+}
+
+func mustRegisterDecoder[T Number](id CodecID, name string, factory Factory[T]) {
+	if err := RegisterDecoder(id, name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Return the factory of the codec with the specified id for elements of type
+// T along with nil error, otherwise returns (nil, error). Codecs registered
+// with RegisterDecoder are rejected unless the packer only unpacks. The
+// factory is read under the lock since factories of the codec may be
+// registered concurrently.
+func lookupFactory[T Number](id CodecID, pack bool) (Factory[T], error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	entry, ok := registry.byID[id]
+	if !ok {
+		return nil, errors.New("unknown codec")
+	}
+	if pack && entry.decodeOnly {
+		return nil, fmt.Errorf(
+			"codec %s cannot pack without parameters, create its packer with its constructor",
+			entry.name)
+	}
+	factory, ok := entry.factories[elemTypeOf[T]()].(Factory[T])
+	if !ok {
+		return nil, errors.New("codec does not support the element type")
+	}
+
+	return factory, nil
+}
@@ -0,0 +1,108 @@
+package packer
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_BuiltinCodecs(t *testing.T) {
+
+	id, ok := LookupCodec("chimp")
+	assert.True(t, ok)
+	assert.Equal(t, CodecChimp, id)
+
+	id, ok = LookupCodec("gorilla")
+	assert.True(t, ok)
+	assert.Equal(t, CodecGorilla, id)
+
+	assert.Equal(t, "chimp", CodecName(CodecChimp))
+	assert.Contains(t, Codecs(), CodecChimp)
+	assert.Contains(t, Codecs(), CodecGorilla)
+	assert.True(t, Supports[uint64](CodecGorilla))
+
+	p, err := New[float64](CodecGorilla)
+	assert.Nil(t, err)
+	assert.IsType(t, &Gorilla[float64]{}, p)
+
+	q, err := NewByName[int64]("chimp")
+	assert.Nil(t, err)
+	assert.IsType(t, &Chimp[int64]{}, q)
+
+	_, err = New[float64](CodecUnknown)
+	assert.NotNil(t, err)
+
+	_, err = NewByName[float64]("unknown")
+	assert.NotNil(t, err)
+}
+
+func TestRegistry_Register(t *testing.T) {
+
+	id := CodecUser + 1
+	factory := func() Packer[float64] { return NewChimp[float64]() }
+
+	assert.Nil(t, Register(id, "test-codec", factory))
+	assert.Equal(t, "test-codec", id.String())
+
+	// Same type twice, name clash and id clash
+	assert.NotNil(t, Register(id, "test-codec", factory))
+	assert.NotNil(t, Register(id+1, "test-codec", factory))
+	assert.NotNil(t, Register(id, "other-codec", func() Packer[int64] { return NewChimp[int64]() }))
+	assert.NotNil(t, Register(CodecUnknown, "unknown", factory))
+
+	assert.True(t, Supports[float64](id))
+	assert.False(t, Supports[int64](id))
+
+	_, err := New[int64](id)
+	assert.NotNil(t, err)
+}
+
+func TestRegistry_ConcurrentRegister(t *testing.T) {
+
+	id := CodecUser + 3
+	assert.Nil(t, Register(id, "concurrent-codec",
+		func() Packer[float64] { return NewChimp[float64]() }))
+
+	// Lookups of a codec race with the registration of its other factories
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.Nil(t, Register(id, "concurrent-codec",
+			func() Packer[int64] { return NewChimp[int64]() }))
+		assert.Nil(t, Register(id, "concurrent-codec",
+			func() Packer[uint64] { return NewChimp[uint64]() }))
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, err := New[float64](id)
+			assert.Nil(t, err)
+			Supports[int64](id)
+		}
+	}()
+	wg.Wait()
+
+	_, err := New[uint64](id)
+	assert.Nil(t, err)
+}
+
+func TestRegistry_ForBuffer(t *testing.T) {
+
+	a := []uint64{10, 20, 30, 40}
+	buffer := &bytes.Buffer{}
+	NewGorilla[uint64]().Pack(a, buffer, Delta, 0)
+
+	p, err := ForBuffer[uint64](buffer)
+	assert.Nil(t, err)
+
+	res := make([]uint64, 4)
+	_, err = p.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, res)
+
+	_, err = ForBuffer[uint64](&bytes.Buffer{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,313 @@
+package packer
+
+// Restart points. A packer writing restart points resets the state of its
+// encoder (and the base of the Delta operation) every interval values, and
+// records the bit offset of every restart point in an index at the end of the
+// payload. Any segment of values between two restart points can then be
+// decoded on its own, without decoding the values preceding it.
+//
+// Index layout (little endian, at the end of the byte aligned payload):
+//
+//	8 bytes per segment   bit offset of the segment within the payload
+//	4 bytes               number of values between restart points
+//	4 bytes               number of segments
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+// Default number of values between restart points
+const DefaultRestartInterval = 128
+
+// Implemented by packers able to write restart points and to decode a single
+// segment of a packed block.
+type Seeker[T Number] interface {
+	Packer[T]
+
+	// Return a copy of the packer writing restart points every interval
+	// values. Zero disables restart points.
+	WithRestartInterval(interval uint64) Seeker[T]
+
+	// Unpacks the segment of the block in src holding the value at the
+	// specified index into dst, and returns the index of the first value of
+	// the segment and the number of unpacked values along with nil error.
+	// A block without restart points is a single segment. The checksum of
+	// a block with restart points is not verified (see VerifyBlock).
+	// Otherwise, returns (0, 0, error).
+	UnpackSegment(src *bytes.Buffer, index uint64, dst []T) (uint64, uint64, error)
+}
+
+// Return the number of values between the restart points of the block in the
+// src buffer, or the number of elements of a block without restart points.
+// The length never exceeds the number of elements of the block, so it can size
+// the buffer of a segment. Returns the length along with nil error, otherwise
+// returns (0, error).
+func SegmentLength(src *bytes.Buffer) (uint64, error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if !hdr.HasFlag(FlagIndex) {
+		return hdr.NumElements, nil
+	}
+
+	idx, err := readRestartIndex(&hdr, src.Bytes()[HeaderSize:hdr.payloadEnd()])
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if idx.interval > hdr.NumElements {
+		return hdr.NumElements, nil
+	}
+	return idx.interval, nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Encoder and decoder of the XOR based codecs (Chimp and Gorilla)
+type xorCodec interface {
+	addUIntValue(bitStream bitSink, value uint64)
+	next(bitStream *bitstream.BitReader) error
+	lastValue() uint64
+	reset()
+
+	// Set the width of the encoded words (see xorWordBits)
+	setWordBits(wordBits uint64)
+}
+
+// Return the width of the words encoded by the XOR based codecs for elements
+// of the specified type. 32-bit values rotated into the high half of the words
+// (see FlagSmallInts) leave the low half zero, so only the high half is
+// encoded and the length fields are one bit narrower.
+func xorWordBits(elemType ElemType, smallInts bool) uint64 {
+	if smallInts && elemType.Size() == 4 {
+		return 32
+	}
+	return 64
+}
+
+type restartIndex struct {
+	interval uint64
+	offsets  []uint64
+
+	// Encoded offsets of an index read from a block
+	table []byte
+}
+
+// Return the number of segments
+func (idx *restartIndex) count() uint64 {
+	if idx.table != nil {
+		return uint64(len(idx.table)) / 8
+	}
+	return uint64(len(idx.offsets))
+}
+
+// Return the bit offset of the segment within the payload
+func (idx *restartIndex) offset(seg uint64) uint64 {
+	if idx.table != nil {
+		return binary.LittleEndian.Uint64(idx.table[8*seg:])
+	}
+	return idx.offsets[seg]
+}
+
+func (idx *restartIndex) write(w *bitWriter) {
+	var b [8]byte
+	for _, offset := range idx.offsets {
+		binary.LittleEndian.PutUint64(b[:], offset)
+		w.buf = append(w.buf, b[:]...)
+	}
+	binary.LittleEndian.PutUint32(b[0:], uint32(idx.interval))
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(idx.offsets)))
+	w.buf = append(w.buf, b[:]...)
+	w.nbits = 8 * uint64(len(w.buf))
+}
+
+// Reads the index at the end of the payload of the block
+func readRestartIndex(hdr *Header, payload []byte) (restartIndex, error) {
+	if len(payload) < 8 {
+		return restartIndex{}, fmt.Errorf("%w: payload too small for restart index", ErrTruncated)
+	}
+
+	trailer := payload[len(payload)-8:]
+	idx := restartIndex{interval: uint64(binary.LittleEndian.Uint32(trailer[0:]))}
+	count := uint64(binary.LittleEndian.Uint32(trailer[4:]))
+	if idx.interval == 0 || count != (hdr.NumElements+idx.interval-1)/idx.interval {
+		return restartIndex{}, fmt.Errorf("%w: invalid restart index", ErrCorrupt)
+	}
+	if uint64(len(payload)) < 8+8*count {
+		return restartIndex{}, fmt.Errorf("%w: payload too small for restart index", ErrTruncated)
+	}
+
+	start := uint64(len(payload)) - 8 - 8*count
+	idx.table = payload[start : start+8*count]
+
+	return idx, nil
+}
+
+// Return a reader positioned at the specified bit offset of the payload
+func bitReaderAt(payload []byte, offset uint64) (*bitstream.BitReader, error) {
+	if offset/8 > uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: bit offset out of payload", ErrCorrupt)
+	}
+
+	br := bitstream.NewReader(bytes.NewReader(payload[offset/8:]))
+	if skip := int(offset % 8); skip > 0 {
+		if _, err := br.ReadBits(skip); err != nil {
+			return nil, streamError(err)
+		}
+	}
+	return br, nil
+}
+
+// Packs the values with the appender of an XOR based codec writing restart
+// points every interval values.
+func packXorSegments[T Number](
+	app *xorAppender[T], src []T, dst *bytes.Buffer) error {
+
+	for _, v := range src {
+		if err := app.Append(v); err != nil {
+			return err
+		}
+	}
+	return app.Snapshot(dst)
+}
+
+// Unpacks all segments of a block with restart points into dst
+func unpackXorSegments[T Number](
+	codec xorCodec, hdr *Header, payload []byte, dst []T) (uint64, error) {
+
+	idx, err := readRestartIndex(hdr, payload)
+	if err != nil {
+		return 0, streamError(err)
+	}
+
+	var readElements uint64 = 0
+	for seg := uint64(0); seg < idx.count(); seg++ {
+		n, err := unpackXorSegment(codec, hdr, payload, &idx, seg, dst[readElements:])
+		readElements += n
+		if err != nil {
+			return 0, streamError(err)
+		}
+	}
+
+	return readElements, nil
+}
+
+// Unpacks the specified segment of a block with restart points into dst and
+// returns the number of unpacked values.
+func unpackXorSegment[T Number](
+	codec xorCodec, hdr *Header, payload []byte, idx *restartIndex,
+	seg uint64, dst []T) (uint64, error) {
+
+	start := seg * idx.interval
+	count := hdr.NumElements - start
+	if count > idx.interval {
+		count = idx.interval
+	}
+	if uint64(len(dst)) < count {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	elemType := elemTypeOf[T]()
+
+	// Signs of signed integer values are packed ahead of the values, they are
+	// kept in dst until the values are decoded
+	if elemType.isSignedInt() {
+		minusOne := fromBits[T](toBits(int64(-1)))
+		br, err := bitReaderAt(payload, start)
+		if err != nil {
+			return 0, streamError(err)
+		}
+		for i := uint64(0); i < count; i++ {
+			bit, err := br.ReadBit()
+			if err != nil {
+				return 0, streamError(err)
+			}
+			dst[i] = 1
+			if bit {
+				dst[i] = minusOne
+			}
+		}
+	}
+
+	br, err := bitReaderAt(payload, idx.offset(seg))
+	if err != nil {
+		return 0, streamError(err)
+	}
+
+	smallInts := hdr.HasFlag(FlagSmallInts)
+	opParam := fromBits[T](hdr.OpParam)
+	prev := opParam
+
+	codec.setWordBits(xorWordBits(hdr.ElemType, smallInts))
+	codec.reset()
+	for i := uint64(0); i < count; i++ {
+		if err := codec.next(br); err != nil {
+			return i, streamError(err)
+		}
+
+		uVal := codec.lastValue()
+		if elemType != ElemFloat64 && smallInts {
+			uVal = (uVal << 32) | (uVal >> 32)
+		}
+
+		val := fromBits[T](uVal)
+		switch hdr.Op {
+		case Offset:
+			val -= opParam
+		case Delta:
+			val += prev
+			prev = val
+		}
+
+		if elemType.isSignedInt() {
+			val *= dst[i]
+		}
+		dst[i] = val
+	}
+
+	return count, nil
+}
+
+// Unpacks the segment of the block holding the value at index, without
+// verifying the checksum of the block. A block without restart points is
+// unpacked as a single segment with unpack.
+func unpackXorSegmentAt[T Number](
+	codec xorCodec, id CodecID, src *bytes.Buffer, index uint64, dst []T,
+	unpack func(src *bytes.Buffer, dst []T) (uint64, error)) (uint64, uint64, error) {
+
+	hdr, payload, err := peekBlock[T](src, id)
+	if err != nil {
+		return 0, 0, err
+	}
+	if index >= hdr.NumElements {
+		return 0, 0, errors.New("index out of bound")
+	}
+
+	if !hdr.HasFlag(FlagIndex) {
+		n, err := unpack(src, dst)
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, n, nil
+	}
+
+	idx, err := readRestartIndex(&hdr, payload)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	seg := index / idx.interval
+	n, err := unpackXorSegment(codec, &hdr, payload, &idx, seg, dst)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return seg * idx.interval, n, nil
+}
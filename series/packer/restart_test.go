@@ -0,0 +1,128 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestart_RoundTrip(t *testing.T) {
+
+	prices := make([]float64, 1000)
+	ints := make([]int64, 1000)
+	uints := make([]uint64, 1000)
+	for i := range prices {
+		prices[i] = 100.0 + math.Sin(float64(i)/10.0)*3.25
+		ints[i] = int64(i*i%97) - 40
+		uints[i] = uint64(1700000000 + i*15 + i%3)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		for _, interval := range []uint64{1, 7, DefaultRestartInterval} {
+			checkRestarts[float64](t, NewChimp[float64](), prices, op, 1.5, interval)
+			checkRestarts[float64](t, NewGorilla[float64](), prices, op, 1.5, interval)
+			checkRestarts[int64](t, NewChimp[int64](), ints, op, 7, interval)
+			checkRestarts[int64](t, NewGorilla[int64](), ints, op, 7, interval)
+			checkRestarts[uint64](t, NewChimp[uint64](), uints, op, 3, interval)
+			checkRestarts[uint64](t, NewGorilla[uint64](), uints, op, 3, interval)
+		}
+	}
+}
+
+func TestRestart_SegmentWithoutIndex(t *testing.T) {
+
+	a := []float64{1.5, 2.5, 3.5, 4.5}
+	buffer := &bytes.Buffer{}
+	NewChimp[float64]().Pack(a, buffer, NOP, 0)
+
+	dst := make([]float64, 4)
+	start, n, err := NewChimp[float64]().UnpackSegment(buffer, 2, dst)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), start)
+	assert.Equal(t, uint64(4), n)
+	assert.Equal(t, a, dst)
+
+	_, _, err = NewChimp[float64]().UnpackSegment(buffer, 4, dst)
+	assert.NotNil(t, err)
+}
+
+func TestRestart_Appender(t *testing.T) {
+
+	a := make([]int64, 300)
+	for i := range a {
+		a[i] = int64(i%50) - 10
+	}
+
+	chimp := NewChimp[int64]().WithRestartInterval(64).(*Chimp[int64])
+
+	packed := &bytes.Buffer{}
+	chimp.Pack(a, packed, Delta, 0)
+
+	app := chimp.NewAppender(Delta, 0)
+	for _, v := range a {
+		app.Append(v)
+	}
+	snapshot := &bytes.Buffer{}
+	app.Snapshot(snapshot)
+
+	assert.Equal(t, packed.Bytes(), snapshot.Bytes())
+	assert.Equal(t, uint64(packed.Len()), app.PackedSize())
+}
+
+func checkRestarts[T Number](
+	t *testing.T, p Seeker[T], values []T, op PackOp, opParam T, interval uint64) {
+
+	p = p.WithRestartInterval(interval)
+
+	buffer := &bytes.Buffer{}
+	err := p.Pack(values, buffer, op, opParam)
+	assert.Nil(t, err)
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.True(t, hdr.HasFlag(FlagIndex))
+	assert.Equal(t, hdr.BlockSize(), uint64(buffer.Len()))
+
+	length, err := SegmentLength(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, interval, length)
+
+	// Full unpack
+	res := make([]T, len(values))
+	numElements, err := p.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(values)), numElements)
+	assert.Equal(t, values, res)
+
+	// Segments holding the first, a middle and the last value
+	seg := make([]T, interval)
+	for _, index := range []int{0, len(values) / 2, len(values) - 1} {
+		start, n, err := p.UnpackSegment(buffer, uint64(index), seg)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(index)/interval*interval, start)
+		assert.Equal(t, values[start:start+n], seg[:n])
+	}
+}
+
+// Benchmark testing for point lookups. A single iteration unpacks the segment
+// holding a single value out of 1 million floats packed with the default
+// restart interval.
+func BenchmarkChimpFor_Float64_UnpackingSegment(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	res := make([]float64, DefaultRestartInterval)
+	for i := range a {
+		a[i] = float64(i) + 10000
+	}
+
+	chimp := NewChimp[float64]().WithRestartInterval(DefaultRestartInterval)
+	buffer := &bytes.Buffer{}
+	chimp.Pack(a, buffer, NOP, 0.0)
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		chimp.UnpackSegment(buffer, uint64(l*7919%len(a)), res)
+	}
+}
@@ -0,0 +1,169 @@
+package packer
+
+// Run-length compaction. Consecutive equal values (compared on their raw
+// bits) are stored once along with the length of the run. Frames holding a
+// single value are detected and stored as that value only, the number of
+// elements being recorded in the block header.
+//
+// Layout:
+//
+//	1 bit     mode (1: constant frame, 0: runs)
+//	constant: 64 bits value
+//	runs:     sequence of 64 bits value, 6 bits width w and w bits of the
+//	          run length minus one
+//
+// With the Delta op, sequences increasing by a constant step (such as
+// counters and regular timestamps) become constant runs as well.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type RLE[T Number] struct{}
+
+func init() {
+	mustRegister(CodecRLE, "rle", func() Packer[int64] { return NewRLE[int64]() })
+	mustRegister(CodecRLE, "rle", func() Packer[uint64] { return NewRLE[uint64]() })
+	mustRegister(CodecRLE, "rle", func() Packer[float64] { return NewRLE[float64]() })
+	mustRegister(CodecRLE, "rle", func() Packer[int32] { return NewRLE[int32]() })
+	mustRegister(CodecRLE, "rle", func() Packer[uint32] { return NewRLE[uint32]() })
+	mustRegister(CodecRLE, "rle", func() Packer[float32] { return NewRLE[float32]() })
+	mustRegister(CodecRLE, "rle", func() Packer[Decimal] { return NewRLE[Decimal]() })
+}
+
+func NewRLE[T Number]() *RLE[T] {
+	return &RLE[T]{}
+}
+
+// Packs the data in the src slice to the dst buffer and returns nil if packing
+// was completed successfuly. Otherwise, returns the error.
+func (rle *RLE[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	if elemTypeOf[T]() == ElemUnknown {
+		return errors.New("unsupported type in pack")
+	}
+
+	values := make([]uint64, len(src))
+	prev := opParam
+	constant := true
+	for ndx := range src {
+		val := src[ndx]
+		switch op {
+		case NOP:
+			values[ndx] = toBits(val)
+		case Offset:
+			values[ndx] = toBits(val + opParam)
+		case Delta:
+			values[ndx] = toBits(val - prev)
+			prev = val
+		}
+		constant = constant && values[ndx] == values[0]
+	}
+
+	start := reserveHeader(dst)
+
+	bitStream := bitstream.NewWriter(dst)
+	var size uint64 = 1
+	if constant && len(values) > 0 {
+		bitStream.WriteBit(true)
+		bitStream.WriteBits(values[0], 64)
+		size += 64
+	} else {
+		bitStream.WriteBit(false)
+		for ndx := 0; ndx < len(values); {
+			run := 1
+			for ndx+run < len(values) && values[ndx+run] == values[ndx] {
+				run++
+			}
+			width := bits.Len64(uint64(run - 1))
+			bitStream.WriteBits(values[ndx], 64)
+			bitStream.WriteBits(uint64(width), 6)
+			bitStream.WriteBits(uint64(run-1), width)
+			size += 64 + 6 + uint64(width)
+			ndx += run
+		}
+	}
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecRLE,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     size,
+	})
+
+	return nil
+}
+
+// Unpacks the data in the src buffer to the dst slice and returns number of
+// elements unpacked along with nil error. Otherwise, returns (0, error). The
+// block header in src takes precedence over op and opParam.
+func (rle *RLE[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecRLE)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+
+	var readElements uint64 = 0
+	if hdr.NumElements > 0 {
+		constant, err := bitStream.ReadBit()
+		if err != nil {
+			return 0, streamError(err)
+		}
+
+		for readElements < hdr.NumElements {
+			value, err := bitStream.ReadBits(64)
+			if err != nil {
+				return 0, streamError(err)
+			}
+
+			run := hdr.NumElements
+			if !constant {
+				width, err := bitStream.ReadBits(6)
+				if err != nil {
+					return 0, streamError(err)
+				}
+				run, err = bitStream.ReadBits(int(width))
+				if err != nil {
+					return 0, streamError(err)
+				}
+				run++
+			}
+			if run > hdr.NumElements-readElements {
+				return 0, fmt.Errorf("%w: invalid run length in unpack", ErrCorrupt)
+			}
+
+			for end := readElements + run; readElements < end; readElements++ {
+				switch op {
+				case NOP:
+					dst[readElements] = fromBits[T](value)
+				case Offset:
+					dst[readElements] = fromBits[T](value) - opParam
+				case Delta:
+					dst[readElements] = fromBits[T](value) + opParam
+					opParam = dst[readElements]
+				}
+			}
+		}
+	}
+
+	return readElements, nil
+}
+
+// Return the id of the codec
+func (rle *RLE[T]) Codec() CodecID {
+	return CodecRLE
+}
@@ -0,0 +1,165 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRLE_Float64_ValueCheck(t *testing.T) {
+
+	a := []float64{1, 1, 1, 2, 2, math.NaN(), math.NaN(), 3, 1, 1}
+	res := make([]float64, len(a))
+
+	rle := NewRLE[float64]()
+	buffer := &bytes.Buffer{}
+
+	err := rle.Pack(a, buffer, NOP, 0.0)
+	assert.Nil(t, err)
+
+	numElements, err := NewRLE[float64]().Unpack(buffer, res, NOP, 0.0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	for i := range a {
+		assert.Equal(t, math.Float64bits(a[i]), math.Float64bits(res[i]))
+	}
+
+	// Five runs of lengths 3, 2, 2, 1 and 2
+	assert.Equal(t, uint64(1+5*70+2+1+1+0+1), packedBits(buffer))
+}
+
+func TestRLE_Int64_PackOps(t *testing.T) {
+
+	a := make([]int64, 1000)
+	for i := range a {
+		a[i] = int64(i/100) - 3
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+
+		err := NewRLE[int64]().Pack(a, buffer, op, 5)
+		assert.Nil(t, err)
+
+		res := make([]int64, len(a))
+		_, err = NewRLE[int64]().Unpack(buffer, res, NOP, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, a, res, op.String())
+	}
+}
+
+func TestRLE_Empty(t *testing.T) {
+
+	rle := NewRLE[uint64]()
+	buffer := &bytes.Buffer{}
+
+	err := rle.Pack([]uint64{}, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	numElements, err := rle.Unpack(buffer, []uint64{}, NOP, 0)
+	assert.Nil(t, err)
+	assert.Zero(t, numElements)
+}
+
+// Tests the memory impact of storing a const (value of 1.0) series of size 10
+func TestRLE_Float64_CompressionCheckForConst(t *testing.T) {
+
+	a := make([]float64, 10)
+	for i := range a {
+		a[i] = 1.0
+	}
+
+	rle := NewRLE[float64]()
+	buffer := &bytes.Buffer{}
+
+	rle.Pack(a, buffer, NOP, 0.0)
+	assert.Equal(t, HeaderSize+9+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(65), packedBits(buffer))          // Num bits
+}
+
+// A monotonically increasing sequence with a constant step is a single run
+// once the delta operation is applied
+func TestRLE_UInt64_CompressionCheckForDeltaSequence(t *testing.T) {
+
+	a := make([]uint64, 1000)
+	for i := range a {
+		a[i] = uint64(i+1) * 10
+	}
+
+	rle := NewRLE[uint64]()
+	buffer := &bytes.Buffer{}
+
+	rle.Pack(a, buffer, Delta, 0)
+	assert.Equal(t, uint64(65), packedBits(buffer))
+
+	res := make([]uint64, len(a))
+	rle.Unpack(buffer, res, Delta, 0)
+	assert.Equal(t, a, res)
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// constant float64 (1999.9999). So a single op in the "ns/op" refers to
+// handling 1 million float64s
+func BenchmarkRLEFor_Float64_PackingConst(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	for i := range a {
+		a[i] = 1999.9999
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		rle := NewRLE[float64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		rle.Pack(a, buffer, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will pack 1 million
+// constant float64 (1999.9999). So a single op in the "ns/op" refers to
+// handling 1 million float64s
+func BenchmarkRLEFor_Float64_UnpackingConst(t *testing.B) {
+
+	a := make([]float64, 1000000)
+	res := make([]float64, 1000000)
+	for i := range a {
+		a[i] = 1999.9999
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		rle := NewRLE[float64]()
+		buffer := &bytes.Buffer{}
+		rle.Pack(a, buffer, NOP, 0.0)
+
+		t.StartTimer()
+		rle.Unpack(buffer, res, NOP, 0.0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// constant int64. So a single op in the "ns/op" refers to handling 1 million
+// int64s
+func BenchmarkRLEFor_Int64_PackingConst(t *testing.B) {
+
+	a := make([]int64, 1000000)
+	for i := range a {
+		a[i] = 1999
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		rle := NewRLE[int64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		rle.Pack(a, buffer, NOP, 0)
+		t.StopTimer()
+	}
+}
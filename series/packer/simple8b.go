@@ -0,0 +1,131 @@
+package packer
+
+// Integer compaction based on zigzag encoding, frame of reference and
+// Simple-8b (or fixed width bit packing). Signed values and deltas are zigzag
+// encoded so that values of small magnitude map to small unsigned integers;
+// the resulting integers are then written as an integer block (see
+// writeIntBlock).
+//
+// Ref: Vo Ngoc Anh, Alistair Moffat. Index compression using 64-bit words.
+//      Software: Practice and Experience, 40(2): 131 - 147, 2010
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/dgryski/go-bitstream"
+)
+
+type Simple8b[T Number] struct{}
+
+func init() {
+	mustRegister(CodecSimple8b, "simple8b", func() Packer[int64] { return NewSimple8b[int64]() })
+	mustRegister(CodecSimple8b, "simple8b", func() Packer[uint64] { return NewSimple8b[uint64]() })
+	mustRegister(CodecSimple8b, "simple8b", func() Packer[int32] { return NewSimple8b[int32]() })
+	mustRegister(CodecSimple8b, "simple8b", func() Packer[uint32] { return NewSimple8b[uint32]() })
+	mustRegister(CodecSimple8b, "simple8b", func() Packer[Decimal] { return NewSimple8b[Decimal]() })
+}
+
+func NewSimple8b[T Number]() *Simple8b[T] {
+	return &Simple8b[T]{}
+}
+
+// Packs the integer data in the src slice to the dst buffer and returns nil if
+// packing was completed successfuly. Otherwise, returns the error.
+func (s8 *Simple8b[T]) Pack(src []T, dst *bytes.Buffer, op PackOp, opParam T) error {
+	switch any(opParam).(type) {
+	case int64, uint64, int32, uint32, Decimal:
+	default:
+		return errors.New("unsupported type in pack")
+	}
+
+	signed := s8.isSigned(op)
+	values := make([]uint64, len(src))
+	prev := opParam
+	for ndx := range src {
+		val := src[ndx]
+		switch op {
+		case Offset:
+			val += opParam
+		case Delta:
+			val, prev = val-prev, val
+		}
+		if signed {
+			values[ndx] = zigzag(toSigned(val))
+		} else {
+			values[ndx] = toBits(val)
+		}
+	}
+
+	start := reserveHeader(dst)
+
+	bitStream := bitstream.NewWriter(dst)
+	size := writeIntBlock(bitStream, values)
+	bitStream.Flush(false)
+
+	writeHeader(dst, start, &Header{
+		Codec:       CodecSimple8b,
+		ElemType:    elemTypeOf[T](),
+		Op:          op,
+		NumElements: uint64(len(src)),
+		OpParam:     toBits(opParam),
+		NumBits:     size,
+	})
+
+	return nil
+}
+
+// Unpacks the integer data in the src buffer to the dst slice and returns
+// number of elements unpacked along with nil error. Otherwise, returns (0,
+// error). The block header in src takes precedence over op and opParam.
+func (s8 *Simple8b[T]) Unpack(src *bytes.Buffer, dst []T, op PackOp, opParam T) (uint64, error) {
+	hdr, payload, err := openBlock[T](src, CodecSimple8b)
+	if err != nil {
+		return 0, streamError(err)
+	}
+	if uint64(len(dst)) < hdr.NumElements {
+		return 0, errors.New("destination too small in unpack")
+	}
+
+	op = hdr.Op
+	opParam = fromBits[T](hdr.OpParam)
+
+	values := make([]uint64, hdr.NumElements)
+	bitStream := bitstream.NewReader(bytes.NewReader(payload))
+	if err := readIntBlock(bitStream, values); err != nil {
+		return 0, streamError(err)
+	}
+
+	signed := s8.isSigned(op)
+	for ndx, uVal := range values {
+		if signed {
+			uVal = uint64(unzigzag(uVal))
+		}
+		switch op {
+		case NOP:
+			dst[ndx] = fromBits[T](uVal)
+		case Offset:
+			dst[ndx] = fromBits[T](uVal) - opParam
+		case Delta:
+			dst[ndx] = fromBits[T](uVal) + opParam
+			opParam = dst[ndx]
+		}
+	}
+
+	return hdr.NumElements, nil
+}
+
+// Return the id of the codec
+func (s8 *Simple8b[T]) Codec() CodecID {
+	return CodecSimple8b
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return true if the values are zigzag encoded. Signed values and deltas
+// (which are negative for decreasing unsigned values) are zigzag encoded.
+func (s8 *Simple8b[T]) isSigned(op PackOp) bool {
+	return op == Delta || elemTypeOf[T]().isSignedInt()
+}
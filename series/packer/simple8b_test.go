@@ -0,0 +1,175 @@
+package packer
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimple8b_Int64_ValueCheck(t *testing.T) {
+
+	a := []int64{-5, 100, -100000, 0, 7, 7, 7, 1 << 40, -(1 << 40), 3}
+	res := make([]int64, len(a))
+
+	s8 := NewSimple8b[int64]()
+	buffer := &bytes.Buffer{}
+
+	err := s8.Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	numElements, err := s8.Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(len(a)), numElements)
+	assert.Equal(t, a, res)
+}
+
+func TestSimple8b_ExtremeValues(t *testing.T) {
+
+	a := []int64{math.MinInt64, math.MaxInt64, 0, -1, 1}
+	res := make([]int64, len(a))
+
+	buffer := &bytes.Buffer{}
+	err := NewSimple8b[int64]().Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+
+	_, err = NewSimple8b[int64]().Unpack(buffer, res, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, res)
+
+	b := []uint64{math.MaxUint64, 0, 1 << 63, 12}
+	resB := make([]uint64, len(b))
+
+	buffer = &bytes.Buffer{}
+	err = NewSimple8b[uint64]().Pack(b, buffer, Delta, 0)
+	assert.Nil(t, err)
+
+	_, err = NewSimple8b[uint64]().Unpack(buffer, resB, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, b, resB)
+}
+
+func TestSimple8b_UInt64_PackOps(t *testing.T) {
+
+	a := make([]uint64, 1000)
+	for i := range a {
+		a[i] = 1000000 + uint64((i*7919)%1013)
+	}
+
+	for _, op := range []PackOp{NOP, Offset, Delta} {
+		buffer := &bytes.Buffer{}
+
+		err := NewSimple8b[uint64]().Pack(a, buffer, op, 5)
+		assert.Nil(t, err)
+
+		res := make([]uint64, len(a))
+		_, err = NewSimple8b[uint64]().Unpack(buffer, res, NOP, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, a, res, op.String())
+	}
+}
+
+// Tests the memory impact of storing a const series of size 10
+func TestSimple8b_Int64_CompressionCheckForConst(t *testing.T) {
+
+	a := make([]int64, 10)
+	for i := range a {
+		a[i] = 1
+	}
+
+	s8 := NewSimple8b[int64]()
+	buffer := &bytes.Buffer{}
+
+	s8.Pack(a, buffer, NOP, 0)
+	assert.Equal(t, HeaderSize+9+ChecksumSize, buffer.Len()) // Num bytes
+	assert.Equal(t, uint64(65+7), packedBits(buffer))        // Num bits
+}
+
+// Tests the memory impact of storing a large counter increasing by at most 15
+// with delta operation applied before compression
+func TestSimple8b_UInt64_CompressionCheckForCounter(t *testing.T) {
+
+	a := make([]uint64, 1500)
+	var counter uint64 = 1 << 40
+	for i := range a {
+		counter += uint64(i % 16)
+		a[i] = counter
+	}
+
+	s8 := NewSimple8b[uint64]()
+	buffer := &bytes.Buffer{}
+
+	s8.Pack(a, buffer, Delta, 0)
+
+	// Apart from the first delta, zigzag deltas fit into 5 bits, 12 per word
+	assert.LessOrEqual(t, packedBits(buffer), uint64(65+64*(2+1500/12)))
+
+	res := make([]uint64, len(a))
+	s8.Unpack(buffer, res, Delta, 0)
+	assert.Equal(t, a, res)
+}
+
+// Values that do not fit in 60 bits are packed with fixed width
+func TestSimple8b_UInt64_FixedWidth(t *testing.T) {
+
+	a := []uint64{0, 1 << 62, 3, 1 << 61}
+
+	s8 := NewSimple8b[uint64]()
+	buffer := &bytes.Buffer{}
+
+	s8.Pack(a, buffer, NOP, 0)
+	assert.Equal(t, uint64(65+7+4*63), packedBits(buffer))
+
+	res := make([]uint64, len(a))
+	s8.Unpack(buffer, res, NOP, 0)
+	assert.Equal(t, a, res)
+}
+
+func TestSimple8b_UnsupportedType(t *testing.T) {
+
+	err := NewSimple8b[float64]().Pack([]float64{1.0}, &bytes.Buffer{}, NOP, 0.0)
+	assert.NotNil(t, err)
+}
+
+// Benchmark testing for packing. A single iteration will pack 1 million
+// int64 counter values.
+func BenchmarkSimple8bFor_Int64_PackingCounter(t *testing.B) {
+
+	a := make([]int64, 1000000)
+	for i := range a {
+		a[i] = int64(i*3) + int64(i%7)
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		s8 := NewSimple8b[int64]()
+		buffer := &bytes.Buffer{}
+
+		t.StartTimer()
+		s8.Pack(a, buffer, Delta, 0)
+		t.StopTimer()
+	}
+}
+
+// Benchmark testing for unpacking. A single iteration will unpack 1 million
+// int64 counter values.
+func BenchmarkSimple8bFor_Int64_UnpackingCounter(t *testing.B) {
+
+	a := make([]int64, 1000000)
+	res := make([]int64, 1000000)
+	for i := range a {
+		a[i] = int64(i*3) + int64(i%7)
+	}
+
+	t.ResetTimer()
+	for l := 0; l < t.N; l++ {
+		s8 := NewSimple8b[int64]()
+		buffer := &bytes.Buffer{}
+		s8.Pack(a, buffer, Delta, 0)
+
+		t.StartTimer()
+		s8.Unpack(buffer, res, Delta, 0)
+		t.StopTimer()
+	}
+}
@@ -0,0 +1,150 @@
+package packer
+
+// Summary statistics of the values of a packed block. The statistics are
+// stored in an optional section after the payload, so queries that only need
+// the count, extrema, sum or the first and last values can be answered from
+// the block without decoding the payload.
+//
+// Layout (little endian, StatsSize bytes, raw bits of the element type):
+//
+//	offset  size  field
+//	0       8     count
+//	8       8     min
+//	16      8     max
+//	24      8     sum
+//	32      8     first
+//	40      8     last
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// Size of the statistics section in bytes
+const StatsSize = 48
+
+// Summary statistics of a sequence of values.
+type Stats[T Number] struct {
+	Count uint64
+	Min   T
+	Max   T
+	Sum   T
+	First T
+	Last  T
+}
+
+// Compute the statistics of the specified values. The sum of integer values
+// wraps around on overflow.
+func ComputeStats[T Number](values []T) Stats[T] {
+	stats := Stats[T]{}
+	for _, v := range values {
+		stats.Add(v)
+	}
+	return stats
+}
+
+// Add the value to the statistics, as the last value of the sequence
+func (stats *Stats[T]) Add(v T) {
+	if stats.Count == 0 {
+		stats.Min = v
+		stats.Max = v
+		stats.First = v
+	}
+	if v < stats.Min {
+		stats.Min = v
+	}
+	if v > stats.Max {
+		stats.Max = v
+	}
+	stats.Sum += v
+	stats.Last = v
+	stats.Count++
+}
+
+// Append the statistics section to the packed block at the start of the dst
+// buffer and flag it in the block header. The block must be the last data in
+// the buffer and must not already hold statistics or a validity section.
+// Returns nil if the section was appended, otherwise returns the error.
+func AppendStats[T Number](dst *bytes.Buffer, stats Stats[T]) error {
+	hdr, err := ReadHeader(dst)
+	if err != nil {
+		return err
+	}
+	if hdr.ElemType != elemTypeOf[T]() {
+		return errors.New("block holds a different element type")
+	}
+	if hdr.HasFlag(FlagStats) {
+		return errors.New("block already holds statistics")
+	}
+	if hdr.HasFlag(FlagValidity) || hdr.HasFlag(FlagLabels) {
+		return errors.New("statistics must be appended before the validity and labels sections")
+	}
+	if uint64(dst.Len()) != hdr.BlockSize() {
+		return errors.New("block is not the last data in the buffer")
+	}
+
+	// The statistics section goes before the checksum, which is recomputed
+	sealed := hdr.HasFlag(FlagChecksum)
+	if sealed {
+		dst.Truncate(dst.Len() - ChecksumSize)
+	}
+
+	var b [StatsSize]byte
+	stats.encode(b[:])
+	dst.Write(b[:])
+
+	hdr.Flags |= FlagStats
+	hdr.encode(dst.Bytes()[:HeaderSize])
+	if sealed {
+		writeChecksum(dst, 0)
+	}
+
+	return nil
+}
+
+// Read the statistics section of the packed block in the src buffer. The
+// buffer is not consumed. Returns the statistics along with nil error,
+// otherwise returns the error.
+func ReadStats[T Number](src *bytes.Buffer) (Stats[T], error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return Stats[T]{}, err
+	}
+	if hdr.ElemType != elemTypeOf[T]() {
+		return Stats[T]{}, errors.New("block holds a different element type")
+	}
+	if !hdr.HasFlag(FlagStats) {
+		return Stats[T]{}, errors.New("block holds no statistics")
+	}
+	if err := verifyChecksum(&hdr, src.Bytes()); err != nil {
+		return Stats[T]{}, err
+	}
+
+	end := hdr.payloadEnd()
+	return decodeStats[T](src.Bytes()[end : end+StatsSize]), nil
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+func (stats *Stats[T]) encode(b []byte) {
+	binary.LittleEndian.PutUint64(b[0:], stats.Count)
+	binary.LittleEndian.PutUint64(b[8:], toBits(stats.Min))
+	binary.LittleEndian.PutUint64(b[16:], toBits(stats.Max))
+	binary.LittleEndian.PutUint64(b[24:], toBits(stats.Sum))
+	binary.LittleEndian.PutUint64(b[32:], toBits(stats.First))
+	binary.LittleEndian.PutUint64(b[40:], toBits(stats.Last))
+}
+
+func decodeStats[T Number](b []byte) Stats[T] {
+	return Stats[T]{
+		Count: binary.LittleEndian.Uint64(b[0:]),
+		Min:   fromBits[T](binary.LittleEndian.Uint64(b[8:])),
+		Max:   fromBits[T](binary.LittleEndian.Uint64(b[16:])),
+		Sum:   fromBits[T](binary.LittleEndian.Uint64(b[24:])),
+		First: fromBits[T](binary.LittleEndian.Uint64(b[32:])),
+		Last:  fromBits[T](binary.LittleEndian.Uint64(b[40:])),
+	}
+}
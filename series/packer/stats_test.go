@@ -0,0 +1,65 @@
+package packer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats_ComputeStats(t *testing.T) {
+
+	stats := ComputeStats([]float64{3.5, -1.0, 7.25, 2.0})
+	assert.Equal(t, uint64(4), stats.Count)
+	assert.Equal(t, -1.0, stats.Min)
+	assert.Equal(t, 7.25, stats.Max)
+	assert.Equal(t, 11.75, stats.Sum)
+	assert.Equal(t, 3.5, stats.First)
+	assert.Equal(t, 2.0, stats.Last)
+
+	empty := ComputeStats([]int64{})
+	assert.Equal(t, Stats[int64]{}, empty)
+}
+
+func TestStats_AppendAndRead(t *testing.T) {
+
+	a := make([]int64, 100)
+	for i := range a {
+		a[i] = int64(i*3) - 50
+	}
+
+	buffer := &bytes.Buffer{}
+	err := NewChimp[int64]().Pack(a, buffer, NOP, 0)
+	assert.Nil(t, err)
+	packedLen := buffer.Len()
+
+	_, err = ReadStats[int64](buffer)
+	assert.NotNil(t, err)
+
+	stats := ComputeStats(a)
+	err = AppendStats(buffer, stats)
+	assert.Nil(t, err)
+	assert.Equal(t, packedLen+StatsSize, buffer.Len())
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.True(t, hdr.HasFlag(FlagStats))
+	assert.Equal(t, uint64(buffer.Len()), hdr.BlockSize())
+
+	res, err := ReadStats[int64](buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, stats, res)
+	assert.Equal(t, int64(-50), res.Min)
+	assert.Equal(t, int64(247), res.Max)
+
+	// Statistics are appended once and for the element type of the block
+	assert.NotNil(t, AppendStats(buffer, stats))
+	_, err = ReadStats[uint64](buffer)
+	assert.NotNil(t, err)
+
+	// Payload is still decoded correctly
+	dst := make([]int64, 100)
+	_, err = NewChimp[int64]().Unpack(buffer, dst, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, dst)
+}
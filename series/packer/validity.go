@@ -0,0 +1,107 @@
+package packer
+
+// Validity of the values of a packed block. Blocks holding missing values
+// (samples that were never received, as opposed to zeros) carry a validity
+// section after the statistics section, marking the values that are present.
+// Missing values are packed as zeros by the codec, so the section is only
+// needed to tell them apart; blocks whose values are all present omit it.
+//
+// Layout (little endian):
+//
+//	offset  size  field
+//	0       4     size n of the bitmap in bytes
+//	4       n     binary form of the bitmap of the present values (see
+//	              bitmap.Bitmap.MarshalBinary)
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rmravindran/ats/series/bitmap"
+)
+
+// Size of the prefix of the validity section holding the size of the bitmap
+const ValidityPrefixSize = 4
+
+// Append the validity section holding the bitmap of the present values to the
+// packed block at the start of the dst buffer and flag it in the block
+// header. The block must be the last data in the buffer and must not already
+// hold a validity section. Returns nil if the section was appended, otherwise
+// returns the error.
+func AppendValidity(dst *bytes.Buffer, valid *bitmap.Bitmap) error {
+	hdr, err := ReadHeader(dst)
+	if err != nil {
+		return err
+	}
+	if hdr.HasFlag(FlagValidity) {
+		return errors.New("block already holds a validity section")
+	}
+	if hdr.HasFlag(FlagLabels) {
+		return errors.New("validity must be appended before the labels section")
+	}
+	if uint64(dst.Len()) != hdr.BlockSize() {
+		return errors.New("block is not the last data in the buffer")
+	}
+	if valid == nil || valid.Len() != hdr.NumElements {
+		return errors.New("validity differs in length from the block")
+	}
+
+	data, err := valid.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) > math.MaxUint32 {
+		return errors.New("validity section too large")
+	}
+
+	// The validity section goes before the checksum, which is recomputed
+	sealed := hdr.HasFlag(FlagChecksum)
+	if sealed {
+		dst.Truncate(dst.Len() - ChecksumSize)
+	}
+
+	var prefix [ValidityPrefixSize]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(data)))
+	dst.Write(prefix[:])
+	dst.Write(data)
+
+	hdr.Flags |= FlagValidity
+	hdr.encode(dst.Bytes()[:HeaderSize])
+	if sealed {
+		writeChecksum(dst, 0)
+	}
+
+	return nil
+}
+
+// Read the bitmap of the present values of the packed block in the src
+// buffer. The buffer is not consumed. Returns the bitmap along with nil error,
+// or (nil, nil) if the block has no validity section, its values being all
+// present. Otherwise returns (nil, error).
+func ReadValidity(src *bytes.Buffer) (*bitmap.Bitmap, error) {
+	hdr, err := ReadHeader(src)
+	if err != nil {
+		return nil, err
+	}
+	if !hdr.HasFlag(FlagValidity) {
+		return nil, nil
+	}
+	if err := verifyChecksum(&hdr, src.Bytes()); err != nil {
+		return nil, err
+	}
+
+	start := hdr.validityStart() + ValidityPrefixSize
+	end := hdr.validityStart() + hdr.ValiditySize
+	valid := bitmap.New()
+	if err := valid.UnmarshalBinary(src.Bytes()[start:end]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if valid.Len() != hdr.NumElements {
+		return nil, fmt.Errorf("%w: validity differs in length from the block", ErrCorrupt)
+	}
+
+	return valid, nil
+}
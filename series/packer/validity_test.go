@@ -0,0 +1,88 @@
+package packer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rmravindran/ats/series/bitmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidity_AppendRead(t *testing.T) {
+
+	a := []int64{1, 2, 0, 0, 5, 6, 7, 0}
+	valid := bitmap.FromBools([]bool{true, true, false, true, true, true, true, false})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, NewSimple8b[int64]().Pack(a, buffer, NOP, 0))
+	res, err := ReadValidity(buffer)
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+
+	assert.Nil(t, AppendStats(buffer, ComputeStats(a)))
+	assert.Nil(t, AppendValidity(buffer, valid))
+	assert.NotNil(t, AppendValidity(buffer, valid))
+	assert.NotNil(t, AppendStats(buffer, ComputeStats(a)))
+
+	hdr, err := ReadHeader(buffer)
+	assert.Nil(t, err)
+	assert.True(t, hdr.HasFlag(FlagValidity))
+	assert.Equal(t, uint64(buffer.Len()), hdr.BlockSize())
+	assert.Nil(t, VerifyBlock(buffer))
+
+	res, err = ReadValidity(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, valid.Runs(), res.Runs())
+	assert.Equal(t, valid.Len(), res.Len())
+
+	// Values and statistics are still read from the block
+	stats, err := ReadStats[int64](buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), stats.Sum)
+	values := make([]int64, len(a))
+	_, err = NewSimple8b[int64]().Unpack(buffer, values, NOP, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, a, values)
+
+	// Truncated and corrupted sections are detected
+	truncated := bytes.NewBuffer(buffer.Bytes()[:buffer.Len()-ChecksumSize-1])
+	_, err = ReadHeader(truncated)
+	assert.ErrorIs(t, err, ErrTruncated)
+
+	corrupted := bytes.NewBuffer(append([]byte{}, buffer.Bytes()...))
+	corrupted.Bytes()[buffer.Len()-ChecksumSize-1] ^= 0xff
+	_, err = ReadValidity(corrupted)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	assert.NotNil(t, AppendValidity(&bytes.Buffer{}, valid))
+	other := &bytes.Buffer{}
+	assert.Nil(t, NewSimple8b[int64]().Pack(a[:4], other, NOP, 0))
+	assert.NotNil(t, AppendValidity(other, valid))
+}
+
+// Blocks holding missing values are only aggregated from their statistics
+func TestValidity_AggregateBlock(t *testing.T) {
+
+	a := []int64{4, 0, 4, 4, 0, 9}
+	valid := bitmap.FromBools([]bool{true, false, true, true, true, true})
+
+	buffer := &bytes.Buffer{}
+	p := NewRLE[int64]()
+	assert.Nil(t, p.Pack(a, buffer, NOP, 0))
+	assert.Nil(t, AppendStats(buffer, Stats[int64]{Count: 5, Min: 0, Max: 9, Sum: 21}))
+	assert.Nil(t, AppendValidity(buffer, valid))
+
+	v, ok, err := AggregateBlock[int64](p, buffer, AggregateCount, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), v)
+
+	v, ok, err = AggregateBlock[int64](p, buffer, AggregateSum, 0)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(21), v)
+
+	_, ok, err = AggregateBlock[int64](p, buffer, AggregateCountEqual, 4)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
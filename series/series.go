@@ -29,11 +29,30 @@ type Series[T packer.Number] struct {
 
 	// Last frame offset
 	lastFrameOffset int
+
+	// Construction options
+	opts options
+}
+
+// Summary statistics of a frame of the series
+type FrameStats[T packer.Number] struct {
+
+	// Statistics of the values
+	Values packer.Stats[T]
+
+	// Time of the first value of the frame
+	FirstTime uint64
+
+	// Time of the last value of the frame
+	LastTime uint64
 }
 
-// Creates a new series where every frame is of the specified fameSize
-func NewSeries[T packer.Number](frameSize int) *Series[T] {
-	return &Series[T]{
+// Creates a new series where every frame is of the specified fameSize. By
+// default time frames are packed with DeltaOfDelta and value frames with
+// Chimp; use WithTimeCodec and WithValueCodec to select other registered
+// codecs.
+func NewSeries[T packer.Number](frameSize int, opts ...Option) *Series[T] {
+	series := &Series[T]{
 		startTime:       0,
 		endTime:         0,
 		timeFrames:      nil,
@@ -41,25 +60,77 @@ func NewSeries[T packer.Number](frameSize int) *Series[T] {
 		frameSize:       frameSize,
 		size:            0,
 		lastFrameOffset: 0,
+		opts:            defaultOptions(),
+	}
+
+	for _, opt := range opts {
+		opt(&series.opts)
+	}
+
+	return series
+}
+
+// Creates a new series holding the values at the specified times, appended in
+// order. Returns the series along with nil error, otherwise returns (nil,
+// error).
+func FromSlices[T packer.Number](
+	times []uint64, values []T, frameSize int, opts ...Option) (*Series[T], error) {
+
+	if len(times) != len(values) {
+		return nil, errors.New("times and values differ in length")
+	}
+
+	series := NewSeries[T](frameSize, opts...)
+	for ndx := range values {
+		if err := series.AppendValue(times[ndx], values[ndx]); err != nil {
+			return nil, err
+		}
 	}
+
+	return series, nil
 }
 
 // Appends a value to the series
 func (series *Series[T]) AppendValue(time uint64, value T) error {
-	if series.timeFrames == nil || len(series.timeFrames) == 0 {
-		series.appendFrame()
+	frameIndex := series.size / series.frameSize
+	if frameIndex >= len(series.timeFrames) {
+		if err := series.appendFrame(); err != nil {
+			return err
+		}
+	}
+
+	errT := appendToFrame(series.timeFrames[frameIndex], series.lastFrameOffset, time)
+	if errT != nil {
+		return errT
 	}
+
+	errV := appendToFrame(series.valueFrames[frameIndex], series.lastFrameOffset, value)
+	if errV != nil {
+		return errV
+	}
+
+	series.lastFrameOffset++
+	series.size++
+
+	return nil
+}
+
+// Appends a missing value to the series, a sample that was expected at the
+// specified time but not received (see IsValid)
+func (series *Series[T]) AppendNull(time uint64) error {
 	frameIndex := series.size / series.frameSize
 	if frameIndex >= len(series.timeFrames) {
-		series.appendFrame()
+		if err := series.appendFrame(); err != nil {
+			return err
+		}
 	}
 
-	errT := series.timeFrames[frameIndex].SetValue(series.lastFrameOffset, time)
+	errT := appendToFrame(series.timeFrames[frameIndex], series.lastFrameOffset, time)
 	if errT != nil {
 		return errT
 	}
 
-	errV := series.valueFrames[frameIndex].SetValue(series.lastFrameOffset, value)
+	errV := appendNullToFrame(series.valueFrames[frameIndex], series.lastFrameOffset)
 	if errV != nil {
 		return errV
 	}
@@ -91,6 +162,38 @@ func (series *Series[T]) SetValue(index int, time uint64, value T) error {
 	return nil
 }
 
+// Mark the value at the specified index as missing
+func (series *Series[T]) SetNull(index int, time uint64) error {
+	if index < 0 || index >= series.size {
+		return errors.New("index out of bound")
+	}
+
+	frameIndex := index / series.frameSize
+	localIndex := index - (frameIndex * series.frameSize)
+	errT := series.timeFrames[frameIndex].SetValue(localIndex, time)
+	if errT != nil {
+		return errT
+	}
+
+	return series.valueFrames[frameIndex].SetNull(localIndex)
+}
+
+// Return true if the value at the specified index is present, false if it is
+// missing (see AppendNull). Missing values are returned by Value as zeros.
+func (series *Series[T]) IsValid(index int) (bool, error) {
+	if index < 0 || index >= series.size {
+		return false, errors.New("index out of bound")
+	}
+
+	frameIndex := index / series.frameSize
+	localIndex := index - (frameIndex * series.frameSize)
+	if _, err := series.valueFrames[frameIndex].Validity(); err != nil {
+		return false, err
+	}
+
+	return series.valueFrames[frameIndex].IsValid(localIndex), nil
+}
+
 // Set value at the specified index
 func (series *Series[T]) Value(index int) (uint64, T, error) {
 	if index >= series.size {
@@ -104,7 +207,7 @@ func (series *Series[T]) Value(index int) (uint64, T, error) {
 		return 0, T(0), errT
 	}
 	v, errV := series.valueFrames[frameIndex].Value(localIndex)
-	if errT != nil {
+	if errV != nil {
 		return 0, T(0), errV
 	}
 
@@ -119,18 +222,213 @@ func (series *Series[T]) FrameSize() int {
 	return series.frameSize
 }
 
+// Pack every full frame of the series, the frame being filled is left as is.
+// Packed frames release their unpacked values if reduce is true.
+func (series *Series[T]) Finalize(reduce bool) error {
+	for i := range series.valueFrames {
+		if i == len(series.valueFrames)-1 && series.lastFrameOffset < series.frameSize {
+			break
+		}
+		if err := series.timeFrames[i].Finalize(reduce); err != nil {
+			return err
+		}
+		if err := series.valueFrames[i].Finalize(reduce); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Return the number of frames of the series
+func (series *Series[T]) NumFrames() int {
+	return len(series.valueFrames)
+}
+
+// Return the statistics of the frame at the specified index. Statistics of
+// finalized frames are read from the packed frames without unpacking them.
+func (series *Series[T]) FrameStats(index int) (FrameStats[T], error) {
+	if index < 0 || index >= len(series.valueFrames) {
+		return FrameStats[T]{}, errors.New("frame index out of bound")
+	}
+
+	n := series.frameSize
+	if index == len(series.valueFrames)-1 {
+		n = series.lastFrameOffset
+	}
+
+	statsT, err := frameStats(series.timeFrames[index], n)
+	if err != nil {
+		return FrameStats[T]{}, err
+	}
+	statsV, err := frameStats(series.valueFrames[index], n)
+	if err != nil {
+		return FrameStats[T]{}, err
+	}
+
+	return FrameStats[T]{
+		Values:    statsV,
+		FirstTime: statsT.First,
+		LastTime:  statsT.Last,
+	}, nil
+}
+
+// Return the value frame at the specified index along with the number of
+// values of the series it holds, which is less than the length of the frame
+// for the unfilled last frame.
+func (series *Series[T]) ValueFrame(index int) (*frame.Frame[T], int, error) {
+	if index < 0 || index >= len(series.valueFrames) {
+		return nil, 0, errors.New("frame index out of bound")
+	}
+
+	n := series.frameSize
+	if index == len(series.valueFrames)-1 {
+		n = series.lastFrameOffset
+	}
+	return series.valueFrames[index], n, nil
+}
+
+// Return the time frame at the specified index along with the number of
+// values of the series it holds, which is less than the length of the frame
+// for the unfilled last frame.
+func (series *Series[T]) TimeFrame(index int) (*frame.Frame[uint64], int, error) {
+	if index < 0 || index >= len(series.timeFrames) {
+		return nil, 0, errors.New("frame index out of bound")
+	}
+
+	n := series.frameSize
+	if index == len(series.timeFrames)-1 {
+		n = series.lastFrameOffset
+	}
+	return series.timeFrames[index], n, nil
+}
+
+// Return the id of the codec used to pack the time frames
+func (series *Series[T]) TimeCodec() packer.CodecID {
+	return series.opts.timeCodec
+}
+
+// Return the id of the codec used to pack the value frames
+func (series *Series[T]) ValueCodec() packer.CodecID {
+	return series.opts.valueCodec
+}
+
+// Return the scale of the values of a series packed with the Decimal codec
+// (see WithDecimalScale)
+func (series *Series[T]) DecimalScale() uint8 {
+	return series.opts.decimalScale
+}
+
 //-----------------------------------------------------------------------------
 //                              PRIVATE METHODS
 //-----------------------------------------------------------------------------
 
-func (series *Series[T]) appendFrame() {
-	pT := packer.NewChimp[uint64]()
-	fT := frame.NewEmptyFrame[uint64](uint64(series.frameSize), pT)
+func (series *Series[T]) appendFrame() error {
+	pT, err := newTimePacker(&series.opts)
+	if err != nil {
+		return err
+	}
+	pV, err := newValuePacker[T](&series.opts)
+	if err != nil {
+		return err
+	}
+
+	pV = withRestartInterval(pV, series.opts.restartInterval)
+
+	fT := newFrame[uint64](uint64(series.frameSize), pT, &series.opts)
 	series.timeFrames = append(series.timeFrames, fT)
 
-	pV := packer.NewChimp[T]()
-	fV := frame.NewEmptyFrame[T](uint64(series.frameSize), pV)
+	fV := newFrame[T](uint64(series.frameSize), pV, &series.opts)
 	series.valueFrames = append(series.valueFrames, fV)
 
 	series.lastFrameOffset = 0
+
+	return nil
+}
+
+// Create the packer of the time frames
+func newTimePacker(opts *options) (packer.Packer[uint64], error) {
+	p, err := packer.New[uint64](opts.timeCodec)
+	if err != nil {
+		return nil, err
+	}
+	return withRestartInterval(p, opts.restartInterval), nil
+}
+
+// Create the packer of the value frames
+func newValuePacker[V packer.Number](opts *options) (packer.Packer[V], error) {
+	if opts.valueCodec == packer.CodecQuantized {
+		if !packer.Supports[V](packer.CodecQuantized) {
+			return nil, errors.New("codec does not support the element type")
+		}
+		if !(opts.errorBound > 0) {
+			return nil, errors.New("quantized codec requires an error bound (see WithMaxError)")
+		}
+		return packer.NewQuantized[V](opts.errorMode, opts.errorBound), nil
+	}
+	p, err := packer.New[V](opts.valueCodec)
+	if err != nil {
+		return nil, err
+	}
+	if opts.valueCodec == packer.CodecDecimal {
+		return packer.NewDecimalPacker[V](opts.decimalScale), nil
+	}
+	return p, nil
+}
+
+// Create an empty frame, appendable if requested and supported by the packer,
+// accounted in the memory budget of the options if any
+func newFrame[V packer.Number](size uint64, p packer.Packer[V], opts *options) *frame.Frame[V] {
+	var f *frame.Frame[V]
+	if opts.appendable {
+		f, _ = frame.NewAppendableFrame[V](size, p)
+	}
+	if f == nil {
+		f = frame.NewEmptyFrame[V](size, p)
+	}
+	f.SetBudget(opts.budget)
+	return f
+}
+
+// Return the packer writing restart points every interval values if it
+// supports restart points, otherwise return the packer unchanged.
+func withRestartInterval[V packer.Number](p packer.Packer[V], interval uint64) packer.Packer[V] {
+	if seeker, ok := p.(packer.Seeker[V]); ok {
+		return seeker.WithRestartInterval(interval)
+	}
+	return p
+}
+
+// Store the value at the specified offset of the frame, the offset being the
+// number of values already stored in the frame.
+func appendToFrame[V packer.Number](f *frame.Frame[V], offset int, value V) error {
+	if f.IsAppendable() {
+		return f.Append(value)
+	}
+	return f.SetValue(offset, value)
+}
+
+// Mark the value at the specified offset of the frame as missing, the offset
+// being the number of values already stored in the frame.
+func appendNullToFrame[V packer.Number](f *frame.Frame[V], offset int) error {
+	if f.IsAppendable() {
+		return f.AppendNull()
+	}
+	return f.SetNull(offset)
+}
+
+// Return the statistics of the first n values of the frame, missing values
+// being left out. Statistics of a frame holding more values (the unfilled
+// part of the last frame) are computed from its values.
+func frameStats[V packer.Number](f *frame.Frame[V], n int) (packer.Stats[V], error) {
+	if uint64(n) < f.Length() {
+		stats := packer.Stats[V]{}
+		for ndx, v := range f.Values()[:n] {
+			if f.IsValid(ndx) {
+				stats.Add(v)
+			}
+		}
+		return stats, nil
+	}
+	return f.Stats()
 }
@@ -1,8 +1,13 @@
 package series
 
 import (
+	"math"
 	"testing"
 
+	"github.com/rmravindran/ats/series/frame"
+	"github.com/rmravindran/ats/series/gen"
+	"github.com/rmravindran/ats/series/packer"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,3 +36,443 @@ func TestSeries_ValueCheck(t *testing.T) {
 	}
 
 }
+
+func TestSeries_Codecs(t *testing.T) {
+
+	s := NewSeries[float64](4,
+		WithTimeCodec(packer.CodecGorilla), WithValueCodec(packer.CodecGorilla))
+
+	assert.Equal(t, packer.CodecGorilla, s.TimeCodec())
+	assert.Equal(t, packer.CodecGorilla, s.ValueCodec())
+
+	for i := 0; i < 10; i++ {
+		err := s.AppendValue(uint64(i), float64(i))
+		assert.Nil(t, err)
+	}
+
+	for i := 0; i < 10; i++ {
+		time, v, err := s.Value(i)
+		assert.Equal(t, uint64(i), time)
+		assert.Equal(t, float64(i), v)
+		assert.Nil(t, err)
+	}
+}
+
+func TestSeries_UnknownCodec(t *testing.T) {
+
+	s := NewSeries[float64](4, WithValueCodec(packer.CodecUnknown))
+
+	err := s.AppendValue(0, 1.0)
+	assert.NotNil(t, err)
+	assert.Zero(t, s.Size())
+}
+
+func TestSeries_DefaultCodecs(t *testing.T) {
+
+	s := NewSeries[float64](10)
+
+	assert.Equal(t, packer.CodecDeltaOfDelta, s.TimeCodec())
+	assert.Equal(t, packer.CodecChimp, s.ValueCodec())
+}
+
+func TestSeries_FrameStats(t *testing.T) {
+
+	s := NewSeries[int64](4)
+
+	for i := 0; i < 10; i++ {
+		err := s.AppendValue(uint64(100+i*10), int64(i*i))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 3, s.NumFrames())
+
+	stats, err := s.FrameStats(1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), stats.Values.Count)
+	assert.Equal(t, int64(16), stats.Values.Min)
+	assert.Equal(t, int64(49), stats.Values.Max)
+	assert.Equal(t, int64(16+25+36+49), stats.Values.Sum)
+	assert.Equal(t, uint64(140), stats.FirstTime)
+	assert.Equal(t, uint64(170), stats.LastTime)
+
+	// Last frame only holds two values
+	stats, err = s.FrameStats(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), stats.Values.Count)
+	assert.Equal(t, int64(64), stats.Values.First)
+	assert.Equal(t, int64(81), stats.Values.Last)
+	assert.Equal(t, uint64(180), stats.FirstTime)
+	assert.Equal(t, uint64(190), stats.LastTime)
+
+	_, err = s.FrameStats(3)
+	assert.NotNil(t, err)
+}
+
+func TestSeries_AppendableFrames(t *testing.T) {
+
+	s := NewSeries[float64](8,
+		WithTimeCodec(packer.CodecGorilla), WithAppendableFrames())
+
+	for i := 0; i < 20; i++ {
+		err := s.AppendValue(uint64(1000+i), float64(i)/4.0)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 3, s.NumFrames())
+
+	for i := 0; i < 20; i++ {
+		time, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1000+i), time)
+		assert.Equal(t, float64(i)/4.0, v)
+	}
+
+	err := s.SetValue(17, 1017, -3.0)
+	assert.Nil(t, err)
+
+	stats, err := s.FrameStats(2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), stats.Values.Count)
+	assert.Equal(t, -3.0, stats.Values.Min)
+	assert.Equal(t, uint64(1016), stats.FirstTime)
+	assert.Equal(t, uint64(1019), stats.LastTime)
+
+	// Head frame keeps appending after random writes
+	err = s.AppendValue(1020, 5.0)
+	assert.Nil(t, err)
+	_, v, err := s.Value(20)
+	assert.Nil(t, err)
+	assert.Equal(t, 5.0, v)
+}
+
+func TestSeries_Nulls(t *testing.T) {
+
+	for _, appendable := range []bool{false, true} {
+		var opts []Option
+		if appendable {
+			opts = append(opts, WithAppendableFrames())
+		}
+		s := NewSeries[int64](4, opts...)
+
+		for i := 0; i < 10; i++ {
+			var err error
+			if i%3 == 1 {
+				err = s.AppendNull(uint64(100 + i))
+			} else {
+				err = s.AppendValue(uint64(100+i), int64(i))
+			}
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, s.SetNull(8, 108))
+
+		// Missing values are read as zeros at their times
+		for i := 0; i < 10; i++ {
+			valid, err := s.IsValid(i)
+			assert.Nil(t, err)
+			assert.Equal(t, i%3 != 1 && i != 8, valid, i)
+
+			time, v, err := s.Value(i)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(100+i), time)
+			if !valid {
+				assert.Equal(t, int64(0), v)
+			}
+		}
+		_, err := s.IsValid(10)
+		assert.NotNil(t, err)
+
+		// Statistics leave out the missing values
+		stats, err := s.FrameStats(1)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(2), stats.Values.Count)
+		assert.Equal(t, int64(5+6), stats.Values.Sum)
+		stats, err = s.FrameStats(2)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), stats.Values.Count)
+		assert.Equal(t, int64(9), stats.Values.Min)
+
+		// Validity survives packing
+		assert.Nil(t, s.Finalize(true))
+		for i := 0; i < 10; i++ {
+			valid, err := s.IsValid(i)
+			assert.Nil(t, err)
+			assert.Equal(t, i%3 != 1 && i != 8, valid, i)
+		}
+	}
+}
+
+// Finalizing again after reading releases the unpacked frames without losing
+// their values
+func TestSeries_FinalizeTwice(t *testing.T) {
+
+	configs := map[string][]Option{
+		"default":    nil,
+		"appendable": {WithAppendableFrames()},
+		"restart":    {WithRestartInterval(4)},
+		"budget":     {WithMemoryBudget(frame.NewBudget(1 << 20))},
+	}
+	for name, opts := range configs {
+		s := NewSeries[float64](16, opts...)
+		for i := 0; i < 40; i++ {
+			if i%7 == 3 {
+				assert.Nil(t, s.AppendNull(uint64(1000+i)))
+			} else {
+				assert.Nil(t, s.AppendValue(uint64(1000+i), float64(i)))
+			}
+		}
+
+		check := func(i int, exp float64) {
+			time, v, err := s.Value(i)
+			assert.Nil(t, err, name)
+			assert.Equal(t, uint64(1000+i), time, name)
+			assert.Equal(t, exp, v, name)
+		}
+		expected := func(i int) float64 {
+			if i%7 == 3 {
+				return 0
+			}
+			return float64(i)
+		}
+
+		assert.Nil(t, s.Finalize(false))
+		for i := 0; i < 40; i++ {
+			check(i, expected(i))
+		}
+		assert.Nil(t, s.Finalize(true))
+		for i := 0; i < 40; i++ {
+			check(i, expected(i))
+		}
+		assert.Nil(t, s.Finalize(true))
+
+		// Writes after release decode the frame first
+		assert.Nil(t, s.SetValue(5, 1005, -5))
+		for i := 0; i < 40; i++ {
+			if i == 5 {
+				check(i, -5)
+				continue
+			}
+			check(i, expected(i))
+			valid, err := s.IsValid(i)
+			assert.Nil(t, err, name)
+			assert.Equal(t, i%7 != 3, valid, name)
+		}
+		assert.Nil(t, s.Finalize(true))
+		check(5, -5)
+		check(18, 18)
+	}
+}
+
+func TestSeries_MemoryBudget(t *testing.T) {
+
+	// Room for the times and values of two frames, shared by two series
+	budget := frame.NewBudget(2 * 2 * 64 * 8)
+	a := NewSeries[float64](64, WithMemoryBudget(budget))
+	b := NewSeries[int64](64, WithMemoryBudget(budget))
+	for i := 0; i < 640; i++ {
+		assert.Nil(t, a.AppendValue(uint64(i), float64(i)/2))
+		assert.Nil(t, b.AppendValue(uint64(i), int64(i)))
+	}
+	assert.Nil(t, a.Finalize(true))
+	assert.Nil(t, b.Finalize(true))
+	assert.Equal(t, uint64(0), budget.Used())
+
+	// Scanning both series keeps the decoded frames within the budget
+	for i := 0; i < 640; i++ {
+		time, v, err := a.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i), time)
+		assert.Equal(t, float64(i)/2, v)
+
+		_, w, err := b.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(i), w)
+		assert.LessOrEqual(t, budget.Used(), budget.Limit())
+	}
+
+	m := budget.Metrics()
+	assert.Equal(t, uint64(2*2*10), m.Misses)
+	assert.Equal(t, m.Misses-4, m.Evictions)
+	assert.Equal(t, uint64(4*640)-m.Misses, m.Hits)
+}
+
+// Head frames stay packed with every codec supporting incremental encoding
+func TestSeries_AppendableCodecs(t *testing.T) {
+
+	cases := []struct {
+		time, value             packer.CodecID
+		packedTime, packedValue bool
+	}{
+		{packer.CodecDeltaOfDelta, packer.CodecChimp, true, true},
+		{packer.CodecDeltaOfDelta, packer.CodecSimple8b, true, false},
+		{packer.CodecGorilla, packer.CodecRLE, true, false},
+		{packer.CodecSimple8b, packer.CodecGorilla, false, true},
+	}
+	for _, c := range cases {
+		s := NewSeries[int64](64, WithTimeCodec(c.time),
+			WithValueCodec(c.value), WithAppendableFrames())
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, s.AppendValue(uint64(1000+i*15), int64(i%7)))
+		}
+
+		fT, _, err := s.TimeFrame(1)
+		assert.Nil(t, err)
+		assert.Equal(t, c.packedTime, fT.IsAppendable(), c.time.String())
+		fV, _, err := s.ValueFrame(1)
+		assert.Nil(t, err)
+		assert.Equal(t, c.packedValue, fV.IsAppendable(), c.value.String())
+
+		// Regular times of a packed head frame cost about a bit each
+		if c.packedTime && c.time == packer.CodecDeltaOfDelta {
+			assert.Less(t, fT.Size(), uint64(36*8))
+		}
+
+		for i := 0; i < 100; i++ {
+			time, v, err := s.Value(i)
+			assert.Nil(t, err)
+			assert.Equal(t, uint64(1000+i*15), time)
+			assert.Equal(t, int64(i%7), v)
+		}
+	}
+}
+
+func TestSeries_32BitValues(t *testing.T) {
+
+	for _, codec := range []packer.CodecID{packer.CodecChimp, packer.CodecGorilla} {
+		s := NewSeries[float32](8, WithValueCodec(codec), WithAppendableFrames())
+		for i := 0; i < 20; i++ {
+			err := s.AppendValue(uint64(1000+i), float32(i)/4.0)
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, s.Finalize(true))
+
+		for i := 0; i < 20; i++ {
+			_, v, err := s.Value(i)
+			assert.Nil(t, err)
+			assert.Equal(t, float32(i)/4.0, v)
+		}
+	}
+
+	s := NewSeries[int32](8, WithValueCodec(packer.CodecSimple8b))
+	for i := 0; i < 20; i++ {
+		assert.Nil(t, s.AppendValue(uint64(i), int32(10-i)))
+	}
+	assert.Nil(t, s.Finalize(true))
+	_, v, err := s.Value(19)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(-9), v)
+}
+
+func TestSeries_RestartPoints(t *testing.T) {
+
+	s := NewSeries[float64](64,
+		WithTimeCodec(packer.CodecGorilla), WithRestartInterval(16))
+
+	for i := 0; i < 150; i++ {
+		err := s.AppendValue(uint64(i*60), float64(i)*0.5)
+		assert.Nil(t, err)
+	}
+
+	// Head frame is not full and stays unpacked
+	err := s.Finalize(true)
+	assert.Nil(t, err)
+
+	for _, i := range []int{0, 17, 63, 64, 100, 127, 149} {
+		time, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i*60), time)
+		assert.Equal(t, float64(i)*0.5, v)
+	}
+
+	err = s.AppendValue(150*60, 75.0)
+	assert.Nil(t, err)
+	_, v, err := s.Value(150)
+	assert.Nil(t, err)
+	assert.Equal(t, 75.0, v)
+}
+
+func TestSeries_MaxError(t *testing.T) {
+
+	s := NewSeries[float64](64, WithMaxError(packer.AbsoluteError, 0.01))
+	assert.Equal(t, packer.CodecQuantized, s.ValueCodec())
+
+	values := make([]float64, 128)
+	for i := range values {
+		values[i] = 20.0 + math.Sin(float64(i)/9.0)*1.234567
+		err := s.AppendValue(uint64(i*60), values[i])
+		assert.Nil(t, err)
+	}
+
+	err := s.Finalize(true)
+	assert.Nil(t, err)
+	assert.True(t, s.valueFrames[0].IsLossy())
+	assert.False(t, s.timeFrames[0].IsLossy())
+
+	hdr, err := s.valueFrames[1].Header()
+	assert.Nil(t, err)
+	assert.Equal(t, 0.01, hdr.ErrorBound)
+
+	for i := range values {
+		_, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.InDelta(t, values[i], v, 0.01)
+	}
+
+	// Lossy values are only supported by float64 series
+	sInt := NewSeries[int64](64, WithMaxError(packer.AbsoluteError, 1))
+	assert.NotNil(t, sInt.AppendValue(0, 1))
+
+	// The codec cannot pack without an error bound
+	sNoBound := NewSeries[float64](64, WithValueCodec(packer.CodecQuantized))
+	assert.NotNil(t, sNoBound.AppendValue(0, 1))
+}
+
+func TestSeries_DecimalScale(t *testing.T) {
+
+	s := NewSeries[packer.Decimal](64, WithDecimalScale(2))
+	assert.Equal(t, packer.CodecDecimal, s.ValueCodec())
+	assert.Equal(t, uint8(2), s.DecimalScale())
+
+	values := make([]packer.Decimal, 128)
+	for i := range values {
+		values[i] = packer.Decimal(1999 + (i%5)*100)
+		err := s.AppendValue(uint64(i*60), values[i])
+		assert.Nil(t, err)
+	}
+
+	err := s.Finalize(true)
+	assert.Nil(t, err)
+
+	hdr, err := s.valueFrames[1].Header()
+	assert.Nil(t, err)
+	assert.Equal(t, packer.CodecDecimal, hdr.Codec)
+	assert.Equal(t, packer.ElemDecimal, hdr.ElemType)
+
+	for i := range values {
+		_, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, values[i], v)
+	}
+
+	// Decimal frames are only supported by decimal series
+	sInt := NewSeries[int64](64, WithDecimalScale(2))
+	assert.NotNil(t, sInt.AppendValue(0, 1))
+}
+
+func TestSeries_FromSlices(t *testing.T) {
+
+	times := gen.Timestamps(1, 1000, 1700000000, 60, 5)
+	values := gen.Sinusoid[float64](2, 1000, 20, 3, 288, 0.25)
+
+	s, err := FromSlices(times, values, 128)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, s.Size())
+	assert.Equal(t, 8, s.NumFrames())
+
+	for _, i := range []int{0, 127, 128, 999} {
+		time, v, err := s.Value(i)
+		assert.Nil(t, err)
+		assert.Equal(t, times[i], time)
+		assert.Equal(t, values[i], v)
+	}
+
+	_, err = FromSlices(times[:10], values, 128)
+	assert.NotNil(t, err)
+}
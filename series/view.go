@@ -0,0 +1,179 @@
+package series
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/rmravindran/ats/series/packer"
+)
+
+// Read-only view of a range of values of a series. The view shares the frames
+// of the series instead of copying its values, which are read from the frames
+// on access, so ops applied on a small range of a large series only unpack the
+// frames holding the range. A view reflects later changes of the values of
+// the series, but its range is fixed when it is created.
+//
+// Views are read directly by the ops of the series/ops package.
+type SeriesView[T packer.Number] struct {
+
+	// Viewed series
+	series *Series[T]
+
+	// Index of the first value of the view in the series
+	from int
+
+	// Index past the last value of the view in the series
+	to int
+}
+
+// Return a view of the values of the series at the indices in [fromIdx,
+// toIdx) along with nil error, otherwise returns (nil, error) if the range is
+// out of bound.
+func (series *Series[T]) Slice(fromIdx, toIdx int) (*SeriesView[T], error) {
+	if fromIdx < 0 || toIdx < fromIdx || toIdx > series.size {
+		return nil, errors.New("slice out of bound")
+	}
+	return &SeriesView[T]{series: series, from: fromIdx, to: toIdx}, nil
+}
+
+// Return a view of the values of the series at the times in [fromTime,
+// toTime) along with nil error, otherwise returns (nil, error). The times of
+// the series must be in ascending order. The frames holding the range are
+// found from the statistics of the time frames, so only the frames at the
+// bounds of the range are unpacked.
+func (series *Series[T]) Range(fromTime, toTime uint64) (*SeriesView[T], error) {
+	if toTime < fromTime {
+		return nil, errors.New("invalid time range")
+	}
+
+	from, err := series.searchTime(fromTime)
+	if err != nil {
+		return nil, err
+	}
+	to, err := series.searchTime(toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return series.Slice(from, to)
+}
+
+// Return a view of the values of the view at the indices in [fromIdx, toIdx)
+// along with nil error, otherwise returns (nil, error) if the range is out of
+// bound.
+func (view *SeriesView[T]) Slice(fromIdx, toIdx int) (*SeriesView[T], error) {
+	if fromIdx < 0 || toIdx < fromIdx || toIdx > view.Length() {
+		return nil, errors.New("slice out of bound")
+	}
+	return &SeriesView[T]{
+		series: view.series, from: view.from + fromIdx, to: view.from + toIdx}, nil
+}
+
+// Return the time and the value at the specified index of the view along with
+// nil error, otherwise returns (0, 0, error).
+func (view *SeriesView[T]) Value(index int) (uint64, T, error) {
+	if index < 0 || index >= view.Length() {
+		return 0, 0, errors.New("index out of bound")
+	}
+	return view.series.Value(view.from + index)
+}
+
+// Return the value at the specified index, or 0 if it cannot be read.
+func (view *SeriesView[T]) ValueAt(idx int) T {
+	if idx < 0 || idx >= view.Length() {
+		return 0
+	}
+	f, local := view.series.locate(view.from + idx)
+	v, _ := view.series.valueFrames[f].Value(local)
+	return v
+}
+
+// Return the time at the specified index, or 0 if it cannot be read.
+func (view *SeriesView[T]) TimeAt(idx int) uint64 {
+	if idx < 0 || idx >= view.Length() {
+		return 0
+	}
+	f, local := view.series.locate(view.from + idx)
+	t, _ := view.series.timeFrames[f].Value(local)
+	return t
+}
+
+// Return true if the value at the specified index is present (see
+// Series.IsValid).
+func (view *SeriesView[T]) IsValidAt(idx int) bool {
+	if idx < 0 || idx >= view.Length() {
+		return false
+	}
+	valid, _ := view.series.IsValid(view.from + idx)
+	return valid
+}
+
+// Return true if the view holds no value.
+func (view *SeriesView[T]) IsEmpty() bool {
+	return view.to == view.from
+}
+
+// Return the number of values of the view.
+func (view *SeriesView[T]) Length() int {
+	return view.to - view.from
+}
+
+//-----------------------------------------------------------------------------
+//                              PRIVATE METHODS
+//-----------------------------------------------------------------------------
+
+// Return the index of the frame holding the value at the specified index of
+// the series, along with the index of the value in the frame.
+func (series *Series[T]) locate(index int) (int, int) {
+	frameIndex := index / series.frameSize
+	return frameIndex, index - (frameIndex * series.frameSize)
+}
+
+// Return the index of the first value of the series at or after the specified
+// time, or the size of the series if there is none, along with nil error.
+// Otherwise returns (0, error).
+func (series *Series[T]) searchTime(time uint64) (int, error) {
+
+	// First frame whose last time is at or after the time
+	var err error
+	frameIndex := sort.Search(len(series.timeFrames), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		f, n, e := series.TimeFrame(i)
+		if e == nil && n == 0 {
+			return true
+		}
+		stats, e := frameStats(f, n)
+		if e != nil {
+			err = e
+			return true
+		}
+		return stats.Last >= time
+	})
+	if err != nil {
+		return 0, err
+	}
+	if frameIndex == len(series.timeFrames) {
+		return series.size, nil
+	}
+
+	// First value of the frame at or after the time
+	f, n, _ := series.TimeFrame(frameIndex)
+	local := sort.Search(n, func(i int) bool {
+		if err != nil {
+			return true
+		}
+		t, e := f.Value(i)
+		if e != nil {
+			err = e
+			return true
+		}
+		return t >= time
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return frameIndex*series.frameSize + local, nil
+}
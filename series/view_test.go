@@ -0,0 +1,71 @@
+package series
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesView_SliceRange(t *testing.T) {
+
+	// Times every 10 units, last frame partially filled
+	s := NewSeries[float64](32)
+	for i := 0; i < 200; i++ {
+		if i == 70 {
+			assert.Nil(t, s.AppendNull(uint64(i*10)))
+			continue
+		}
+		assert.Nil(t, s.AppendValue(uint64(i*10), float64(i)))
+	}
+	assert.Nil(t, s.Finalize(true))
+
+	view, err := s.Slice(60, 80)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, view.Length())
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, uint64((60+i)*10), view.TimeAt(i))
+		assert.Equal(t, i != 10, view.IsValidAt(i), i)
+		if i != 10 {
+			assert.Equal(t, float64(60+i), view.ValueAt(i))
+		}
+	}
+
+	sub, err := view.Slice(2, 4)
+	assert.Nil(t, err)
+	time, v, err := sub.Value(1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(630), time)
+	assert.Equal(t, 63.0, v)
+	_, _, err = sub.Value(2)
+	assert.NotNil(t, err)
+
+	// Time ranges include the start and exclude the end
+	cases := []struct {
+		from, to uint64
+		first    int
+		length   int
+	}{
+		{600, 800, 60, 20},
+		{595, 801, 60, 21},
+		{0, 10, 0, 1},
+		{1990, 5000, 199, 1},
+		{2000, 5000, 200, 0},
+		{315, 325, 32, 1},
+		{0, 0, 0, 0},
+	}
+	for _, c := range cases {
+		r, err := s.Range(c.from, c.to)
+		assert.Nil(t, err)
+		assert.Equal(t, c.length, r.Length(), c)
+		if c.length > 0 {
+			assert.Equal(t, uint64(c.first*10), r.TimeAt(0), c)
+		}
+	}
+
+	_, err = s.Range(10, 5)
+	assert.NotNil(t, err)
+	_, err = s.Slice(0, 201)
+	assert.NotNil(t, err)
+	_, err = view.Slice(-1, 3)
+	assert.NotNil(t, err)
+}